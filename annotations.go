@@ -0,0 +1,231 @@
+package quranapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+const bucketAnnotations = "annotations"
+
+// AnnotationPackVersion is the version of the annotation pack format
+// described by annotations.schema.json.
+const AnnotationPackVersion = 1
+
+// ErrInvalidAnnotationPack is returned for packs that aren't valid JSON or
+// have an unsupported version.
+var ErrInvalidAnnotationPack = errors.New("invalid annotation pack")
+
+//go:embed annotations.schema.json
+var AnnotationPackSchema []byte
+
+// AnnotationPack is the portable format for sharing annotated verses.
+type AnnotationPack struct {
+	Version     int          `json:"version"`
+	Title       string       `json:"title,omitempty"`
+	Author      string       `json:"author,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Annotations []Annotation `json:"annotations"`
+}
+
+// Annotation is a note and highlighted words on a single verse.
+type Annotation struct {
+	VerseKey   VerseKey    `json:"verse_key"`
+	Note       string      `json:"note,omitempty"`
+	Highlights []Highlight `json:"highlights,omitempty"`
+}
+
+// Highlight marks the words from FromWord to ToWord, counted from 1.
+type Highlight struct {
+	FromWord int    `json:"from_word"`
+	ToWord   int    `json:"to_word"`
+	Color    string `json:"color,omitempty"`
+}
+
+func (a Annotation) validate() error {
+	if err := a.VerseKey.Validate(); err != nil {
+		return err
+	}
+	for _, h := range a.Highlights {
+		if h.FromWord < 1 || h.ToWord < h.FromWord {
+			return fmt.Errorf("%s: invalid highlight of words %d-%d", a.VerseKey, h.FromWord, h.ToWord)
+		}
+	}
+	return nil
+}
+
+// merge adds the note and highlights of other that a lacks.
+func (a Annotation) merge(other Annotation) (Annotation, bool) {
+	changed := false
+	switch {
+	case other.Note == "" || other.Note == a.Note:
+	case a.Note == "":
+		a.Note, changed = other.Note, true
+	default:
+		a.Note, changed = a.Note+"\n\n"+other.Note, true
+	}
+
+	seen := make(map[Highlight]bool, len(a.Highlights))
+	for _, h := range a.Highlights {
+		seen[h] = true
+	}
+	for _, h := range other.Highlights {
+		if !seen[h] {
+			a.Highlights = append(a.Highlights, h)
+			seen[h] = true
+			changed = true
+		}
+	}
+	return a, changed
+}
+
+// Annotate stores a on its verse, replacing any earlier annotation.
+func (q *QuranService) Annotate(ctx context.Context, a Annotation) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+	return q.putValue(ctx, bucketAnnotations, a.VerseKey.String(), a)
+}
+
+// Annotations returns every stored annotation in mushaf order.
+func (q *QuranService) Annotations(ctx context.Context) ([]Annotation, error) {
+	var out []Annotation
+	err := q.iterate(ctx, bucketAnnotations, func(key string, value []byte) error {
+		var a Annotation
+		if err := valueDecode(value, &a); err != nil {
+			return err
+		}
+		out = append(out, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].VerseKey.Compare(out[j].VerseKey) < 0
+	})
+	return out, nil
+}
+
+// ExportAnnotations writes every stored annotation to w as a pack.
+func (q *QuranService) ExportAnnotations(ctx context.Context, w io.Writer, title, author string) error {
+	annotations, err := q.Annotations(ctx)
+	if err != nil {
+		return err
+	}
+	pack := AnnotationPack{
+		Version:     AnnotationPackVersion,
+		Title:       title,
+		Author:      author,
+		CreatedAt:   time.Now().UTC(),
+		Annotations: annotations,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(pack)
+}
+
+// ImportAnnotations merges the pack read from r into the stored
+// annotations. Notes on an already annotated verse are appended, and
+// highlights are added unless already present.
+func (q *QuranService) ImportAnnotations(ctx context.Context, r io.Reader) (ImportReport, error) {
+	var pack AnnotationPack
+	if err := json.NewDecoder(r).Decode(&pack); err != nil {
+		return ImportReport{}, fmt.Errorf("%w: %v", ErrInvalidAnnotationPack, err)
+	}
+	if pack.Version != AnnotationPackVersion {
+		return ImportReport{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidAnnotationPack, pack.Version)
+	}
+
+	var report ImportReport
+	for _, a := range pack.Annotations {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if err := a.validate(); err != nil {
+			report.Skipped = append(report.Skipped, err.Error())
+			continue
+		}
+
+		var existing Annotation
+		err := q.getValue(ctx, bucketAnnotations, a.VerseKey.String(), &existing)
+		switch {
+		case errors.Is(err, ErrKeyNotFound):
+		case err != nil:
+			return report, err
+		default:
+			var changed bool
+			if a, changed = existing.merge(a); !changed {
+				report.Duplicates++
+				continue
+			}
+		}
+
+		if err := q.putValue(ctx, bucketAnnotations, a.VerseKey.String(), a); err != nil {
+			return report, err
+		}
+		report.Imported++
+	}
+	return report, nil
+}
+
+const annotationsUsage = "usage: annotations export [-title t] [-author a] [-o file] | import <file> | schema"
+
+func runAnnotations(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) == 0 {
+		return errors.New(annotationsUsage)
+	}
+
+	switch args[0] {
+	case "export":
+		fs := flag.NewFlagSet("annotations export", flag.ContinueOnError)
+		title := fs.String("title", "", "pack title")
+		author := fs.String("author", "", "pack author")
+		out := fs.String("o", "", "output file (defaults to stdout)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		return q.ExportAnnotations(ctx, w, *title, *author)
+	case "import":
+		if len(args) != 2 {
+			return errors.New(annotationsUsage)
+		}
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		report, err := q.ImportAnnotations(ctx, f)
+		if err != nil {
+			return err
+		}
+		for _, s := range report.Skipped {
+			fmt.Printf("skipped: %s\n", s)
+		}
+		fmt.Printf("imported %d annotations, %d already present, %d skipped\n",
+			report.Imported, report.Duplicates, len(report.Skipped))
+		return nil
+	case "schema":
+		_, err := os.Stdout.Write(AnnotationPackSchema)
+		return err
+	default:
+		return fmt.Errorf("unknown annotations command: %q", args[0])
+	}
+}
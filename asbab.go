@@ -0,0 +1,224 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const bucketAsbab = "asbab_nuzul"
+
+// maxAsbabDataset caps the size of a downloaded occasions dataset.
+const maxAsbabDataset = 64 << 20
+
+// ErrNoAsbabDataset is returned by GetAsbabNuzul when no occasions dataset
+// was imported and none can be downloaded.
+var ErrNoAsbabDataset = errors.New("no asbab al-nuzul dataset imported")
+
+// Occasion is an account of the occasion of revelation (sabab al-nuzul)
+// of a verse or a run of verses.
+type Occasion struct {
+	// Verses is the verse or range of verses the account is about, such as
+	// "2:142" or "2:142-144".
+	Verses string `json:"verses"`
+	Text   string `json:"text"`
+	// Source is the work the account is taken from, such as al-Wahidi's
+	// Asbab al-Nuzul, and Narrator whom it is narrated from.
+	Source   string `json:"source,omitempty"`
+	Narrator string `json:"narrator,omitempty"`
+}
+
+// WithAsbabNuzulURL downloads the occasions dataset from url, in the form
+// ReadAsbabNuzul reads, the first time GetAsbabNuzul is called with none
+// imported.
+func WithAsbabNuzulURL(url string) Option {
+	return func(q *QuranService) {
+		q.asbabURL = url
+	}
+}
+
+// ReadAsbabNuzul reads an occasions dataset: a JSON array of Occasion.
+func ReadAsbabNuzul(r io.Reader) ([]Occasion, error) {
+	var occasions []Occasion
+	if err := json.NewDecoder(r).Decode(&occasions); err != nil {
+		return nil, err
+	}
+	for i, o := range occasions {
+		if _, err := occasionScope(o); err != nil {
+			return nil, fmt.Errorf("occasion %d: %w", i, err)
+		}
+		if strings.TrimSpace(o.Text) == "" {
+			return nil, fmt.Errorf("occasion %d of %s: no text", i, o.Verses)
+		}
+	}
+	return occasions, nil
+}
+
+// occasionScope returns the verses an occasion is about.
+func occasionScope(o Occasion) (Scope, error) {
+	s, err := ParseScope(o.Verses)
+	if err == nil && (s.FromVerse == 0 || s.ToVerse == 0) {
+		err = fmt.Errorf("invalid verses %q: want a verse or range such as 2:142-144", o.Verses)
+	}
+	if err == nil {
+		err = s.Validate()
+	}
+	return s, err
+}
+
+// ImportAsbabNuzul replaces the occasions dataset with the one read from r.
+// Each occasion is stored under every verse it is about.
+func (q *QuranService) ImportAsbabNuzul(ctx context.Context, r io.Reader) error {
+	occasions, err := ReadAsbabNuzul(r)
+	if err != nil {
+		return err
+	}
+
+	var stale []string
+	err = q.store.Iterate(ctx, bucketAsbab, func(key string, _ []byte) error {
+		stale = append(stale, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range stale {
+		if err := q.deleteValue(ctx, bucketAsbab, key); err != nil {
+			return err
+		}
+	}
+
+	byVerse := make(map[string][]Occasion)
+	for _, o := range occasions {
+		s, _ := occasionScope(o)
+		for v := s.FromVerse; v <= s.ToVerse; v++ {
+			key := verseKey(s.Number, v)
+			byVerse[key] = append(byVerse[key], o)
+		}
+	}
+	for key, occasions := range byVerse {
+		if err := q.putValue(ctx, bucketAsbab, key, occasions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAsbabNuzul returns the accounts of the occasion of revelation of the
+// verse named by key, or none for verses without one in the dataset.
+func (q *QuranService) GetAsbabNuzul(ctx context.Context, key string) ([]Occasion, error) {
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	key = verseKey(chapter, verse)
+	if err := q.loadAsbabOnce(ctx); err != nil {
+		return nil, err
+	}
+
+	occasions := []Occasion{}
+	err = q.getValue(ctx, bucketAsbab, key, &occasions)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+	return occasions, nil
+}
+
+// loadAsbabOnce downloads the dataset set by WithAsbabNuzulURL when none is
+// stored.
+func (q *QuranService) loadAsbabOnce(ctx context.Context) error {
+	empty := true
+	err := q.store.Iterate(ctx, bucketAsbab, func(string, []byte) error {
+		empty = false
+		return errStopIteration
+	})
+	if err != nil && !errors.Is(err, errStopIteration) {
+		return err
+	}
+	if !empty {
+		return nil
+	}
+	if q.asbabURL == "" {
+		return ErrNoAsbabDataset
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, q.asbabURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := q.doer.Do(req)
+	if err != nil {
+		return fmt.Errorf("download asbab al-nuzul: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download asbab al-nuzul: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAsbabDataset))
+	if err != nil {
+		return fmt.Errorf("download asbab al-nuzul: %w", err)
+	}
+	return q.ImportAsbabNuzul(ctx, bytes.NewReader(data))
+}
+
+// AsbabNuzulProvider returns a CompanionProvider attaching the occasions of
+// revelation of q's dataset to verses as companions of kind
+// "asbab_al_nuzul".
+func (q *QuranService) AsbabNuzulProvider() CompanionProvider {
+	return asbabProvider{q}
+}
+
+type asbabProvider struct {
+	q *QuranService
+}
+
+func (asbabProvider) Name() string { return "asbab_al_nuzul" }
+
+func (p asbabProvider) Companions(ctx context.Context, v Verse) ([]Companion, error) {
+	occasions, err := p.q.GetAsbabNuzul(ctx, v.VerseKey.String())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Companion, len(occasions))
+	for i, o := range occasions {
+		out[i] = Companion{Kind: "asbab_al_nuzul", Title: "Occasion of revelation of " + o.Verses, Text: o.Text, Source: o.Source}
+	}
+	return out, nil
+}
+
+const asbabUsage = "usage: asbab <verse key> | import <file>"
+
+func runAsbab(ctx context.Context, q *QuranService, args []string) error {
+	switch {
+	case len(args) == 2 && args[0] == "import":
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return q.ImportAsbabNuzul(ctx, f)
+	case len(args) == 1:
+		occasions, err := q.GetAsbabNuzul(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		for i, o := range occasions {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s\n%s\n", o.Verses, o.Text)
+			if o.Source != "" {
+				fmt.Printf("— %s\n", o.Source)
+			}
+		}
+		return nil
+	default:
+		return errors.New(asbabUsage)
+	}
+}
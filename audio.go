@@ -0,0 +1,400 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const bucketAudio = "audio"
+
+// DefaultAudioBaseURL hosts the verse audio upstream names by relative
+// paths such as "Alafasy/mp3/001001.mp3".
+const DefaultAudioBaseURL = "https://verses.quran.com/"
+
+// VerseAudio is the audio of a verse in one recitation.
+type VerseAudio struct {
+	VerseKey VerseKey `json:"verse_key"`
+	URL      string   `json:"url"`
+	// Duration is in seconds, or zero when upstream does not give it.
+	Duration int    `json:"duration"`
+	Format   string `json:"format"`
+}
+
+// WithAudioDir sets the directory DownloadAudio saves verse audio in, as
+// {recitation}/{chapter}{verse}.mp3 with three digit numbers, the layout
+// of EveryAyah. Playlists use the files found there instead of their URLs.
+func WithAudioDir(dir string) Option {
+	return func(q *QuranService) {
+		q.audioDir = dir
+	}
+}
+
+// AudioPath returns where DownloadAudio saves the audio of the verse named
+// key in recitation, or "" without WithAudioDir.
+func (q *QuranService) AudioPath(key VerseKey, recitation int) string {
+	chapter, verse, err := key.Split()
+	if q.audioDir == "" || err != nil {
+		return ""
+	}
+	return filepath.Join(q.audioDir, strconv.Itoa(recitation), fmt.Sprintf("%03d%03d.mp3", chapter, verse))
+}
+
+// ChapterAudio returns the audio of the verses of chapter in recitation.
+// The audio of the recitation set by WithRecitation comes with the stored
+// chapter; that of others is fetched the first time and stored apart.
+func (q *QuranService) ChapterAudio(ctx context.Context, chapter, recitation int) ([]VerseAudio, error) {
+	if err := q.requireFeature(FeatureAudio); err != nil {
+		return nil, err
+	}
+	if err := ValidateChapter(chapter); err != nil {
+		return nil, err
+	}
+	if recitation <= 0 {
+		return nil, fmt.Errorf("invalid recitation %d", recitation)
+	}
+
+	if recitation == q.recitation {
+		c, err := q.GetChapter(ctx, chapter)
+		if err != nil {
+			return nil, err
+		}
+		return verseAudio(c.Verses), nil
+	}
+
+	key := strconv.Itoa(recitation) + "/" + strconv.Itoa(chapter)
+	var audio []VerseAudio
+	err := q.getValue(ctx, bucketAudio, key, &audio)
+	q.cacheEvent(ctx, CacheLayerStore, "audio/"+key, err == nil)
+	if err == nil {
+		return audio, nil
+	}
+	if !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/chapters/%d/verses", chapter)
+	verses, err := paginate(ctx, q.paging, versesPerPage, chapterVerseCounts[chapter-1],
+		func(v Verse) int { return v.VerseNumber },
+		func(ctx context.Context, params []queryParam) ([]Verse, error) {
+			var resp struct {
+				Verses []Verse `json:"verses"`
+			}
+			req := q.httpClient.Get(path).QueryParam("recitation", strconv.Itoa(recitation))
+			for _, p := range params {
+				req = req.QueryParam(p.name, p.value)
+			}
+			ctx, span := q.startSpan(ctx, "GET "+path)
+			err := q.fetch(ctx, path, req, &resp)
+			endSpan(span, err)
+			return resp.Verses, err
+		})
+	if err != nil {
+		return nil, err
+	}
+	audio = verseAudio(verses)
+	if err := q.putValue(ctx, bucketAudio, key, audio); err != nil {
+		q.log(ctx).Error("cache audio", "recitation", recitation, "chapter", chapter, "err", err)
+	}
+	return audio, nil
+}
+
+func verseAudio(verses []Verse) []VerseAudio {
+	out := make([]VerseAudio, len(verses))
+	for i, v := range verses {
+		out[i] = VerseAudio{
+			VerseKey: v.VerseKey,
+			URL:      audioURL(v.Audio.URL),
+			Duration: v.Audio.Duration,
+			Format:   v.Audio.Format,
+		}
+	}
+	return out
+}
+
+// audioURL resolves an audio URL as upstream gives it, which may be
+// protocol relative or relative to DefaultAudioBaseURL.
+func audioURL(u string) string {
+	switch {
+	case u == "" || strings.Contains(u, "://"):
+		return u
+	case strings.HasPrefix(u, "//"):
+		return "https:" + u
+	}
+	return DefaultAudioBaseURL + strings.TrimPrefix(u, "/")
+}
+
+// scopeAudio returns the audio of the verses in scope, in mushaf order.
+func (q *QuranService) scopeAudio(ctx context.Context, scope Scope, recitation int) ([]VerseAudio, error) {
+	if err := q.requireFeature(FeatureAudio); err != nil {
+		return nil, err
+	}
+	verses, err := q.ScopeVerses(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	var out []VerseAudio
+	for i := 0; i < len(verses); {
+		chapter := verses[i].ChapterID
+		audio, err := q.ChapterAudio(ctx, chapter, recitation)
+		if err != nil {
+			return nil, err
+		}
+		for ; i < len(verses) && verses[i].ChapterID == chapter; i++ {
+			n := verses[i].VerseNumber
+			if n > len(audio) || audio[n-1].URL == "" {
+				return nil, fmt.Errorf("recitation %d has no audio for %s", recitation, verses[i].VerseKey)
+			}
+			out = append(out, audio[n-1])
+		}
+	}
+	return out, nil
+}
+
+// DownloadAudio saves the audio of the verses in scope into the directory
+// set by WithAudioDir, at AudioPath, and returns how many files it
+// downloaded. Files already there are skipped, so an interrupted download
+// can be resumed.
+func (q *QuranService) DownloadAudio(ctx context.Context, scope Scope, recitation int) (int, error) {
+	if q.audioDir == "" {
+		return 0, errors.New("download audio: no audio directory set")
+	}
+	audio, err := q.scopeAudio(ctx, scope, recitation)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Join(q.audioDir, strconv.Itoa(recitation)), 0o755); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, a := range audio {
+		path := q.AudioPath(a.VerseKey, recitation)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := downloadFile(ctx, q.doer, a.URL, path); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// PlaylistTrack is an entry of a Playlist.
+type PlaylistTrack struct {
+	Title string `json:"title"`
+	// Duration is in seconds, or -1 when unknown, as in M3U.
+	Duration int `json:"duration"`
+	// Location is the absolute path of a downloaded file, or its URL.
+	Location string `json:"location"`
+}
+
+// Playlist is the recitation of a scope as a list of audio tracks.
+type Playlist struct {
+	Title  string          `json:"title"`
+	Tracks []PlaylistTrack `json:"tracks"`
+}
+
+// GeneratePlaylist returns a track for each verse in scope recited in
+// recitation, playing the file saved by DownloadAudio when there is one and
+// streaming it from upstream otherwise. A chapter wholly in scope whose
+// verses were joined by StitchChapterAudio is a single track.
+func (q *QuranService) GeneratePlaylist(ctx context.Context, scope Scope, recitation int) (Playlist, error) {
+	audio, err := q.scopeAudio(ctx, scope, recitation)
+	if err != nil {
+		return Playlist{}, err
+	}
+	names, err := q.chapterNames(ctx)
+	if err != nil {
+		return Playlist{}, err
+	}
+
+	p := Playlist{Title: scope.String()}
+	for i := 0; i < len(audio); {
+		a := audio[i]
+		chapter, verse, _ := a.VerseKey.Split()
+		if count := chapterVerseCounts[chapter-1]; verse == 1 && i+count <= len(audio) {
+			if path := localFile(q.ChapterAudioPath(chapter, recitation)); path != "" {
+				t := PlaylistTrack{Title: names[chapter], Location: path}
+				for _, a := range audio[i : i+count] {
+					if a.Duration <= 0 || t.Duration < 0 {
+						t.Duration = -1
+						continue
+					}
+					t.Duration += a.Duration
+				}
+				p.Tracks = append(p.Tracks, t)
+				i += count
+				continue
+			}
+		}
+
+		t := PlaylistTrack{
+			Title:    fmt.Sprintf("%s %d:%d", names[chapter], chapter, verse),
+			Duration: a.Duration,
+			Location: a.URL,
+		}
+		if t.Duration <= 0 {
+			t.Duration = -1
+		}
+		if path := localFile(q.AudioPath(a.VerseKey, recitation)); path != "" {
+			t.Location = path
+		}
+		p.Tracks = append(p.Tracks, t)
+		i++
+	}
+	return p, nil
+}
+
+// localFile returns the absolute path of the file at path, or "" if there
+// is none.
+func localFile(path string) string {
+	if path == "" {
+		return ""
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	return abs
+}
+
+// WriteM3U writes p as an extended M3U playlist. It is UTF-8, so it is
+// read correctly when saved with the .m3u8 extension.
+func (p Playlist) WriteM3U(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	if p.Title != "" {
+		fmt.Fprintf(&b, "#PLAYLIST:%s\n", p.Title)
+	}
+	for _, t := range p.Tracks {
+		fmt.Fprintf(&b, "#EXTINF:%d,%s\n%s\n", t.Duration, strings.ReplaceAll(t.Title, "\n", " "), t.Location)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+const playlistUsage = "usage: playlist [-reciter id] [-o file.m3u8] <scope>"
+
+func runPlaylist(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("playlist", flag.ContinueOnError)
+	reciter := fs.Int("reciter", q.recitation, "recitation id")
+	out := fs.String("o", "", "file to write, or stdout")
+	rest, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 || *reciter == 0 {
+		return errors.New(playlistUsage)
+	}
+	scope, err := q.ResolveScope(strings.Join(rest, " "))
+	if err != nil {
+		return err
+	}
+	p, err := q.GeneratePlaylist(ctx, scope, *reciter)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		return p.WriteM3U(os.Stdout)
+	}
+	if ext := path.Ext(*out); ext != ".m3u" && ext != ".m3u8" {
+		return fmt.Errorf("playlist %s: want a .m3u or .m3u8 file", *out)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteM3U(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+const audioUsage = `usage: audio download [-reciter id] <scope>
+       audio stitch [-reciter id] [-hls] <chapter>...
+       audio speak -translation id <verse key>...`
+
+func runAudio(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) == 0 || args[0] != "download" && args[0] != "stitch" && args[0] != "speak" {
+		return errors.New(audioUsage)
+	}
+	fs := flag.NewFlagSet("audio "+args[0], flag.ContinueOnError)
+	reciter := fs.Int("reciter", q.recitation, "recitation id")
+	hls := fs.Bool("hls", false, "write an HLS stream instead of one file")
+	translation := fs.Int("translation", 0, "translation resource id to speak")
+	rest, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 || *reciter == 0 && args[0] != "speak" || *translation == 0 && args[0] == "speak" {
+		return errors.New(audioUsage)
+	}
+
+	if args[0] == "speak" {
+		for _, key := range rest {
+			path, err := q.TranslationAudio(ctx, key, *translation)
+			if err != nil {
+				return err
+			}
+			fmt.Println(path)
+		}
+		return nil
+	}
+
+	if args[0] == "stitch" {
+		chapters, err := parseInts(strings.Join(rest, ","))
+		if err != nil {
+			return err
+		}
+		for _, chapter := range chapters {
+			stitch := q.StitchChapterAudio
+			if *hls {
+				stitch = q.WriteChapterHLS
+			}
+			path, err := stitch(ctx, chapter, *reciter)
+			if err != nil {
+				return err
+			}
+			fmt.Println(path)
+		}
+		return nil
+	}
+
+	scope, err := q.ResolveScope(strings.Join(rest, " "))
+	if err != nil {
+		return err
+	}
+	n, err := q.DownloadAudio(ctx, scope, *reciter)
+	fmt.Printf("downloaded %d files into %s\n", n, filepath.Join(q.audioDir, strconv.Itoa(*reciter)))
+	return err
+}
+
+// parseInterspersed parses the flags of args wherever they appear, so that
+// "juz 30 -reciter 7" reads as "-reciter 7 juz 30", and returns the other
+// arguments.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return rest, nil
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+}
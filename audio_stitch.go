@@ -0,0 +1,239 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChapterAudioPath returns where StitchChapterAudio saves the audio of
+// chapter in recitation, beside its verses as {chapter}.mp3, or "" without
+// WithAudioDir.
+func (q *QuranService) ChapterAudioPath(chapter, recitation int) string {
+	if q.audioDir == "" {
+		return ""
+	}
+	return filepath.Join(q.audioDir, strconv.Itoa(recitation), fmt.Sprintf("%03d.mp3", chapter))
+}
+
+// ChapterHLSPath returns where WriteChapterHLS saves the HLS playlist of
+// chapter in recitation, or "" without WithAudioDir.
+func (q *QuranService) ChapterHLSPath(chapter, recitation int) string {
+	if q.audioDir == "" {
+		return ""
+	}
+	return filepath.Join(q.audioDir, strconv.Itoa(recitation), fmt.Sprintf("%03d", chapter), "index.m3u8")
+}
+
+// verseFrames is the MP3 audio of a downloaded verse without its tags.
+type verseFrames struct {
+	key      VerseKey
+	frames   []byte
+	duration time.Duration
+}
+
+// chapterFrames reads the verses of chapter saved by DownloadAudio.
+func (q *QuranService) chapterFrames(ctx context.Context, chapter, recitation int) ([]verseFrames, error) {
+	if err := q.requireFeature(FeatureAudio); err != nil {
+		return nil, err
+	}
+	if err := ValidateChapter(chapter); err != nil {
+		return nil, err
+	}
+	if q.audioDir == "" {
+		return nil, errors.New("stitch audio: no audio directory set")
+	}
+	out := make([]verseFrames, chapterVerseCounts[chapter-1])
+	for i := range out {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		key := NewVerseKey(chapter, i+1)
+		path := q.AudioPath(key, recitation)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("stitch audio: %s of recitation %d is not downloaded", key, recitation)
+		}
+		if err != nil {
+			return nil, err
+		}
+		frames, duration, err := mp3Frames(data)
+		if err != nil {
+			return nil, fmt.Errorf("stitch audio: %s: %w", path, err)
+		}
+		out[i] = verseFrames{key: key, frames: frames, duration: duration}
+	}
+	return out, nil
+}
+
+// StitchChapterAudio joins the verses of chapter, downloaded by
+// DownloadAudio, into one MP3 file at ChapterAudioPath, so that the chapter
+// plays without a gap between verses. The tags and VBR headers of the
+// verse files are dropped, as they would be read as the header of the
+// whole file.
+func (q *QuranService) StitchChapterAudio(ctx context.Context, chapter, recitation int) (string, error) {
+	verses, err := q.chapterFrames(ctx, chapter, recitation)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	for _, v := range verses {
+		b.Write(v.frames)
+	}
+	path := q.ChapterAudioPath(chapter, recitation)
+	return path, writeFileAtomic(path, b.Bytes())
+}
+
+// WriteChapterHLS writes the verses of chapter, downloaded by
+// DownloadAudio, as an HLS stream: a segment for each verse beside a VOD
+// playlist at ChapterHLSPath. Segment durations are counted from the MP3
+// frames rather than taken from upstream, which rounds them to seconds,
+// and each segment carries the ID3 timestamp HLS requires of packed audio
+// so that players line the segments up without gaps.
+func (q *QuranService) WriteChapterHLS(ctx context.Context, chapter, recitation int) (string, error) {
+	verses, err := q.chapterFrames(ctx, chapter, recitation)
+	if err != nil {
+		return "", err
+	}
+	path := q.ChapterHLSPath(chapter, recitation)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	var target time.Duration
+	for _, v := range verses {
+		target = max(target, v.duration)
+	}
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-PLAYLIST-TYPE:VOD\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n#EXT-X-MEDIA-SEQUENCE:0\n", int(math.Ceil(target.Seconds())))
+	var start time.Duration
+	for _, v := range verses {
+		_, verse, _ := v.key.Split()
+		name := fmt.Sprintf("%03d.mp3", verse)
+		segment := append(hlsTimestampTag(start), v.frames...)
+		if err := writeFileAtomic(filepath.Join(dir, name), segment); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "#EXTINF:%.3f,%s\n%s\n", v.duration.Seconds(), v.key, name)
+		start += v.duration
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return path, writeFileAtomic(path, []byte(b.String()))
+}
+
+// hlsTimestampTag returns an ID3v2.4 tag holding the MPEG-2 timestamp, in
+// 90kHz ticks, at which a packed audio segment starts.
+func hlsTimestampTag(start time.Duration) []byte {
+	const owner = "com.apple.streaming.transportStreamTimestamp\x00"
+	ticks := uint64(start.Seconds()*90000) & (1<<33 - 1)
+	frame := binary.BigEndian.AppendUint64([]byte(owner), ticks)
+
+	tag := []byte{'I', 'D', '3', 4, 0, 0}
+	tag = append(tag, syncsafe(10+len(frame))...)
+	tag = append(tag, 'P', 'R', 'I', 'V')
+	tag = append(tag, syncsafe(len(frame))...)
+	tag = append(tag, 0, 0)
+	return append(tag, frame...)
+}
+
+// syncsafe encodes n in the 4 bytes of 7 bits ID3 uses for sizes.
+func syncsafe(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+}
+
+var (
+	// mp3Bitrates are the Layer III bitrates in kbit/s by bitrate index,
+	// for MPEG-1 and for MPEG-2 and 2.5.
+	mp3Bitrates = [2][15]int{
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	}
+	// mp3SampleRates are in Hz by version bits and sample rate index.
+	mp3SampleRates = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG-1
+		2: {22050, 24000, 16000}, // MPEG-2
+		0: {11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+// mp3Frames returns the MPEG Layer III frames of an MP3 file, without its
+// ID3 tags or the Xing, Info or VBRI frame of a VBR file, and how long
+// they play.
+func mp3Frames(data []byte) ([]byte, time.Duration, error) {
+	if len(data) >= 10 && string(data[:3]) == "ID3" {
+		size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+		size += 10
+		if data[5]&0x10 != 0 {
+			size += 10 // footer
+		}
+		data = data[min(size, len(data)):]
+	}
+	if len(data) >= 128 && string(data[len(data)-128:len(data)-125]) == "TAG" {
+		data = data[:len(data)-128]
+	}
+
+	var out []byte
+	var samples float64
+	first := true
+	for i := 0; i+4 <= len(data); {
+		h := data[i : i+4]
+		version, layer := h[1]>>3&3, h[1]>>1&3
+		bitrate, rate, padding := int(h[2]>>4), int(h[2]>>2&3), int(h[2]>>1&1)
+		if h[0] != 0xff || h[1]&0xe0 != 0xe0 || version == 1 || layer != 1 || bitrate == 0 || bitrate == 15 || rate == 3 {
+			i++ // not a frame header: skip junk until the next one
+			continue
+		}
+		sampleRate := mp3SampleRates[version][rate]
+		perFrame, coef, table := 1152, 144, 0
+		if version != 3 {
+			perFrame, coef, table = 576, 72, 1
+		}
+		size := coef*mp3Bitrates[table][bitrate]*1000/sampleRate + padding
+		if i+size > len(data) {
+			break // truncated final frame
+		}
+		frame := data[i : i+size]
+		i += size
+		if first {
+			first = false
+			head := frame[:min(len(frame), 64)]
+			if bytes.Contains(head, []byte("Xing")) || bytes.Contains(head, []byte("Info")) || bytes.Contains(head, []byte("VBRI")) {
+				continue
+			}
+		}
+		out = append(out, frame...)
+		samples += float64(perFrame) / float64(sampleRate)
+	}
+	if len(out) == 0 {
+		return nil, 0, errors.New("no MPEG audio frames")
+	}
+	return out, time.Duration(samples * float64(time.Second)), nil
+}
+
+// writeFileAtomic writes data to path through a temporary file, so that
+// readers never see it partly written.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
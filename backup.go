@@ -0,0 +1,190 @@
+package quranapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrBackupUnsupported is returned by Backup and Restore for stores other
+// than bolt and bbolt.
+var ErrBackupUnsupported = errors.New("store does not support backups")
+
+// ErrInvalidBackup is returned by Restore for input that isn't a database
+// written by Backup.
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// backuper is implemented by stores that can copy their whole database
+// while in use.
+type backuper interface {
+	WriteBackup(ctx context.Context, w io.Writer) (int64, error)
+	RestoreBackup(ctx context.Context, path string) error
+}
+
+// Backup writes a copy of the whole database, text cache and user data
+// alike, to w and returns its size. The copy is taken from a single read
+// transaction, so it is consistent while the service keeps serving and
+// writing. It is a bolt database file that can be opened as quran.db.
+func (q *QuranService) Backup(ctx context.Context, w io.Writer) (int64, error) {
+	b, ok := q.store.(backuper)
+	if !ok {
+		return 0, ErrBackupUnsupported
+	}
+	_, span := q.startSpan(ctx, "store.Backup")
+	n, err := b.WriteBackup(ctx, w)
+	endSpan(span, err)
+	return n, err
+}
+
+// Restore replaces the contents of the database with a backup written by
+// Backup. The backup is spooled to a temporary file and checked before
+// anything is replaced, and the replacement happens in one transaction.
+func (q *QuranService) Restore(ctx context.Context, r io.Reader) error {
+	if q.readOnly {
+		return ErrReadOnly
+	}
+	b, ok := q.store.(backuper)
+	if !ok {
+		return ErrBackupUnsupported
+	}
+
+	f, err := os.CreateTemp("", "quranapi-restore-*.db")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	_, span := q.startSpan(ctx, "store.Restore")
+	err = b.RestoreBackup(ctx, f.Name())
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+	q.dropCaches()
+	return nil
+}
+
+// ctxWriter stops a long copy once ctx is done.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c ctxWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
+}
+
+// withAdmin serves the admin endpoints under /admin/ to requests carrying
+// key as "Authorization: Bearer <key>" or X-Admin-Key, and passes every
+// other request to next. The endpoints don't exist when key is empty.
+func withAdmin(q *QuranService, key string, next http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/backup", q.handleBackup)
+	mux.HandleFunc("POST /admin/restore", q.handleRestore)
+	mux.HandleFunc("GET /admin/store-stats", q.handleStoreStats)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key == "" || !strings.HasPrefix(r.URL.Path, "/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		given := r.Header.Get("X-Admin-Key")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			given = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(key)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="quranapi admin"`)
+			http.Error(w, errUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (q *QuranService) handleBackup(w http.ResponseWriter, r *http.Request) {
+	name := "quran-" + time.Now().UTC().Format("20060102T150405Z") + ".db"
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	if _, err := q.Backup(r.Context(), w); err != nil {
+		// the status has been sent with the first bytes; a truncated body
+		// is all the client will see
+		q.log(r.Context()).Error("backup failed", "err", err)
+	}
+}
+
+func (q *QuranService) handleRestore(w http.ResponseWriter, r *http.Request) {
+	if err := q.Restore(r.Context(), r.Body); err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func runBackup(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	out := fs.String("o", "", "file to write the backup to (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := q.Backup(ctx, os.Stdout)
+		return err
+	}
+	// write next to the destination and rename, so that an interrupted
+	// backup never leaves a truncated file behind under its name
+	tmp := *out + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	n, err := q.Backup(ctx, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, *out); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d bytes to %s\n", n, *out)
+	return nil
+}
+
+func runRestore(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: restore <file|->")
+	}
+	if args[0] == "-" {
+		return q.Restore(ctx, os.Stdin)
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := q.Restore(ctx, f); err != nil {
+		return err
+	}
+	fmt.Printf("restored %s\n", args[0])
+	return nil
+}
@@ -0,0 +1,275 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	bucketBookmarks = "bookmarks"
+	bucketReading   = "reading"
+
+	keyLastRead = "last_read"
+)
+
+// Bookmark marks a verse, keyed by its verse key, with an optional note and
+// the collection it was filed under. Category and Color label the mark the
+// way pens of different colors do in a printed mushaf.
+type Bookmark struct {
+	VerseKey   VerseKey         `json:"verse_key"`
+	Note       string           `json:"note,omitempty"`
+	Collection string           `json:"collection,omitempty"`
+	Category   BookmarkCategory `json:"category,omitempty"`
+	Color      string           `json:"color,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+type BookmarkCategory string
+
+const (
+	CategoryMemorized BookmarkCategory = "memorized"
+	CategoryReflect   BookmarkCategory = "reflect"
+	CategoryDua       BookmarkCategory = "dua"
+)
+
+// categoryColors are the colors used for a category when the bookmark has
+// none of its own.
+var categoryColors = map[BookmarkCategory]string{
+	CategoryMemorized: "#2e7d32",
+	CategoryReflect:   "#1565c0",
+	CategoryDua:       "#f9a825",
+}
+
+var colorRE = regexp.MustCompile(`^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|[a-zA-Z]+)$`)
+
+// DisplayColor returns the bookmark color, falling back to the color of its
+// category.
+func (b Bookmark) DisplayColor() string {
+	if b.Color != "" {
+		return b.Color
+	}
+	return categoryColors[b.Category]
+}
+
+// BookmarkFilter selects bookmarks by label. Zero fields match any value.
+type BookmarkFilter struct {
+	Category   BookmarkCategory
+	Color      string
+	Collection string
+}
+
+func (f BookmarkFilter) match(b Bookmark) bool {
+	return (f.Category == "" || b.Category == f.Category) &&
+		(f.Color == "" || strings.EqualFold(b.Color, f.Color)) &&
+		(f.Collection == "" || b.Collection == f.Collection)
+}
+
+func (q *QuranService) getBookmark(ctx context.Context, verseKey string) (Bookmark, error) {
+	var b Bookmark
+	err := q.getValue(ctx, bucketBookmarks, verseKey, &b)
+	return b, err
+}
+
+func (q *QuranService) putBookmark(ctx context.Context, b Bookmark) error {
+	return q.putValue(ctx, bucketBookmarks, b.VerseKey.String(), b)
+}
+
+// AddBookmark bookmarks the verse with the given key, replacing the note of
+// an existing bookmark on it.
+func (q *QuranService) AddBookmark(ctx context.Context, key, note string) (Bookmark, error) {
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return Bookmark{}, err
+	}
+	key = verseKey(chapter, verse)
+
+	b, err := q.getBookmark(ctx, key)
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		b = Bookmark{VerseKey: VerseKey(key), CreatedAt: time.Now().UTC()}
+	case err != nil:
+		return Bookmark{}, err
+	}
+	b.Note = note
+
+	if err := q.putBookmark(ctx, b); err != nil {
+		return Bookmark{}, err
+	}
+	return b, nil
+}
+
+// LabelBookmark sets the category and color of the bookmark on the verse.
+// Empty values clear the label.
+func (q *QuranService) LabelBookmark(ctx context.Context, key string, category BookmarkCategory, color string) (Bookmark, error) {
+	if color != "" && !colorRE.MatchString(color) {
+		return Bookmark{}, fmt.Errorf("invalid color %q: want a name or #rgb hex", color)
+	}
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return Bookmark{}, err
+	}
+
+	b, err := q.getBookmark(ctx, verseKey(chapter, verse))
+	if err != nil {
+		return Bookmark{}, err
+	}
+	b.Category, b.Color = category, color
+	return b, q.putBookmark(ctx, b)
+}
+
+// DeleteBookmark moves the bookmark on the verse to the trash.
+func (q *QuranService) DeleteBookmark(ctx context.Context, key string) error {
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return err
+	}
+	return q.softDelete(ctx, bucketBookmarks, verseKey(chapter, verse))
+}
+
+// ListBookmarks returns the bookmarks matching filter in mushaf order.
+func (q *QuranService) ListBookmarks(ctx context.Context, filter BookmarkFilter) ([]Bookmark, error) {
+	var out []Bookmark
+	err := q.iterate(ctx, bucketBookmarks, func(key string, value []byte) error {
+		var b Bookmark
+		if err := valueDecode(value, &b); err != nil {
+			return err
+		}
+		if filter.match(b) {
+			out = append(out, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].VerseKey.Compare(out[j].VerseKey) < 0
+	})
+	return out, nil
+}
+
+// ReadingPosition is the verse the reader last stopped at.
+type ReadingPosition struct {
+	VerseKey  VerseKey  `json:"verse_key"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetLastRead records key as the reading position.
+func (q *QuranService) SetLastRead(ctx context.Context, key string) error {
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return err
+	}
+	pos := ReadingPosition{VerseKey: NewVerseKey(chapter, verse), UpdatedAt: time.Now().UTC()}
+	return q.putValue(ctx, bucketReading, keyLastRead, pos)
+}
+
+// GetLastRead returns the reading position, or ErrKeyNotFound when none
+// was recorded.
+func (q *QuranService) GetLastRead(ctx context.Context) (ReadingPosition, error) {
+	var pos ReadingPosition
+	err := q.getValue(ctx, bucketReading, keyLastRead, &pos)
+	return pos, err
+}
+
+const bookmarksUsage = "usage: bookmarks list [-category c] [-color c] [-collection c]|add <verse> [note]|label [-color c] <verse> [category]|rm <verse>|last-read [verse]|import [-format csv|quran-android|ayat] <file>"
+
+func runBookmarks(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) == 0 {
+		return errors.New(bookmarksUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		fs := flag.NewFlagSet("bookmarks list", flag.ContinueOnError)
+		category := fs.String("category", "", "only list bookmarks in this category")
+		color := fs.String("color", "", "only list bookmarks with this color")
+		collection := fs.String("collection", "", "only list bookmarks in this collection")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		bookmarks, err := q.ListBookmarks(ctx, BookmarkFilter{
+			Category:   BookmarkCategory(*category),
+			Color:      *color,
+			Collection: *collection,
+		})
+		if err != nil {
+			return err
+		}
+		for _, b := range bookmarks {
+			fmt.Printf("%s\t%s\t%s\t%s\n", b.VerseKey, b.CreatedAt.Format(time.DateOnly), b.Category, b.Note)
+		}
+		return nil
+	case "label":
+		fs := flag.NewFlagSet("bookmarks label", flag.ContinueOnError)
+		color := fs.String("color", "", "color name or #rgb hex")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() < 1 || fs.NArg() > 2 {
+			return errors.New(bookmarksUsage)
+		}
+		_, err := q.LabelBookmark(ctx, fs.Arg(0), BookmarkCategory(fs.Arg(1)), *color)
+		return err
+	case "add":
+		if len(args) < 2 {
+			return errors.New(bookmarksUsage)
+		}
+		_, err := q.AddBookmark(ctx, args[1], strings.Join(args[2:], " "))
+		return err
+	case "rm":
+		if len(args) != 2 {
+			return errors.New(bookmarksUsage)
+		}
+		return q.DeleteBookmark(ctx, args[1])
+	case "last-read":
+		if len(args) == 2 {
+			return q.SetLastRead(ctx, args[1])
+		}
+		pos, err := q.GetLastRead(ctx)
+		if errors.Is(err, ErrKeyNotFound) {
+			fmt.Println("no reading position recorded")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\t%s\n", pos.VerseKey, pos.UpdatedAt.Format(time.DateTime))
+		return nil
+	case "import":
+		fs := flag.NewFlagSet("bookmarks import", flag.ContinueOnError)
+		format := fs.String("format", string(ImportCSV), "source format: csv, quran-android or ayat")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("usage: bookmarks import [-format csv|quran-android|ayat] <file>")
+		}
+
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		report, err := q.ImportBookmarks(ctx, f, ImportFormat(*format))
+		if err != nil {
+			return err
+		}
+		for _, s := range report.Skipped {
+			fmt.Printf("skipped: %s\n", s)
+		}
+		fmt.Printf("imported %d bookmarks, %d already present, %d skipped\n",
+			report.Imported, report.Duplicates, len(report.Skipped))
+		return nil
+	default:
+		return fmt.Errorf("unknown bookmarks command: %q", args[0])
+	}
+}
@@ -0,0 +1,214 @@
+package quranapi
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ImportFormat string
+
+const (
+	// ImportCSV reads rows of verse_key[,note[,collection]], with an
+	// optional header row.
+	ImportCSV ImportFormat = "csv"
+	// ImportQuranAndroid reads the JSON backup written by the Quran for
+	// Android app.
+	ImportQuranAndroid ImportFormat = "quran-android"
+	// ImportAyat reads the JSON bookmark export of the Ayat app: an array
+	// of {sura, aya, title, note, date} objects.
+	ImportAyat ImportFormat = "ayat"
+)
+
+type ImportReport struct {
+	Imported   int
+	Duplicates int
+	// Skipped describes entries that could not be mapped to a verse.
+	Skipped []string
+}
+
+// ParseBookmarks reads bookmarks exported by another app. Entries that can't
+// be mapped to a verse are described in skipped rather than failing the
+// whole import.
+func ParseBookmarks(r io.Reader, format ImportFormat) (bookmarks []Bookmark, skipped []string, err error) {
+	switch format {
+	case ImportCSV:
+		return parseCSVBookmarks(r)
+	case ImportQuranAndroid:
+		return parseQuranAndroidBookmarks(r)
+	case ImportAyat:
+		return parseAyatBookmarks(r)
+	default:
+		return nil, nil, fmt.Errorf("unsupported bookmark format: %q", format)
+	}
+}
+
+// ImportBookmarks adds the bookmarks read from r. A verse that is already
+// bookmarked keeps its existing bookmark.
+func (q *QuranService) ImportBookmarks(ctx context.Context, r io.Reader, format ImportFormat) (ImportReport, error) {
+	bookmarks, skipped, err := ParseBookmarks(r, format)
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	report := ImportReport{Skipped: skipped}
+	for _, b := range bookmarks {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		_, err := q.getBookmark(ctx, b.VerseKey.String())
+		if err == nil {
+			report.Duplicates++
+			continue
+		}
+		if !errors.Is(err, ErrKeyNotFound) {
+			return report, err
+		}
+
+		if err := q.putBookmark(ctx, b); err != nil {
+			return report, err
+		}
+		report.Imported++
+	}
+	return report, nil
+}
+
+func parseCSVBookmarks(r io.Reader) ([]Bookmark, []string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var (
+		out     []Bookmark
+		skipped []string
+	)
+	now := time.Now().UTC()
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rec) == 0 || (line == 1 && strings.EqualFold(rec[0], "verse_key")) {
+			continue
+		}
+
+		chapter, verse, err := ValidateVerseKey(strings.TrimSpace(rec[0]))
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("line %d: %s", line, err))
+			continue
+		}
+
+		b := Bookmark{VerseKey: NewVerseKey(chapter, verse), CreatedAt: now}
+		if len(rec) > 1 {
+			b.Note = rec[1]
+		}
+		if len(rec) > 2 {
+			b.Collection = rec[2]
+		}
+		out = append(out, b)
+	}
+	return out, skipped, nil
+}
+
+func parseQuranAndroidBookmarks(r io.Reader) ([]Bookmark, []string, error) {
+	var backup struct {
+		Tags []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"tags"`
+		Bookmarks []struct {
+			ID        int64   `json:"id"`
+			Sura      *int    `json:"sura"`
+			Ayah      *int    `json:"ayah"`
+			Page      int     `json:"page"`
+			Timestamp int64   `json:"timestamp"` // unix millis
+			Tags      []int64 `json:"tags"`
+		} `json:"bookmarks"`
+	}
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return nil, nil, err
+	}
+
+	tags := make(map[int64]string, len(backup.Tags))
+	for _, t := range backup.Tags {
+		tags[t.ID] = t.Name
+	}
+
+	var (
+		out     []Bookmark
+		skipped []string
+	)
+	for _, bm := range backup.Bookmarks {
+		if bm.Sura == nil || bm.Ayah == nil {
+			skipped = append(skipped, fmt.Sprintf("bookmark %d: page %d bookmark has no verse", bm.ID, bm.Page))
+			continue
+		}
+		if err := ValidateVerse(*bm.Sura, *bm.Ayah); err != nil {
+			skipped = append(skipped, fmt.Sprintf("bookmark %d: %s", bm.ID, err))
+			continue
+		}
+
+		b := Bookmark{
+			VerseKey:  NewVerseKey(*bm.Sura, *bm.Ayah),
+			CreatedAt: time.UnixMilli(bm.Timestamp).UTC(),
+		}
+		if len(bm.Tags) > 0 {
+			b.Collection = tags[bm.Tags[0]]
+		}
+		out = append(out, b)
+	}
+	return out, skipped, nil
+}
+
+func parseAyatBookmarks(r io.Reader) ([]Bookmark, []string, error) {
+	var entries []struct {
+		Sura  int    `json:"sura"`
+		Aya   int    `json:"aya"`
+		Title string `json:"title"`
+		Note  string `json:"note"`
+		Date  string `json:"date"`
+	}
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		out     []Bookmark
+		skipped []string
+	)
+	for i, e := range entries {
+		if e.Sura == 0 || e.Aya == 0 {
+			skipped = append(skipped, "entry "+strconv.Itoa(i+1)+": missing sura or aya")
+			continue
+		}
+		if err := ValidateVerse(e.Sura, e.Aya); err != nil {
+			skipped = append(skipped, "entry "+strconv.Itoa(i+1)+": "+err.Error())
+			continue
+		}
+
+		created, err := time.Parse(time.RFC3339, e.Date)
+		if err != nil {
+			created = time.Now().UTC()
+		}
+		note := e.Note
+		if e.Title != "" {
+			note = strings.TrimSpace(e.Title + "\n" + e.Note)
+		}
+		out = append(out, Bookmark{
+			VerseKey:  NewVerseKey(e.Sura, e.Aya),
+			Note:      note,
+			CreatedAt: created,
+		})
+	}
+	return out, skipped, nil
+}
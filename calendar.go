@@ -0,0 +1,319 @@
+package quranapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const bucketReadingOccasions = "reading_occasions"
+
+var (
+	// ErrReadingOccasionNotFound is returned for occasion names the user
+	// didn't add.
+	ErrReadingOccasionNotFound = errors.New("reading occasion not found")
+	// ErrCuratedReadingOccasion is returned replacing a curated occasion.
+	ErrCuratedReadingOccasion = errors.New("curated reading occasions are read-only")
+)
+
+// HijriDate is a date of the Islamic calendar.
+type HijriDate struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+var hijriMonths = [...]string{
+	"Muharram", "Safar", "Rabi al-Awwal", "Rabi al-Thani", "Jumada al-Ula", "Jumada al-Akhirah",
+	"Rajab", "Shaban", "Ramadan", "Shawwal", "Dhul Qadah", "Dhul Hijjah",
+}
+
+func (d HijriDate) String() string {
+	if d.Month < 1 || d.Month > 12 {
+		return fmt.Sprintf("%d-%02d-%02d AH", d.Year, d.Month, d.Day)
+	}
+	return fmt.Sprintf("%d %s %d AH", d.Day, hijriMonths[d.Month-1], d.Year)
+}
+
+// ToHijri converts the calendar day of t to the tabular Islamic calendar.
+// The tabular calendar is arithmetic, so it may be a day or two off from
+// calendars following the sighting of the moon; WithHijriAdjustment
+// corrects for a local calendar.
+func ToHijri(t time.Time) HijriDate {
+	days := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+	// the civil epoch, 16 July 622 (Julian), is Julian day 1948440
+	l := int(days) + 2440588 - 1948440 + 10632
+	n := (l - 1) / 10631
+	l = l - 10631*n + 354
+	j := ((10985-l)/5316)*((50*l)/17719) + (l/5670)*((43*l)/15238)
+	l = l - ((30-j)/15)*((17719*j)/50) - (j/16)*((15238*j)/43) + 29
+	m := (24 * l) / 709
+	return HijriDate{Year: 30*n + j - 30, Month: m, Day: l - (709*m)/24}
+}
+
+// WithHijriAdjustment shifts the Hijri dates of SuggestedReading by days,
+// for places whose calendar, set by sighting the moon, runs ahead of or
+// behind the tabular one.
+func WithHijriAdjustment(days int) Option {
+	return func(q *QuranService) {
+		q.hijriAdjustment = days
+	}
+}
+
+// ReadingOccasion is a day or season with verses suited to it, such as
+// Ramadan or Friday. An occasion falls in HijriMonth, on HijriDays of it
+// or the whole month when none are given, or on Weekday.
+type ReadingOccasion struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	HijriMonth  int    `json:"hijri_month,omitempty"`
+	HijriDays   []int  `json:"hijri_days,omitempty"`
+	// Weekday is the lower case English name of a day of the week.
+	Weekday string `json:"weekday,omitempty"`
+	// Selections are verse keys and ranges such as "97:1-5", as in
+	// collections.
+	Selections []string `json:"selections"`
+	// Curated occasions ship with the service and cannot be changed.
+	Curated bool `json:"curated"`
+}
+
+// Validate checks that o has a name, falls on some day and has valid
+// selections.
+func (o ReadingOccasion) Validate() error {
+	if !collectionNameRE.MatchString(o.Name) {
+		return fmt.Errorf("invalid occasion name %q: want lower case words joined by hyphens", o.Name)
+	}
+	if o.HijriMonth == 0 && o.Weekday == "" {
+		return fmt.Errorf("occasion %s: no hijri month or weekday", o.Name)
+	}
+	if o.HijriMonth < 0 || o.HijriMonth > 12 {
+		return fmt.Errorf("occasion %s: invalid hijri month %d", o.Name, o.HijriMonth)
+	}
+	for _, d := range o.HijriDays {
+		if o.HijriMonth == 0 || d < 1 || d > 30 {
+			return fmt.Errorf("occasion %s: invalid hijri day %d", o.Name, d)
+		}
+	}
+	if o.Weekday != "" && parseWeekday(o.Weekday) < 0 {
+		return fmt.Errorf("occasion %s: invalid weekday %q", o.Name, o.Weekday)
+	}
+	if len(o.Selections) == 0 {
+		return fmt.Errorf("occasion %s: no selections", o.Name)
+	}
+	_, err := Collection{Name: o.Name, Selections: o.Selections}.Scopes()
+	return err
+}
+
+func parseWeekday(s string) time.Weekday {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(s, d.String()) {
+			return d
+		}
+	}
+	return -1
+}
+
+// falls reports whether o falls on the day with the given weekday and
+// Hijri date.
+func (o ReadingOccasion) falls(weekday time.Weekday, hijri HijriDate) bool {
+	if o.Weekday != "" && parseWeekday(o.Weekday) != weekday {
+		return false
+	}
+	if o.HijriMonth != 0 {
+		if o.HijriMonth != hijri.Month {
+			return false
+		}
+		if len(o.HijriDays) > 0 && !slices.Contains(o.HijriDays, hijri.Day) {
+			return false
+		}
+	}
+	return true
+}
+
+//go:embed reading_occasions.json
+var curatedReadingOccasionsJSON []byte
+
+var curatedReadingOccasions = func() []ReadingOccasion {
+	var occasions []ReadingOccasion
+	if err := json.Unmarshal(curatedReadingOccasionsJSON, &occasions); err != nil {
+		panic("reading_occasions.json: " + err.Error())
+	}
+	for i := range occasions {
+		occasions[i].Curated = true
+		if err := occasions[i].Validate(); err != nil {
+			panic("reading_occasions.json: " + err.Error())
+		}
+	}
+	return occasions
+}()
+
+// ReadingOccasions returns the curated occasions followed by those the
+// user added, sorted by name.
+func (q *QuranService) ReadingOccasions(ctx context.Context) ([]ReadingOccasion, error) {
+	out := append([]ReadingOccasion(nil), curatedReadingOccasions...)
+	var user []ReadingOccasion
+	err := q.iterate(ctx, bucketReadingOccasions, func(key string, value []byte) error {
+		var o ReadingOccasion
+		if err := valueDecode(value, &o); err != nil {
+			return err
+		}
+		user = append(user, o)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(user, func(i, j int) bool { return user[i].Name < user[j].Name })
+	return append(out, user...), nil
+}
+
+// SaveReadingOccasion adds o to the user's occasions, replacing any with
+// the same name. Curated occasions cannot be replaced.
+func (q *QuranService) SaveReadingOccasion(ctx context.Context, o ReadingOccasion) error {
+	o.Curated = false
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if slices.ContainsFunc(curatedReadingOccasions, func(c ReadingOccasion) bool { return c.Name == o.Name }) {
+		return fmt.Errorf("%w: %s", ErrCuratedReadingOccasion, o.Name)
+	}
+	for i, sel := range o.Selections {
+		var err error
+		if o.Selections[i], err = normalizeSelection(sel); err != nil {
+			return err
+		}
+	}
+	return q.putValue(ctx, bucketReadingOccasions, o.Name, o)
+}
+
+// DeleteReadingOccasion moves the user occasion called name to the trash.
+func (q *QuranService) DeleteReadingOccasion(ctx context.Context, name string) error {
+	err := q.softDelete(ctx, bucketReadingOccasions, name)
+	if errors.Is(err, ErrKeyNotFound) {
+		return fmt.Errorf("%w: %s", ErrReadingOccasionNotFound, name)
+	}
+	return err
+}
+
+// ImportReadingOccasions saves the occasions of a JSON array read from r,
+// as SaveReadingOccasion does.
+func (q *QuranService) ImportReadingOccasions(ctx context.Context, r io.Reader) (int, error) {
+	var occasions []ReadingOccasion
+	if err := json.NewDecoder(r).Decode(&occasions); err != nil {
+		return 0, fmt.Errorf("read occasions: %w", err)
+	}
+	for i, o := range occasions {
+		if err := q.SaveReadingOccasion(ctx, o); err != nil {
+			return i, err
+		}
+	}
+	return len(occasions), nil
+}
+
+// ReadingSuggestion is the verses suggested for a day.
+type ReadingSuggestion struct {
+	Date      string            `json:"date"`
+	Hijri     HijriDate         `json:"hijri"`
+	Occasions []ReadingOccasion `json:"occasions"`
+}
+
+// SuggestedReading returns the occasions, curated and the user's, falling
+// on the calendar day of date, with the verses suggested for each.
+func (q *QuranService) SuggestedReading(ctx context.Context, date time.Time) (ReadingSuggestion, error) {
+	hijri := ToHijri(date.AddDate(0, 0, q.hijriAdjustment))
+	s := ReadingSuggestion{Date: date.Format(time.DateOnly), Hijri: hijri, Occasions: []ReadingOccasion{}}
+	occasions, err := q.ReadingOccasions(ctx)
+	if err != nil {
+		return s, err
+	}
+	for _, o := range occasions {
+		if o.falls(date.Weekday(), hijri) {
+			s.Occasions = append(s.Occasions, o)
+		}
+	}
+	return s, nil
+}
+
+const suggestUsage = "usage: suggest [-date yyyy-mm-dd] | suggest occasions | suggest add <file> | suggest rm <name>"
+
+func runSuggest(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "occasions":
+			occasions, err := q.ReadingOccasions(ctx)
+			if err != nil {
+				return err
+			}
+			for _, o := range occasions {
+				when := o.Weekday
+				if o.HijriMonth != 0 {
+					when = hijriMonths[o.HijriMonth-1]
+					for i, d := range o.HijriDays {
+						sep := ","
+						if i == 0 {
+							sep = " "
+						}
+						when += fmt.Sprintf("%s%d", sep, d)
+					}
+				}
+				fmt.Printf("%s\t%s\t%s\t%s\n", o.Name, when, o.Title, strings.Join(o.Selections, " "))
+			}
+			return nil
+		case "add":
+			if len(args) != 2 {
+				return errors.New(suggestUsage)
+			}
+			f, err := os.Open(args[1])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			n, err := q.ImportReadingOccasions(ctx, f)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("saved %d occasions\n", n)
+			return nil
+		case "rm":
+			if len(args) != 2 {
+				return errors.New(suggestUsage)
+			}
+			return q.DeleteReadingOccasion(ctx, args[1])
+		}
+	}
+
+	fs := flag.NewFlagSet("suggest", flag.ContinueOnError)
+	dateFlag := fs.String("date", "", "date to suggest for (defaults to today)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	date := time.Now()
+	if *dateFlag != "" {
+		var err error
+		if date, err = time.Parse(time.DateOnly, *dateFlag); err != nil {
+			return err
+		}
+	}
+	s, err := q.SuggestedReading(ctx, date)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s (%s)\n", s.Date, s.Hijri)
+	for _, o := range s.Occasions {
+		fmt.Printf("\n%s: %s\n", o.Title, strings.Join(o.Selections, " "))
+		if o.Description != "" {
+			fmt.Println(o.Description)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package quranapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const bucketTranslationHashes = "translation_hashes"
+
+// TranslationChange is a verse translation whose content differed from
+// the previously stored one, or was stored for the first time, at
+// ChangedAt.
+type TranslationChange struct {
+	VerseKey   VerseKey  `json:"verse_key"`
+	ResourceID int       `json:"resource_id"`
+	Hash       string    `json:"hash"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+type translationHash struct {
+	Hash      string
+	ChangedAt time.Time
+}
+
+// WithContentHash sets the hash used to detect translation changes. It
+// defaults to SHA-256; changing it marks every translation changed the
+// next time its chapter is stored.
+func WithContentHash(h func() hash.Hash) Option {
+	return func(q *QuranService) {
+		q.contentHash = h
+	}
+}
+
+func (q *QuranService) hashContent(text string) string {
+	newHash := q.contentHash
+	if newHash == nil {
+		newHash = sha256.New
+	}
+	h := newHash()
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func translationHashKey(resourceID, chapter int) string {
+	return strconv.Itoa(resourceID) + "/" + strconv.Itoa(chapter)
+}
+
+// recordTranslationHashes compares the translations of a chapter being
+// stored with their recorded hashes, stamping those that differ. Hashes
+// outlive the chapter, so a refetched chapter is compared with what was
+// stored before it was dropped.
+func (q *QuranService) recordTranslationHashes(ctx context.Context, chapter Chapter) error {
+	byResource := make(map[int]map[int]string)
+	for _, v := range chapter.Verses {
+		for _, tr := range v.Translations {
+			if byResource[tr.ResourceID] == nil {
+				byResource[tr.ResourceID] = make(map[int]string)
+			}
+			byResource[tr.ResourceID][v.VerseNumber] = q.hashContent(tr.Text)
+		}
+	}
+
+	now := time.Now().UTC()
+	for resourceID, hashes := range byResource {
+		key := translationHashKey(resourceID, chapter.ID)
+		var stored map[int]translationHash
+		err := q.getValue(ctx, bucketTranslationHashes, key, &stored)
+		if err != nil && !errors.Is(err, ErrKeyNotFound) {
+			return err
+		}
+		if stored == nil {
+			stored = make(map[int]translationHash)
+		}
+
+		changed := false
+		for verse, h := range hashes {
+			if stored[verse].Hash != h {
+				stored[verse] = translationHash{Hash: h, ChangedAt: now}
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		if err := q.putValue(ctx, bucketTranslationHashes, key, stored); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChangedSince lists the verse translations whose content changed after t,
+// in mushaf order, so that exports and indexes can be refreshed
+// incrementally. Changes are detected when a chapter is stored, so
+// corrections upstream show up once the chapter is refetched.
+func (q *QuranService) ChangedSince(ctx context.Context, t time.Time) ([]TranslationChange, error) {
+	var out []TranslationChange
+	err := q.store.Iterate(ctx, bucketTranslationHashes, func(key string, value []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		resource, chapter, ok := strings.Cut(key, "/")
+		if !ok {
+			return nil
+		}
+		resourceID, err := strconv.Atoi(resource)
+		if err != nil {
+			return nil
+		}
+		chapterID, err := strconv.Atoi(chapter)
+		if err != nil {
+			return nil
+		}
+
+		var stored map[int]translationHash
+		if err := valueDecode(value, &stored); err != nil {
+			return fmt.Errorf("translation hashes %s: %w", key, err)
+		}
+		for verse, h := range stored {
+			if h.ChangedAt.After(t) {
+				out = append(out, TranslationChange{
+					VerseKey:   NewVerseKey(chapterID, verse),
+					ResourceID: resourceID,
+					Hash:       h.Hash,
+					ChangedAt:  h.ChangedAt,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].VerseKey != out[j].VerseKey {
+			return out[i].VerseKey.Compare(out[j].VerseKey) < 0
+		}
+		return out[i].ResourceID < out[j].ResourceID
+	})
+	return out, nil
+}
+
+func runChanges(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("changes", flag.ContinueOnError)
+	since := fs.String("since", "24h", "a duration ago, or a date or RFC 3339 time")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var t time.Time
+	if d, err := time.ParseDuration(*since); err == nil {
+		t = time.Now().Add(-d)
+	} else if t, err = time.Parse(time.RFC3339, *since); err != nil {
+		if t, err = time.Parse(time.DateOnly, *since); err != nil {
+			return fmt.Errorf("invalid -since %q", *since)
+		}
+	}
+
+	changes, err := q.ChangedSince(ctx, t)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		fmt.Printf("%s\t%d\t%s\n", c.VerseKey, c.ResourceID, c.ChangedAt.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ChapterByName returns the summary of the chapter with the given simple,
+// transliterated or Arabic name, ignoring case, diacritics, spaces and
+// punctuation: "Al-Fatihah", "al fatihah" and "الفاتحة" all name chapter 1.
+func (q *QuranService) ChapterByName(ctx context.Context, name string) (ChapterSummary, error) {
+	key := nameKey(name)
+	if key == "" {
+		return ChapterSummary{}, fmt.Errorf("%w: %q", ErrChapterNotFound, name)
+	}
+	summaries, err := q.ChaptersSummary(ctx)
+	if err != nil {
+		return ChapterSummary{}, err
+	}
+	for _, s := range summaries {
+		for _, n := range s.names() {
+			if nameKey(n) == key {
+				return s, nil
+			}
+		}
+	}
+	return ChapterSummary{}, fmt.Errorf("%w: %q", ErrChapterNotFound, name)
+}
+
+func (s ChapterSummary) names() []string {
+	return []string{s.NameSimple, s.NameTransliteration, s.NameArabic}
+}
+
+// latinFold maps the accented letters of transliterated names, such as
+// "Al-Fātiĥah", to plain ones.
+var latinFold = map[rune]rune{
+	'ā': 'a', 'á': 'a', 'â': 'a',
+	'ī': 'i', 'í': 'i', 'î': 'i',
+	'ū': 'u', 'ú': 'u', 'û': 'u',
+	'ĥ': 'h', 'ḥ': 'h', 'ḍ': 'd', 'ṣ': 's', 'ṭ': 't', 'ẓ': 'z',
+}
+
+// nameKey folds a chapter name for comparison, keeping its lower cased
+// letters and digits.
+func nameKey(name string) string {
+	return strings.Join(nameWords(name), "")
+}
+
+// nameWords splits a folded chapter name into words. Modifier letters,
+// such as the ʿ of ʿAbasa, are dropped with the punctuation.
+func nameWords(name string) []string {
+	return strings.FieldsFunc(foldName(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) || unicode.Is(unicode.Lm, r)
+	})
+}
+
+func foldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if f, ok := latinFold[r]; ok {
+			return f
+		}
+		return r
+	}, normalizeArabic(strings.ToLower(name)))
+}
+
+// ChapterMatch is a chapter found by FindChapter, scored from 0 to 1.
+type ChapterMatch struct {
+	ChapterSummary
+	Score float64
+}
+
+// minChapterScore is the lowest score FindChapter returns.
+const minChapterScore = 0.6
+
+// FindChapter resolves a chapter number or a loosely spelled name, such as
+// "36", "Yaseen", "Ya-Sin" or "يس", to the chapters it may mean, best
+// first. Names are compared with all the names of each chapter, ignoring
+// the definite article and vowel spellings, and then by edit distance.
+// ErrChapterNotFound is returned when nothing is close.
+func (q *QuranService) FindChapter(ctx context.Context, query string) ([]ChapterMatch, error) {
+	query = strings.TrimSpace(query)
+	if id, err := strconv.Atoi(query); err == nil {
+		summary, err := q.GetChapterSummary(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return []ChapterMatch{{ChapterSummary: summary, Score: 1}}, nil
+	}
+
+	key := fuzzyNameKey(query)
+	if key == "" {
+		return nil, fmt.Errorf("%w: %q", ErrChapterNotFound, query)
+	}
+	summaries, err := q.ChaptersSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []ChapterMatch
+	for _, s := range summaries {
+		best := 0.0
+		for _, n := range append(s.names(), s.TranslatedName.Name) {
+			best = max(best, nameScore(key, fuzzyNameKey(n)))
+		}
+		if best >= minChapterScore {
+			matches = append(matches, ChapterMatch{ChapterSummary: s, Score: best})
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrChapterNotFound, query)
+	}
+	slices.SortStableFunc(matches, func(a, b ChapterMatch) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return a.ID - b.ID
+	})
+	return matches, nil
+}
+
+// fuzzyNameKey folds a chapter name further than nameKey: a leading
+// definite article is dropped, "ee", "oo" and "ou" are spelled as the
+// vowels they stand for and doubled letters are collapsed, so that
+// "Yaseen" and "Ya-Sin" or "An-Nas" and "Nas" fold alike.
+func fuzzyNameKey(name string) string {
+	words := nameWords(name)
+	if len(words) > 1 && isArticle(words[0], words[1]) {
+		words = words[1:]
+	}
+	if len(words) > 0 {
+		if w, ok := strings.CutPrefix(words[0], "ال"); ok && len([]rune(w)) > 1 {
+			words[0] = w
+		}
+	}
+
+	key := strings.NewReplacer("ee", "i", "oo", "u", "ou", "u").Replace(strings.Join(words, ""))
+	var b strings.Builder
+	var last rune
+	for _, r := range key {
+		if r != last {
+			b.WriteRune(r)
+		}
+		last = r
+	}
+	return b.String()
+}
+
+// isArticle reports whether word is a definite article before next: "al",
+// "the", or "al" assimilated to a sun letter as in "An-Nas" and "Ash-Shams".
+func isArticle(word, next string) bool {
+	switch {
+	case word == "al", word == "el", word == "the":
+		return true
+	case len(word) >= 2 && word[0] == 'a':
+		return strings.HasPrefix(next, word[1:])
+	}
+	return false
+}
+
+// nameScore scores how close name is to query, both folded by
+// fuzzyNameKey: 1 when equal, 0.9 when query begins name and otherwise by
+// edit distance.
+func nameScore(query, name string) float64 {
+	switch {
+	case name == "":
+		return 0
+	case query == name:
+		return 1
+	case len([]rune(query)) >= 3 && strings.HasPrefix(name, query):
+		return 0.9
+	}
+	a, b := []rune(query), []rune(name)
+	return 1 - float64(editDistance(a, b))/float64(max(len(a), len(b)))
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func runChapter(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("chapter", flag.ContinueOnError)
+	all := fs.Bool("all", false, "list every candidate, best first")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: chapter [-all] <number|name>")
+	}
+
+	matches, err := q.FindChapter(ctx, strings.Join(fs.Args(), " "))
+	if err != nil {
+		return err
+	}
+	if !*all {
+		matches = matches[:1]
+	}
+	for _, m := range matches {
+		fmt.Printf("%d\t%s\t%s\t%d verses\t%s", m.ID, m.NameSimple, m.NameArabic, m.VerseCount, m.RevelationPlace)
+		if *all {
+			fmt.Printf("\t%.2f", m.Score)
+		}
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package quranapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Checksum is the hex SHA-256 of the chapter's canonical text: each verse
+// key and its Uthmani text, one verse per line.
+func (c Chapter) Checksum() string {
+	h := sha256.New()
+	for _, v := range c.Verses {
+		io.WriteString(h, v.VerseKey.String()+"\t"+v.TextMadani+"\n")
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type VerifyFailure struct {
+	ChapterID int
+	Reason    string
+}
+
+type VerifyReport struct {
+	Checked int
+	Failed  []VerifyFailure
+}
+
+func (r VerifyReport) OK() bool {
+	return len(r.Failed) == 0
+}
+
+// Verify checks every cached chapter against the checksum recorded when it
+// was stored, reporting entries that fail to decode or whose text changed.
+func (q *QuranService) Verify(ctx context.Context) (VerifyReport, error) {
+	var report VerifyReport
+	err := q.store.Iterate(ctx, bucketChapters, func(key string, value []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil // not a chapter, e.g. the summaries entry
+		}
+		report.Checked++
+
+		fail := func(reason string) {
+			report.Failed = append(report.Failed, VerifyFailure{ChapterID: id, Reason: reason})
+		}
+
+		var chapter Chapter
+		if err := valueDecode(value, &chapter); err != nil {
+			fail("decode: " + err.Error())
+			return nil
+		}
+
+		want, err := q.store.Get(ctx, bucketChecksums, key)
+		if err != nil {
+			fail("no checksum recorded: " + err.Error())
+			return nil
+		}
+		if got := chapter.Checksum(); got != string(want) {
+			fail(fmt.Sprintf("checksum mismatch: stored %s, computed %s", want, got))
+		}
+		return nil
+	})
+	return report, err
+}
+
+func runVerify(ctx context.Context, q *QuranService) error {
+	report, err := q.Verify(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range report.Failed {
+		fmt.Printf("chapter %d: %s\n", f.ChapterID, f.Reason)
+	}
+	fmt.Printf("checked %d chapters, %d failed\n", report.Checked, len(report.Failed))
+
+	if !report.OK() {
+		return fmt.Errorf("%d chapters failed verification", len(report.Failed))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package quranapi
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Chunk struct {
+	VerseKey VerseKey
+	// Part is the 1-based index of the chunk within the verse, out of Parts.
+	Part  int
+	Parts int
+	Text  string
+}
+
+// ChunkVerses splits the verses in scope into chunks of at most maxRunes
+// runes each, for front ends with message length limits. A verse that fits
+// stays whole.
+func (q *QuranService) ChunkVerses(ctx context.Context, scope Scope, maxRunes int) ([]Chunk, error) {
+	verses, err := q.ScopeVerses(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Chunk
+	for _, v := range verses {
+		parts := ChunkText(v.TextMadani, maxRunes)
+		for i, part := range parts {
+			out = append(out, Chunk{
+				VerseKey: v.VerseKey,
+				Part:     i + 1,
+				Parts:    len(parts),
+				Text:     part,
+			})
+		}
+	}
+	return out, nil
+}
+
+// ChunkText splits text at word boundaries into pieces of at most maxRunes
+// runes. When a piece has to be cut it prefers to end on a waqf (pause)
+// mark, as long as that keeps the piece at least half full. Only a single
+// word longer than maxRunes is cut mid-word.
+func ChunkText(text string, maxRunes int) []string {
+	text = strings.TrimSpace(text)
+	if maxRunes <= 0 || utf8.RuneCountInString(text) <= maxRunes {
+		return []string{text}
+	}
+
+	var (
+		chunks   []string
+		current  []string
+		size     int
+		lastStop = -1 // index into current of the last word ending on a pause mark
+	)
+
+	emit := func(words []string) {
+		chunks = append(chunks, strings.Join(words, " "))
+	}
+
+	for _, word := range chunkWords(text) {
+		wn := utf8.RuneCountInString(word)
+
+		for len(current) > 0 && size+1+wn > maxRunes {
+			cut := len(current)
+			if lastStop >= 0 && lastStop+1 < len(current) && runesIn(current[:lastStop+1]) >= maxRunes/2 {
+				cut = lastStop + 1
+			}
+			emit(current[:cut])
+			current = append([]string(nil), current[cut:]...)
+			size = runesIn(current)
+			lastStop = -1
+			for i, w := range current {
+				if endsWithWaqf(w) {
+					lastStop = i
+				}
+			}
+		}
+
+		for wn > maxRunes {
+			runes := []rune(word)
+			cut := maxRunes
+			// don't separate a letter from its diacritics
+			for cut > 1 && unicode.Is(unicode.Mn, runes[cut]) {
+				cut--
+			}
+			emit([]string{string(runes[:cut])})
+			word = string(runes[cut:])
+			wn -= cut
+		}
+
+		if len(current) > 0 {
+			size++
+		}
+		current = append(current, word)
+		size += wn
+		if endsWithWaqf(word) {
+			lastStop = len(current) - 1
+		}
+	}
+	if len(current) > 0 {
+		emit(current)
+	}
+	return chunks
+}
+
+// chunkWords splits text into words, keeping a pause mark written as its own
+// token with the word it follows.
+func chunkWords(text string) []string {
+	var words []string
+	for _, w := range strings.Fields(text) {
+		if len(words) > 0 && strings.IndexFunc(w, func(r rune) bool { return !isWaqfMark(r) }) < 0 {
+			words[len(words)-1] += " " + w
+			continue
+		}
+		words = append(words, w)
+	}
+	return words
+}
+
+// runesIn counts the runes of words joined by single spaces.
+func runesIn(words []string) int {
+	if len(words) == 0 {
+		return 0
+	}
+	n := len(words) - 1
+	for _, w := range words {
+		n += utf8.RuneCountInString(w)
+	}
+	return n
+}
+
+func isWaqfMark(r rune) bool {
+	return r >= 'ۖ' && r <= 'ۜ'
+}
+
+func endsWithWaqf(word string) bool {
+	r, _ := utf8.DecodeLastRuneInString(word)
+	return isWaqfMark(r)
+}
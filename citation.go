@@ -0,0 +1,144 @@
+package quranapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type CitationStyle int
+
+const (
+	// CitationShort cites by number, e.g. "Qur'an 2:255".
+	CitationShort CitationStyle = iota
+	// CitationLong cites by surah name, e.g. "Sūrat al-Baqarah, āyah 255".
+	CitationLong
+)
+
+type CitationFormat struct {
+	Style CitationStyle
+	// Locale selects the language of the citation: en (default), ar, fr or
+	// id.
+	Locale string
+}
+
+// Citation references a chapter or a range of its verses. A zero FromVerse
+// cites the whole chapter; a zero ToVerse cites a single verse.
+type Citation struct {
+	Chapter    int
+	NameLatin  string
+	NameArabic string
+	FromVerse  int
+	ToVerse    int
+}
+
+func (c Chapter) Cite(from, to int) Citation {
+	return Citation{
+		Chapter:    c.Number,
+		NameLatin:  c.NameTransliteration,
+		NameArabic: c.NameArabic,
+		FromVerse:  from,
+		ToVerse:    to,
+	}
+}
+
+func (c ChapterSummary) Cite(from, to int) Citation {
+	return Citation{
+		Chapter:    c.Number,
+		NameLatin:  c.NameTransliteration,
+		NameArabic: c.NameArabic,
+		FromVerse:  from,
+		ToVerse:    to,
+	}
+}
+
+func (c Citation) String() string {
+	return c.Format(CitationFormat{})
+}
+
+func (c Citation) isRange() bool {
+	return c.ToVerse != 0 && c.ToVerse != c.FromVerse
+}
+
+// verses formats the verse part using digits and the range separator.
+func (c Citation) verses(digits func(int) string, sep string) string {
+	if c.isRange() {
+		return digits(c.FromVerse) + sep + digits(c.ToVerse)
+	}
+	return digits(c.FromVerse)
+}
+
+func (c Citation) Format(f CitationFormat) string {
+	if f.Style == CitationShort {
+		return c.formatShort(f.Locale)
+	}
+	return c.formatLong(f.Locale)
+}
+
+func (c Citation) formatShort(locale string) string {
+	digits, prefix := strconv.Itoa, "Qur'an"
+	switch locale {
+	case "ar":
+		digits, prefix = arabicDigits, "القرآن"
+	case "fr":
+		prefix = "Coran"
+	case "id":
+		prefix = "QS"
+	}
+
+	ref := digits(c.Chapter)
+	if c.FromVerse != 0 {
+		ref += ":" + c.verses(digits, "-")
+	}
+	return prefix + " " + ref
+}
+
+func (c Citation) formatLong(locale string) string {
+	switch locale {
+	case "ar":
+		s := "سورة " + c.NameArabic
+		switch {
+		case c.FromVerse == 0:
+		case c.isRange():
+			s += "، الآيات " + c.verses(arabicDigits, "–")
+		default:
+			s += "، الآية " + c.verses(arabicDigits, "–")
+		}
+		return s
+	case "fr":
+		s := "Sourate " + c.NameLatin
+		switch {
+		case c.FromVerse == 0:
+		case c.isRange():
+			s += ", versets " + c.verses(strconv.Itoa, "–")
+		default:
+			s += ", verset " + c.verses(strconv.Itoa, "–")
+		}
+		return s
+	case "id":
+		s := "QS " + c.NameLatin
+		if c.FromVerse != 0 {
+			s += fmt.Sprintf(" (%d): %s", c.Chapter, c.verses(strconv.Itoa, "-"))
+		}
+		return s
+	default:
+		s := "Sūrat " + lowerArticle(c.NameLatin)
+		switch {
+		case c.FromVerse == 0:
+		case c.isRange():
+			s += ", āyāt " + c.verses(strconv.Itoa, "–")
+		default:
+			s += ", āyah " + c.verses(strconv.Itoa, "–")
+		}
+		return s
+	}
+}
+
+// lowerArticle lower cases a leading "Al-" as it is written mid-sentence,
+// "Sūrat al-Baqarah".
+func lowerArticle(name string) string {
+	if strings.HasPrefix(name, "Al-") {
+		return "al-" + name[len("Al-"):]
+	}
+	return name
+}
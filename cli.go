@@ -0,0 +1,241 @@
+package quranapi
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+)
+
+// RunCLI runs the quranapi command with the given arguments, excluding the
+// program name.
+func RunCLI(args []string) error {
+	flags := flag.NewFlagSet("quranapi", flag.ContinueOnError)
+	configPath := flags.String("config", "", "YAML or TOML config file")
+	backend := flags.String("store", string(BackendBBolt), "storage backend: bolt, bbolt, badger, fs, memory or redis")
+	path := flags.String("db", "quran.db", "database file, directory for the badger and fs backends, or redis:// URL")
+	translations := flags.String("translations", "", "comma separated translation resource ids to fetch")
+	user := flags.String("user", "", "act on the user data of this server user, such as key:3f2a9c01d4e5 or jwt:<subject>")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	// flags given explicitly take precedence over the config file and env
+	var flagErr error
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "store":
+			cfg.Store.Backend = StoreBackend(*backend)
+		case "db":
+			cfg.Store.Path = *path
+		case "translations":
+			cfg.Translations, flagErr = parseInts(*translations)
+		}
+	})
+	if flagErr != nil {
+		return flagErr
+	}
+
+	level, err := ParseLogLevel(cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	slog.SetDefault(logger)
+
+	store, err := OpenStore(cfg.Store)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	opts := append(cfg.ServiceOptions(), WithStore(store), WithLogger(logger))
+	quranSVC, err := NewQuranService(nil, opts...)
+	if err != nil {
+		return err
+	}
+	defer quranSVC.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = ContextWithRequestID(ctx, NewRequestID())
+	if *user != "" {
+		ctx = ContextWithUser(ctx, *user)
+	}
+	if args := flags.Args(); len(args) > 0 {
+		return runCommand(ctx, quranSVC, cfg, args)
+	}
+
+	deleteChapters := []int{}
+	for _, chapter := range deleteChapters {
+		if err := quranSVC.deleteChapterDB(ctx, chapter); err != nil {
+			logger.Error("delete chapter", "chapter", chapter, "err", err)
+		}
+	}
+
+	return runSync(ctx, quranSVC, cfg.Concurrency)
+}
+
+// runSync fetches every chapter into the store, concurrency at a time.
+func runSync(ctx context.Context, q *QuranService, concurrency int) error {
+	chapterSummaries, err := q.ChaptersSummary(ctx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Int32
+	)
+	sem := make(chan struct{}, concurrency)
+	for _, chapterSummary := range chapterSummaries {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(id int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			chapter, err := q.GetChapter(ctx, id)
+			if err != nil {
+				q.log(ctx).Error("sync chapter", "chapter", id, "err", err)
+				failed.Add(1)
+				return
+			}
+			q.log(ctx).Info("synced chapter", "num", chapter.Number, "chapter", chapter.NameSimple, "num_verses", len(chapter.Verses))
+		}(chapterSummary.ID)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	q.emit(ctx, Event{
+		Type:   EventSyncCompleted,
+		Synced: len(chapterSummaries) - int(failed.Load()),
+		Failed: int(failed.Load()),
+	})
+
+	if err := q.BuildSimilarityIndex(ctx); err != nil {
+		q.log(ctx).Error("build similarity index", "err", err)
+	}
+
+	if pending := q.RetryCacheWrites(ctx); pending > 0 {
+		q.log(ctx).Warn("chapters not cached", "pending", pending)
+	}
+	for _, s := range q.StoreTxStats() {
+		q.log(ctx).Info("store transactions", "op", s.Op, "namespace", s.Namespace,
+			"count", s.Count, "mean", s.Mean(), "max", s.Max, "slow", s.Slow)
+	}
+	return nil
+}
+
+func runCommand(ctx context.Context, q *QuranService, cfg Config, args []string) error {
+	switch args[0] {
+	case "export":
+		return runExport(ctx, q, args[1:])
+	case "dataset":
+		return runDataset(ctx, q, args[1:])
+	case "verify":
+		return runVerify(ctx, q)
+	case "trash":
+		return runTrash(ctx, q, args[1:])
+	case "bookmarks":
+		return runBookmarks(ctx, q, args[1:])
+	case "userdata":
+		return runUserData(ctx, q, cfg.Backup, args[1:])
+	case "annotations":
+		return runAnnotations(ctx, q, args[1:])
+	case "plan":
+		return runPlan(ctx, q, args[1:])
+	case "votd":
+		return runVerseOfTheDay(ctx, q, args[1:])
+	case "khatmah":
+		return runKhatmah(ctx, q, args[1:])
+	case "suggest":
+		return runSuggest(ctx, q, args[1:])
+	case "similar":
+		return runSimilar(ctx, q, args[1:])
+	case "glyphs":
+		return runGlyphs(ctx, q, args[1:])
+	case "changes":
+		return runChanges(ctx, q, args[1:])
+	case "stats":
+		return runStats(ctx, q, args[1:])
+	case "soak":
+		return runSoak(ctx, q, args[1:])
+	case "topics":
+		return runTopics(ctx, q, args[1:])
+	case "search":
+		return runSearch(ctx, q, args[1:])
+	case "concordance":
+		return runConcordance(ctx, q, args[1:])
+	case "frequencies":
+		return runFrequencies(ctx, q, args[1:])
+	case "ngrams":
+		return runNGrams(ctx, q, args[1:])
+	case "hifz":
+		return runHifz(ctx, q, args[1:])
+	case "serve":
+		return runServe(ctx, q, cfg.Server, cfg.Backup, args[1:])
+	case "languages":
+		return runLanguages(ctx, q, args[1:])
+	case "sync-translations":
+		return runSyncTranslations(ctx, q, args[1:])
+	case "refresh":
+		return runRefresh(ctx, q, args[1:])
+	case "backup":
+		return runBackup(ctx, q, args[1:])
+	case "restore":
+		return runRestore(ctx, q, args[1:])
+	case "compact":
+		return runCompact(ctx, q, cfg.Store, args[1:])
+	case "db":
+		return runDB(ctx, q, args[1:])
+	case "api-usage":
+		return runAPIUsage(ctx, q, args[1:])
+	case "chapter":
+		return runChapter(ctx, q, args[1:])
+	case "render":
+		return runRender(ctx, q, args[1:])
+	case "numbering":
+		return runNumbering(ctx, q, args[1:])
+	case "playlist":
+		return runPlaylist(ctx, q, args[1:])
+	case "audio":
+		return runAudio(ctx, q, args[1:])
+	case "collections":
+		return runCollections(ctx, q, args[1:])
+	case "compare":
+		return runCompare(ctx, q, args[1:])
+	case "pauses":
+		return runPauseMarks(ctx, q, args[1:])
+	case "diff-editions":
+		return runDiffEditions(ctx, q, args[1:])
+	case "sync-userdata":
+		return runSyncUserData(ctx, q, cfg.Sync, args[1:])
+	case "notes":
+		return runNotes(ctx, q, args[1:])
+	case "asbab":
+		return runAsbab(ctx, q, args[1:])
+	case "import-jsonl":
+		return runImportJSONL(ctx, q, args[1:])
+	default:
+		return fmt.Errorf("unknown command: %q", args[0])
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/alilmtech/quranapi"
+)
+
+func main() {
+	if err := quranapi.RunCLI(os.Args[1:]); err != nil {
+		slog.Error("quranapi failed", "err", err)
+		os.Exit(1)
+	}
+}
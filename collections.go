@@ -0,0 +1,411 @@
+package quranapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const bucketCollections = "collections"
+
+// ErrCollectionNotFound is returned for collections that are neither
+// curated nor defined by the user.
+var ErrCollectionNotFound = errors.New("collection not found")
+
+// ErrCuratedCollection is returned changing a curated collection.
+var ErrCuratedCollection = errors.New("curated collections are read-only")
+
+// ErrCollectionExists is returned creating a collection under a name
+// already taken.
+var ErrCollectionExists = errors.New("collection already exists")
+
+// Collection is a named selection of verses, such as the Rabbana duas.
+type Collection struct {
+	// Name identifies the collection, in lower case words joined by
+	// hyphens, such as "rabbana-duas".
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// Selections are verse keys and ranges such as "2:285-286", in the
+	// order they are read.
+	Selections []string `json:"selections"`
+	// Curated collections ship with the service and cannot be changed.
+	Curated   bool      `json:"curated"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// curatedCollectionsJSON holds the collections shipped with the service.
+//
+//go:embed collections.json
+var curatedCollectionsJSON []byte
+
+var curatedCollections = func() []Collection {
+	var cs []Collection
+	if err := json.Unmarshal(curatedCollectionsJSON, &cs); err != nil {
+		panic("collections.json: " + err.Error())
+	}
+	for i := range cs {
+		cs[i].Curated = true
+		if err := cs[i].Validate(); err != nil {
+			panic("collections.json: " + err.Error())
+		}
+	}
+	return cs
+}()
+
+var collectionNameRE = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func (c Collection) Validate() error {
+	if !collectionNameRE.MatchString(c.Name) {
+		return fmt.Errorf("invalid collection name %q: want lower case words joined by hyphens", c.Name)
+	}
+	_, err := c.Scopes()
+	return err
+}
+
+// Scopes returns the selections of c as verse ranges.
+func (c Collection) Scopes() ([]Scope, error) {
+	scopes := make([]Scope, len(c.Selections))
+	for i, sel := range c.Selections {
+		s, err := ParseScope(sel)
+		if err == nil && s.Kind != ScopeChapter {
+			err = fmt.Errorf("invalid selection %q: want a verse key or range", sel)
+		}
+		if err == nil {
+			err = s.Validate()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("collection %s: %w", c.Name, err)
+		}
+		scopes[i] = s
+	}
+	return scopes, nil
+}
+
+func curatedCollection(name string) (Collection, bool) {
+	for _, c := range curatedCollections {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Collection{}, false
+}
+
+// ListCollections returns the curated collections followed by those of the
+// user, by name.
+func (q *QuranService) ListCollections(ctx context.Context) ([]Collection, error) {
+	out := append([]Collection(nil), curatedCollections...)
+	var user []Collection
+	err := q.iterate(ctx, bucketCollections, func(key string, value []byte) error {
+		var c Collection
+		if err := valueDecode(value, &c); err != nil {
+			return err
+		}
+		user = append(user, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(user, func(i, j int) bool { return user[i].Name < user[j].Name })
+	return append(out, user...), nil
+}
+
+// GetCollection returns the curated or user collection called name.
+func (q *QuranService) GetCollection(ctx context.Context, name string) (Collection, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if c, ok := curatedCollection(name); ok {
+		return c, nil
+	}
+	var c Collection
+	err := q.getValue(ctx, bucketCollections, name, &c)
+	if errors.Is(err, ErrKeyNotFound) {
+		return Collection{}, fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+	}
+	return c, err
+}
+
+// SaveCollection stores a user collection, replacing any of the same name.
+// Curated collections cannot be replaced.
+func (q *QuranService) SaveCollection(ctx context.Context, c Collection) error {
+	c.Curated = false
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if _, ok := curatedCollection(c.Name); ok {
+		return fmt.Errorf("%w: %s", ErrCuratedCollection, c.Name)
+	}
+	c.UpdatedAt = time.Now().UTC()
+	return q.putValue(ctx, bucketCollections, c.Name, c)
+}
+
+// DeleteCollection moves the user collection called name to the trash.
+func (q *QuranService) DeleteCollection(ctx context.Context, name string) error {
+	if _, ok := curatedCollection(name); ok {
+		return fmt.Errorf("%w: %s", ErrCuratedCollection, name)
+	}
+	err := q.softDelete(ctx, bucketCollections, name)
+	if errors.Is(err, ErrKeyNotFound) {
+		return fmt.Errorf("%w: %s", ErrCollectionNotFound, name)
+	}
+	return err
+}
+
+// CollectionVerses returns the verses of the collection called name, in
+// the order of its selections.
+func (q *QuranService) CollectionVerses(ctx context.Context, name string) ([]Verse, error) {
+	c, err := q.GetCollection(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	scopes, err := c.Scopes()
+	if err != nil {
+		return nil, err
+	}
+	var out []Verse
+	for _, s := range scopes {
+		verses, err := q.ScopeVerses(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, verses...)
+	}
+	return out, nil
+}
+
+// CreateCollection creates an empty user collection called name, titled
+// with its name until SaveCollection gives it another.
+func (q *QuranService) CreateCollection(ctx context.Context, name string) (Collection, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	_, err := q.GetCollection(ctx, name)
+	if err == nil {
+		return Collection{}, fmt.Errorf("%w: %s", ErrCollectionExists, name)
+	}
+	if !errors.Is(err, ErrCollectionNotFound) {
+		return Collection{}, err
+	}
+	c := Collection{Name: name, Title: name, Selections: []string{}}
+	if err := q.SaveCollection(ctx, c); err != nil {
+		return Collection{}, err
+	}
+	return q.GetCollection(ctx, name)
+}
+
+// userCollection returns the user collection called name for changing.
+func (q *QuranService) userCollection(ctx context.Context, name string) (Collection, error) {
+	c, err := q.GetCollection(ctx, name)
+	if err != nil {
+		return Collection{}, err
+	}
+	if c.Curated {
+		return Collection{}, fmt.Errorf("%w: %s", ErrCuratedCollection, c.Name)
+	}
+	return c, nil
+}
+
+// normalizeSelection returns sel, a chapter, verse key or range, as it is
+// stored: "2:255" for a single verse and "2:1-5" for a range.
+func normalizeSelection(sel string) (string, error) {
+	s, err := ParseScope(sel)
+	if err == nil && s.Kind != ScopeChapter {
+		err = fmt.Errorf("invalid selection %q: want a verse key or range", sel)
+	}
+	if err == nil {
+		err = s.Validate()
+	}
+	if err != nil {
+		return "", err
+	}
+	if s.FromVerse != 0 && s.FromVerse == s.ToVerse {
+		return verseKey(s.Number, s.FromVerse), nil
+	}
+	return s.String(), nil
+}
+
+// AddToCollection appends verse keys or ranges to the user collection
+// called name, skipping those already in it.
+func (q *QuranService) AddToCollection(ctx context.Context, name string, selections ...string) (Collection, error) {
+	c, err := q.userCollection(ctx, name)
+	if err != nil {
+		return Collection{}, err
+	}
+	for _, sel := range selections {
+		sel, err := normalizeSelection(sel)
+		if err != nil {
+			return Collection{}, err
+		}
+		if !slices.Contains(c.Selections, sel) {
+			c.Selections = append(c.Selections, sel)
+		}
+	}
+	return c, q.SaveCollection(ctx, c)
+}
+
+// RemoveFromCollection removes verse keys or ranges, as they were added,
+// from the user collection called name.
+func (q *QuranService) RemoveFromCollection(ctx context.Context, name string, selections ...string) (Collection, error) {
+	c, err := q.userCollection(ctx, name)
+	if err != nil {
+		return Collection{}, err
+	}
+	for _, sel := range selections {
+		sel, err := normalizeSelection(sel)
+		if err != nil {
+			return Collection{}, err
+		}
+		c.Selections = slices.DeleteFunc(c.Selections, func(s string) bool { return s == sel })
+	}
+	return c, q.SaveCollection(ctx, c)
+}
+
+// CollectionFormatJSON exports a collection as its definition, which
+// ImportCollection reads back, rather than as its verses.
+const CollectionFormatJSON = "json"
+
+// ExportCollection writes the collection called name to w in format: json
+// for the collection itself, to share with ImportCollection, or a
+// RenderFormat for its verses under its title, such as markdown for notes
+// or html for a handout.
+func (q *QuranService) ExportCollection(ctx context.Context, w io.Writer, name, format string) error {
+	c, err := q.GetCollection(ctx, name)
+	if err != nil {
+		return err
+	}
+	if format == CollectionFormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+	}
+	rf, err := ParseRenderFormat(format)
+	if err != nil {
+		return fmt.Errorf("unsupported collection format %q: want json, text, markdown or html", format)
+	}
+	verses, err := q.CollectionVerses(ctx, c.Name)
+	if err != nil {
+		return err
+	}
+
+	switch rf {
+	case RenderMarkdown:
+		fmt.Fprintf(w, "## %s\n\n", markdownEscape(c.Title))
+		if c.Description != "" {
+			fmt.Fprintf(w, "%s\n\n", markdownEscape(c.Description))
+		}
+	case RenderHTML:
+		fmt.Fprintf(w, "<h2>%s</h2>\n", html.EscapeString(c.Title))
+		if c.Description != "" {
+			fmt.Fprintf(w, "<p class=\"description\">%s</p>\n", html.EscapeString(c.Description))
+		}
+	default:
+		fmt.Fprintf(w, "%s\n", c.Title)
+		if c.Description != "" {
+			fmt.Fprintf(w, "%s\n", c.Description)
+		}
+		fmt.Fprintln(w)
+	}
+	return RenderVerses(w, verses, RenderOptions{Format: rf})
+}
+
+// ImportCollection stores the collection exported as json read from r as
+// a user collection, replacing any of the same name.
+func (q *QuranService) ImportCollection(ctx context.Context, r io.Reader) (Collection, error) {
+	var c Collection
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return Collection{}, fmt.Errorf("import collection: %w", err)
+	}
+	for i, sel := range c.Selections {
+		var err error
+		if c.Selections[i], err = normalizeSelection(sel); err != nil {
+			return Collection{}, fmt.Errorf("import collection %s: %w", c.Name, err)
+		}
+	}
+	if err := q.SaveCollection(ctx, c); err != nil {
+		return Collection{}, err
+	}
+	return q.GetCollection(ctx, c.Name)
+}
+
+const collectionsUsage = `usage: collections list | show <name>
+       collections create <name> | delete <name>
+       collections add <name> <key or range>... | rm <name> <key or range>...
+       collections export [-format json|text|markdown|html] [-o file] <name> | import <file>`
+
+func runCollections(ctx context.Context, q *QuranService, args []string) error {
+	printCollection := func(c Collection, err error) error {
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\t%s\n", c.Name, strings.Join(c.Selections, " "))
+		return nil
+	}
+
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		collections, err := q.ListCollections(ctx)
+		if err != nil {
+			return err
+		}
+		for _, c := range collections {
+			kind := "user"
+			if c.Curated {
+				kind = "curated"
+			}
+			fmt.Printf("%s\t%s\t%s\n", c.Name, kind, c.Title)
+		}
+		return nil
+	case len(args) == 2 && args[0] == "show":
+		verses, err := q.CollectionVerses(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		return RenderVerses(os.Stdout, verses, RenderOptions{Format: RenderText})
+	case len(args) == 2 && args[0] == "create":
+		return printCollection(q.CreateCollection(ctx, args[1]))
+	case len(args) == 2 && args[0] == "delete":
+		return q.DeleteCollection(ctx, args[1])
+	case len(args) > 2 && args[0] == "add":
+		return printCollection(q.AddToCollection(ctx, args[1], args[2:]...))
+	case len(args) > 2 && args[0] == "rm":
+		return printCollection(q.RemoveFromCollection(ctx, args[1], args[2:]...))
+	case len(args) == 2 && args[0] == "import":
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return printCollection(q.ImportCollection(ctx, f))
+	case len(args) > 1 && args[0] == "export":
+		fs := flag.NewFlagSet("collections export", flag.ContinueOnError)
+		format := fs.String("format", "text", "json, text, markdown or html")
+		out := fs.String("o", "", "output file (defaults to stdout)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New(collectionsUsage)
+		}
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		return q.ExportCollection(ctx, w, fs.Arg(0), *format)
+	default:
+		return errors.New(collectionsUsage)
+	}
+}
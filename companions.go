@@ -0,0 +1,100 @@
+package quranapi
+
+import (
+	"context"
+	"sync"
+)
+
+// Companion is content related to a verse by a CompanionProvider, such as
+// the occasion of its revelation or a hadith commenting on it.
+type Companion struct {
+	// Provider is the Name of the provider that attached it.
+	Provider string `json:"provider"`
+	// Kind is what the content is, such as "asbab_al_nuzul" or "hadith".
+	Kind   string `json:"kind"`
+	Title  string `json:"title,omitempty"`
+	Text   string `json:"text"`
+	Source string `json:"source,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// CompanionProvider attaches related content to verses. Companions are
+// merged into responses on request and never stored with the verses, so
+// the stored text stays as upstream has it.
+type CompanionProvider interface {
+	// Name identifies the provider in Companion.Provider and in logs.
+	Name() string
+	// Companions returns the content related to v, if any.
+	Companions(ctx context.Context, v Verse) ([]Companion, error)
+}
+
+// maxCompanionCalls bounds the provider calls made at once for a page of
+// verses.
+const maxCompanionCalls = 8
+
+type companionProviders struct {
+	mu        sync.RWMutex
+	providers []CompanionProvider
+}
+
+// RegisterCompanionProvider adds p to the providers asked for companions
+// of the verses returned WithCompanions. It may be called while the
+// service is in use.
+func (q *QuranService) RegisterCompanionProvider(p CompanionProvider) {
+	q.companions.mu.Lock()
+	defer q.companions.mu.Unlock()
+	q.companions.providers = append(q.companions.providers, p)
+}
+
+// WithCompanions fills Verse.Companions from the registered companion
+// providers. Providers that fail are logged and left out, so that a
+// verse is never withheld for its companions.
+func WithCompanions() ChapterOption {
+	return func(o *chapterOptions) {
+		o.companions = true
+	}
+}
+
+// attachCompanions returns a copy of verses with the companions of each
+// from every provider, in the order the providers were registered.
+func (q *QuranService) attachCompanions(ctx context.Context, verses []Verse) []Verse {
+	q.companions.mu.RLock()
+	providers := append([]CompanionProvider(nil), q.companions.providers...)
+	q.companions.mu.RUnlock()
+	verses = append([]Verse(nil), verses...)
+	if len(providers) == 0 {
+		return verses
+	}
+
+	found := make([][][]Companion, len(verses))
+	sem := make(chan struct{}, maxCompanionCalls)
+	var wg sync.WaitGroup
+	for i, v := range verses {
+		found[i] = make([][]Companion, len(providers))
+		for j, p := range providers {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				companions, err := p.Companions(ctx, v)
+				if err != nil {
+					q.log(ctx).Warn("companion provider", "provider", p.Name(), "verse", v.VerseKey, "err", err)
+					return
+				}
+				for k := range companions {
+					companions[k].Provider = p.Name()
+				}
+				found[i][j] = companions
+			}()
+		}
+	}
+	wg.Wait()
+
+	for i := range verses {
+		verses[i].Companions = nil
+		for _, companions := range found[i] {
+			verses[i].Companions = append(verses[i].Companions, companions...)
+		}
+	}
+	return verses
+}
@@ -0,0 +1,129 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// TranslationComparison is a verse with several of its translations side
+// by side.
+type TranslationComparison struct {
+	VerseKey     VerseKey              `json:"verse_key"`
+	Text         string                `json:"text"`
+	Translations []ComparedTranslation `json:"translations"`
+}
+
+// ComparedTranslation is a translation made ready for comparing: Text is
+// plain, without footnote markers or markup, and Tokens are its words in
+// lower case without punctuation, for diffing word by word.
+type ComparedTranslation struct {
+	ResourceID   int      `json:"resource_id"`
+	ResourceName string   `json:"resource_name"`
+	LanguageName string   `json:"language_name,omitempty"`
+	Text         string   `json:"text"`
+	Tokens       []string `json:"tokens"`
+}
+
+// comparisonTokens splits text into lower-cased words, keeping
+// apostrophes within words.
+func comparisonTokens(text string) []string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.IsMark(r) && r != '\'' && r != '’'
+	})
+	tokens := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = strings.Trim(w, "'’"); w != "" {
+			tokens = append(tokens, strings.ToLower(w))
+		}
+	}
+	return tokens
+}
+
+// CompareTranslations returns the verse named by key with the translations
+// resourceIDs, in that order, or with those it is stored with when none
+// are given. Translations the verse is stored without are fetched for its
+// whole chapter and stored, as SyncTranslations does.
+func (q *QuranService) CompareTranslations(ctx context.Context, key string, resourceIDs ...int) (TranslationComparison, error) {
+	v, err := q.GetVerse(ctx, key)
+	if err != nil {
+		return TranslationComparison{}, err
+	}
+	if len(resourceIDs) == 0 {
+		for _, tr := range v.Translations {
+			resourceIDs = append(resourceIDs, tr.ResourceID)
+		}
+	}
+	stored := func(id int) bool {
+		return slices.ContainsFunc(v.Translations, func(tr Translation) bool { return tr.ResourceID == id })
+	}
+	var missing []int
+	for _, id := range resourceIDs {
+		if !stored(id) && !slices.Contains(missing, id) {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		chapter, _, _ := v.VerseKey.Split()
+		if _, err := q.syncChapterTranslations(ctx, chapter, missing); err != nil {
+			return TranslationComparison{}, fmt.Errorf("fetch translations %v: %w", missing, err)
+		}
+		if v, err = q.GetVerse(ctx, key); err != nil {
+			return TranslationComparison{}, err
+		}
+	}
+
+	out := TranslationComparison{VerseKey: v.VerseKey, Text: v.TextMadani}
+	for _, id := range resourceIDs {
+		i := slices.IndexFunc(v.Translations, func(tr Translation) bool { return tr.ResourceID == id })
+		if i < 0 {
+			return TranslationComparison{}, fmt.Errorf("%w: %s translation %d", ErrTranslationNotFound, v.VerseKey, id)
+		}
+		tr := v.Translations[i]
+		text := strings.Join(strings.Fields(stripTags(tr.Text)), " ")
+		out.Translations = append(out.Translations, ComparedTranslation{
+			ResourceID:   tr.ResourceID,
+			ResourceName: tr.ResourceName,
+			LanguageName: tr.LanguageName,
+			Text:         text,
+			Tokens:       comparisonTokens(text),
+		})
+	}
+	return out, nil
+}
+
+const compareUsage = "usage: compare <verse key> [-t ids]"
+
+func runCompare(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	ids := fs.String("t", "", "comma separated translation resource ids (default: those stored)")
+	rest, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return errors.New(compareUsage)
+	}
+	resourceIDs, err := parseInts(*ids)
+	if err != nil {
+		return err
+	}
+
+	c, err := q.CompareTranslations(ctx, rest[0], resourceIDs...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n%s\n", c.VerseKey, c.Text)
+	for _, tr := range c.Translations {
+		name := tr.ResourceName
+		if name == "" {
+			name = fmt.Sprintf("translation %d", tr.ResourceID)
+		}
+		fmt.Printf("\n[%d] %s\n%s\n", tr.ResourceID, name, tr.Text)
+	}
+	return nil
+}
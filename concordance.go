@@ -0,0 +1,243 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// concordanceWindow is the number of words kept on each side of an
+// occurrence in a concordance line.
+const concordanceWindow = 5
+
+// termCount is how often a term occurs in a chapter and in how many of its
+// verses.
+type termCount struct {
+	Occurrences int
+	Verses      int
+}
+
+// chapterCounts maps the translation resource ID of each text of a
+// chapter, zero for the Arabic, to the counts of its terms.
+type chapterCounts map[int]map[string]termCount
+
+func buildCounts(chapter Chapter) chapterCounts {
+	counts := make(chapterCounts)
+	add := func(source int, text string) {
+		terms := counts[source]
+		if terms == nil {
+			terms = make(map[string]termCount)
+			counts[source] = terms
+		}
+		seen := make(map[string]bool)
+		for _, term := range tokenize(text) {
+			c := terms[term]
+			c.Occurrences++
+			if !seen[term] {
+				seen[term] = true
+				c.Verses++
+			}
+			terms[term] = c
+		}
+	}
+	for _, v := range chapter.Verses {
+		add(0, v.TextMadani)
+		for _, tr := range v.Translations {
+			add(tr.ResourceID, stripTags(tr.Text))
+		}
+	}
+	return counts
+}
+
+// ConcordanceLine is an occurrence of a word with the words around it, in
+// the Arabic text or, when Translation is set, in a translation.
+type ConcordanceLine struct {
+	VerseKey    VerseKey `json:"verse_key"`
+	Translation int      `json:"translation,omitempty"`
+	// Position is the 1-based index of the word in the text.
+	Position int `json:"position"`
+	// Word is the occurrence as written, with its diacritics.
+	Word   string `json:"word"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Concordance returns every occurrence of token in the stored Arabic text
+// and translations, in mushaf order, with up to five words of context on
+// each side. token is matched as search matches words, so Arabic matches
+// without diacritics. The verses are found through the search index.
+func (q *QuranService) Concordance(ctx context.Context, token string) ([]ConcordanceLine, error) {
+	terms := tokenize(token)
+	if len(terms) != 1 {
+		return nil, fmt.Errorf("concordance needs a single word, not %q", token)
+	}
+	term := terms[0]
+	if err := q.loadSearchIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	q.search.mu.RLock()
+	verses := make(map[int][]int)
+	for id, postings := range q.search.chapters {
+		if vs := postings[term]; len(vs) > 0 {
+			verses[id] = slices.Clone(vs)
+		}
+	}
+	q.search.mu.RUnlock()
+	ids := make([]int, 0, len(verses))
+	for id := range verses {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	lines := []ConcordanceLine{}
+	for _, id := range ids {
+		chapter, err := q.GetChapter(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range verses[id] {
+			if n > len(chapter.Verses) {
+				continue // the index is ahead of or behind the stored chapter
+			}
+			v := chapter.Verses[n-1]
+			lines = appendConcordance(lines, v.VerseKey, 0, v.TextMadani, term)
+			for _, tr := range v.Translations {
+				lines = appendConcordance(lines, v.VerseKey, tr.ResourceID, stripTags(tr.Text), term)
+			}
+		}
+	}
+	return lines, nil
+}
+
+// appendConcordance appends a line for each occurrence of term in text.
+func appendConcordance(lines []ConcordanceLine, key VerseKey, translation int, text, term string) []ConcordanceLine {
+	spans := tokenSpans(text)
+	for i, s := range spans {
+		if s.term != term {
+			continue
+		}
+		from, to := max(i-concordanceWindow, 0), min(i+concordanceWindow, len(spans)-1)
+		lines = append(lines, ConcordanceLine{
+			VerseKey:    key,
+			Translation: translation,
+			Position:    i + 1,
+			Word:        text[s.start:s.end],
+			Before:      strings.TrimSpace(text[spans[from].start:s.start]),
+			After:       strings.TrimSpace(text[s.end:spans[to].end]),
+		})
+	}
+	return lines
+}
+
+// FrequencyOptions selects the text TokenFrequencies counts.
+type FrequencyOptions struct {
+	// Translation counts the words of this translation resource rather
+	// than the Arabic text.
+	Translation int
+	// Chapters limits the count to these chapters; nil counts every
+	// stored chapter.
+	Chapters []int
+	// MinCount leaves out words occurring fewer times.
+	MinCount int
+	// Limit caps the words returned; zero returns all of them.
+	Limit int
+}
+
+// TokenFrequency is how often a word occurs and in how many verses.
+type TokenFrequency struct {
+	Token  string `json:"token"`
+	Count  int    `json:"count"`
+	Verses int    `json:"verses"`
+}
+
+// TokenFrequencies returns the words of the stored text, normalized as
+// search normalizes them, most frequent first. The counts are kept in the
+// search index as chapters are stored, so they are not recounted.
+func (q *QuranService) TokenFrequencies(ctx context.Context, opts FrequencyOptions) ([]TokenFrequency, error) {
+	if err := q.loadSearchIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]termCount)
+	q.search.mu.RLock()
+	for id, counts := range q.search.counts {
+		if opts.Chapters != nil && !slices.Contains(opts.Chapters, id) {
+			continue
+		}
+		for term, c := range counts[opts.Translation] {
+			t := totals[term]
+			t.Occurrences += c.Occurrences
+			t.Verses += c.Verses
+			totals[term] = t
+		}
+	}
+	q.search.mu.RUnlock()
+
+	out := make([]TokenFrequency, 0, len(totals))
+	for term, c := range totals {
+		if c.Occurrences >= opts.MinCount {
+			out = append(out, TokenFrequency{Token: term, Count: c.Occurrences, Verses: c.Verses})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Token < out[j].Token
+	})
+	if opts.Limit > 0 && len(out) > opts.Limit {
+		out = out[:opts.Limit]
+	}
+	return out, nil
+}
+
+func runConcordance(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: concordance <word>")
+	}
+	lines, err := q.Concordance(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	for _, l := range lines {
+		source := "ar"
+		if l.Translation != 0 {
+			source = strconv.Itoa(l.Translation)
+		}
+		fmt.Printf("%s\t%s\t%s [%s] %s\n", l.VerseKey, source, l.Before, l.Word, l.After)
+	}
+	return nil
+}
+
+func runFrequencies(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("frequencies", flag.ContinueOnError)
+	translation := fs.Int("t", 0, "count this translation resource rather than the Arabic")
+	limit := fs.Int("n", 50, "number of words to print, 0 for all")
+	minCount := fs.Int("min", 0, "leave out words occurring fewer times")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts := FrequencyOptions{Translation: *translation, Limit: *limit, MinCount: *minCount}
+	for _, arg := range fs.Args() {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid chapter %q: %w", arg, err)
+		}
+		opts.Chapters = append(opts.Chapters, id)
+	}
+
+	freqs, err := q.TokenFrequencies(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for _, f := range freqs {
+		fmt.Printf("%s\t%d\t%d\n", f.Token, f.Count, f.Verses)
+	}
+	return nil
+}
@@ -0,0 +1,421 @@
+package quranapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultBaseURL = "http://staging.quran.com:3000/api/v3"
+
+type Config struct {
+	Store   StoreConfig `yaml:"store" toml:"store"`
+	BaseURL string      `yaml:"base_url" toml:"base_url"`
+	// APIVersion is the version of the API at BaseURL, 3 by default.
+	APIVersion   int          `yaml:"api_version" toml:"api_version"`
+	Translations []int        `yaml:"translations" toml:"translations"`
+	Recitation   int          `yaml:"recitation" toml:"recitation"`
+	Media        int          `yaml:"media" toml:"media"`
+	Concurrency  int          `yaml:"concurrency" toml:"concurrency"`
+	Server       ServerConfig `yaml:"server" toml:"server"`
+	LogLevel     string       `yaml:"log_level" toml:"log_level"`
+	Backup       BackupConfig `yaml:"backup" toml:"backup"`
+	RetryQueue   RetryConfig  `yaml:"retry_queue" toml:"retry_queue"`
+	// BareNumber is how a bare number in a scope argument is read.
+	BareNumber BareNumberPolicy `yaml:"bare_number" toml:"bare_number"`
+	// Transliteration adds a Latin transliteration to every verse.
+	Transliteration bool `yaml:"transliteration" toml:"transliteration"`
+	// DisabledFeatures switches off optional subsystems.
+	DisabledFeatures []Feature `yaml:"disabled_features" toml:"disabled_features"`
+	// Webhooks receive events as data is synced and invalidated.
+	Webhooks []Webhook `yaml:"webhooks" toml:"webhooks"`
+	// AutoRefresh revalidates stored chapters in the background while the
+	// server runs, on a ParseSchedule spec such as "24h" or "0 3 * * *".
+	AutoRefresh string `yaml:"auto_refresh" toml:"auto_refresh"`
+	// StorageProfile is full or lite; see ProfileLite.
+	StorageProfile StorageProfile `yaml:"storage_profile" toml:"storage_profile"`
+	// Transport configures the client syncing from upstream.
+	Transport TransportConfig `yaml:"transport" toml:"transport"`
+	// Search selects and configures the search backend.
+	Search SearchConfig `yaml:"search" toml:"search"`
+	// Elasticsearch, when its URL is set, is kept in sync with the store
+	// for the es search backend.
+	Elasticsearch ElasticsearchConfig `yaml:"elasticsearch" toml:"elasticsearch"`
+	// AudioDir is where the audio download command saves verse audio for
+	// offline playlists.
+	AudioDir string `yaml:"audio_dir" toml:"audio_dir"`
+	// TTS speaks translations that have no recorded audio.
+	TTS TTSConfig `yaml:"tts" toml:"tts"`
+	// AsbabNuzulURL is where the occasions of revelation dataset is
+	// downloaded from when none was imported.
+	AsbabNuzulURL string `yaml:"asbab_nuzul_url" toml:"asbab_nuzul_url"`
+	// HijriAdjustment is the days the local Hijri calendar runs ahead of
+	// the tabular one, negative when behind, for suggested readings.
+	HijriAdjustment int `yaml:"hijri_adjustment" toml:"hijri_adjustment"`
+	// Sync is the instance the sync command reconciles user data with.
+	Sync SyncConfig `yaml:"sync" toml:"sync"`
+}
+
+// TTSConfig selects a text-to-speech engine: Google Cloud when
+// GoogleAPIKey is set, otherwise the local Command if any.
+type TTSConfig struct {
+	// Command runs a local engine such as
+	// ["espeak-ng", "--stdin", "--stdout", "-v", "{voice}"]; see CommandTTS.
+	Command []string `yaml:"command" toml:"command"`
+	// Format is the extension of the audio Command writes, wav by default.
+	Format       string `yaml:"format" toml:"format"`
+	GoogleAPIKey string `yaml:"google_api_key" toml:"google_api_key"`
+	// Voices maps upstream language names to voices of the engine.
+	Voices map[string]string `yaml:"voices" toml:"voices"`
+}
+
+// SyncConfig is the peer the sync command syncs with by default: the URL
+// of an instance serving ServerConfig.Sync, and the key to authenticate
+// with it.
+type SyncConfig struct {
+	Peer   string `yaml:"peer" toml:"peer"`
+	APIKey string `yaml:"api_key" toml:"api_key"`
+}
+
+type ServerConfig struct {
+	Port int        `yaml:"port" toml:"port"`
+	Auth AuthConfig `yaml:"auth" toml:"auth"`
+	CORS CORSConfig `yaml:"cors" toml:"cors"`
+	// AdminKey enables the /admin/ endpoints for requests carrying it.
+	AdminKey string `yaml:"admin_key" toml:"admin_key"`
+	// Sync serves POST /sync, reconciling the user data of other devices
+	// running the sync command with this server's.
+	Sync bool `yaml:"sync" toml:"sync"`
+}
+
+type SearchConfig struct {
+	// Backend defaults to builtin.
+	Backend SearchBackendKind `yaml:"backend" toml:"backend"`
+	// BlevePath is the directory of the bleve index. It defaults to the
+	// store path with a .bleve suffix.
+	BlevePath string `yaml:"bleve_path" toml:"bleve_path"`
+}
+
+// RetryConfig configures the queue of failed chapter store writes. An empty
+// Path keeps the queue in memory only.
+type RetryConfig struct {
+	Size int    `yaml:"size" toml:"size"`
+	Path string `yaml:"path" toml:"path"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Store: StoreConfig{
+			Backend: BackendBBolt,
+			Path:    "quran.db",
+		},
+		BaseURL:     defaultBaseURL,
+		Concurrency: 4,
+		Server:      ServerConfig{Port: 8080},
+		LogLevel:    "info",
+		Backup: BackupConfig{
+			Dir:      "backups",
+			Interval: 24 * time.Hour,
+			Keep:     7,
+		},
+		RetryQueue: RetryConfig{
+			Size: defaultRetryQueueSize,
+			Path: "quran.db.retry",
+		},
+		BareNumber: BareNumberChapter,
+		AudioDir:   "audio",
+	}
+}
+
+// LoadConfig reads the YAML (.yaml, .yml) or TOML (.toml) file at path over
+// the defaults, then applies QURANAPI_* environment overrides. An empty path
+// skips the file.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+
+		switch ext := strings.ToLower(filepath.Ext(path)); ext {
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(b, &cfg)
+		case ".toml":
+			err = toml.Unmarshal(b, &cfg)
+		default:
+			err = fmt.Errorf("unsupported config format %q", ext)
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("config %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+	return cfg, cfg.Validate()
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	str := func(name string, dst *string) {
+		if v, ok := lookup(name); ok {
+			*dst = v
+		}
+	}
+	num := func(name string, dst *int) error {
+		v, ok := lookup(name)
+		if !ok {
+			return nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		*dst = n
+		return nil
+	}
+
+	if v, ok := lookup("QURANAPI_STORE"); ok {
+		c.Store.Backend = StoreBackend(v)
+	}
+	str("QURANAPI_DB_PATH", &c.Store.Path)
+	if v, ok := lookup("QURANAPI_STORE_READ_ONLY"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("QURANAPI_STORE_READ_ONLY: %w", err)
+		}
+		c.Store.ReadOnly = b
+	}
+	if v, ok := lookup("QURANAPI_STORE_TTL"); ok {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("QURANAPI_STORE_TTL: %w", err)
+		}
+		c.Store.TTL = ttl
+	}
+	str("QURANAPI_BASE_URL", &c.BaseURL)
+	if err := num("QURANAPI_API_VERSION", &c.APIVersion); err != nil {
+		return err
+	}
+	if v, ok := lookup("QURANAPI_TRANSLATIONS"); ok {
+		ids, err := parseInts(v)
+		if err != nil {
+			return fmt.Errorf("QURANAPI_TRANSLATIONS: %w", err)
+		}
+		c.Translations = ids
+	}
+	if err := num("QURANAPI_RECITATION", &c.Recitation); err != nil {
+		return err
+	}
+	if err := num("QURANAPI_MEDIA", &c.Media); err != nil {
+		return err
+	}
+	if err := num("QURANAPI_CONCURRENCY", &c.Concurrency); err != nil {
+		return err
+	}
+	if err := num("QURANAPI_PORT", &c.Server.Port); err != nil {
+		return err
+	}
+	str("QURANAPI_LOG_LEVEL", &c.LogLevel)
+	str("QURANAPI_AUTO_REFRESH", &c.AutoRefresh)
+	if v, ok := lookup("QURANAPI_API_KEYS"); ok {
+		c.Server.Auth.APIKeys = nil
+		for _, key := range strings.Split(v, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				c.Server.Auth.APIKeys = append(c.Server.Auth.APIKeys, key)
+			}
+		}
+	}
+	str("QURANAPI_JWT_SECRET", &c.Server.Auth.JWTSecret)
+	str("QURANAPI_ADMIN_KEY", &c.Server.AdminKey)
+	if err := num("QURANAPI_RATE_LIMIT", &c.Server.Auth.RateLimit); err != nil {
+		return err
+	}
+	if v, ok := lookup("QURANAPI_PER_USER_DATA"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("QURANAPI_PER_USER_DATA: %w", err)
+		}
+		c.Server.Auth.PerUserData = b
+	}
+	if v, ok := lookup("QURANAPI_CORS_ORIGINS"); ok {
+		c.Server.CORS.AllowedOrigins = nil
+		for _, origin := range strings.Split(v, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				c.Server.CORS.AllowedOrigins = append(c.Server.CORS.AllowedOrigins, origin)
+			}
+		}
+	}
+	if v, ok := lookup("QURANAPI_TRANSLITERATION"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("QURANAPI_TRANSLITERATION: %w", err)
+		}
+		c.Transliteration = b
+	}
+	if v, ok := lookup("QURANAPI_DISABLED_FEATURES"); ok {
+		features, err := ParseFeatures(v)
+		if err != nil {
+			return fmt.Errorf("QURANAPI_DISABLED_FEATURES: %w", err)
+		}
+		c.DisabledFeatures = features
+	}
+	if v, ok := lookup("QURANAPI_BARE_NUMBER"); ok {
+		c.BareNumber = BareNumberPolicy(v)
+	}
+	str("QURANAPI_PROXY", &c.Transport.Proxy)
+	str("QURANAPI_USER_AGENT", &c.Transport.UserAgent)
+	str("QURANAPI_CA_FILE", &c.Transport.CAFile)
+	if v, ok := lookup("QURANAPI_STORAGE_PROFILE"); ok {
+		c.StorageProfile = StorageProfile(v)
+	}
+	if v, ok := lookup("QURANAPI_SEARCH_BACKEND"); ok {
+		c.Search.Backend = SearchBackendKind(v)
+	}
+	str("QURANAPI_ES_URL", &c.Elasticsearch.URL)
+	str("QURANAPI_ES_INDEX", &c.Elasticsearch.Index)
+	str("QURANAPI_ES_API_KEY", &c.Elasticsearch.APIKey)
+	str("QURANAPI_AUDIO_DIR", &c.AudioDir)
+	str("QURANAPI_TTS_GOOGLE_API_KEY", &c.TTS.GoogleAPIKey)
+	str("QURANAPI_ASBAB_NUZUL_URL", &c.AsbabNuzulURL)
+	if err := num("QURANAPI_HIJRI_ADJUSTMENT", &c.HijriAdjustment); err != nil {
+		return err
+	}
+	str("QURANAPI_SYNC_PEER", &c.Sync.Peer)
+	str("QURANAPI_SYNC_API_KEY", &c.Sync.APIKey)
+	if v, ok := lookup("QURANAPI_SYNC"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("QURANAPI_SYNC: %w", err)
+		}
+		c.Server.Sync = b
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	if c.Concurrency < 1 {
+		return fmt.Errorf("config: concurrency must be at least 1, got %d", c.Concurrency)
+	}
+	if c.APIVersion != 0 && c.APIVersion != int(APIv3) && c.APIVersion != int(APIv4) {
+		return fmt.Errorf("config: unsupported api version %d", c.APIVersion)
+	}
+	if c.Server.Port < 0 || c.Server.Port > 65535 {
+		return fmt.Errorf("config: invalid server port %d", c.Server.Port)
+	}
+	if c.Server.Auth.RateLimit < 0 {
+		return fmt.Errorf("config: invalid rate limit %d", c.Server.Auth.RateLimit)
+	}
+	switch strings.ToLower(c.LogLevel) {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("config: invalid log level %q", c.LogLevel)
+	}
+	if err := c.BareNumber.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if err := c.StorageProfile.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if c.Transport.Proxy != "" {
+		if _, err := parseProxyURL(c.Transport.Proxy); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+	for _, f := range c.DisabledFeatures {
+		if err := f.Validate(); err != nil {
+			return fmt.Errorf("config: %w", err)
+		}
+	}
+	if c.AutoRefresh != "" {
+		if _, err := ParseSchedule(c.AutoRefresh); err != nil {
+			return fmt.Errorf("config: auto_refresh: %w", err)
+		}
+	}
+	if err := c.Search.Backend.Validate(); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if c.Search.Backend == BackendElasticsearch && c.Elasticsearch.URL == "" {
+		return errors.New("config: search backend es needs an elasticsearch url")
+	}
+	if c.Elasticsearch.URL != "" {
+		if u, err := url.Parse(c.Elasticsearch.URL); err != nil || u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("config: invalid elasticsearch url %q", c.Elasticsearch.URL)
+		}
+	}
+	for _, w := range c.Webhooks {
+		if u, err := url.Parse(w.URL); err != nil || u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("config: invalid webhook url %q", w.URL)
+		}
+		for _, t := range w.Events {
+			if err := t.Validate(); err != nil {
+				return fmt.Errorf("config: webhook %s: %w", w.URL, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ServiceOptions returns the options configuring a QuranService from c. The
+// store is opened separately with OpenStore(c.Store).
+func (c Config) ServiceOptions() []Option {
+	opts := []Option{
+		WithBaseURL(c.BaseURL),
+		WithTranslations(c.Translations...),
+		WithRecitation(c.Recitation),
+		WithMedia(c.Media),
+		WithCacheWriteRetry(c.RetryQueue.Size, c.RetryQueue.Path),
+		WithBareNumberPolicy(c.BareNumber),
+		WithDisabledFeatures(c.DisabledFeatures...),
+		WithStorageProfile(c.StorageProfile),
+		WithAudioDir(c.AudioDir),
+		WithAsbabNuzulURL(c.AsbabNuzulURL),
+		WithHijriAdjustment(c.HijriAdjustment),
+	}
+	if c.APIVersion != 0 {
+		opts = append(opts, WithAPIVersion(APIVersion(c.APIVersion)))
+	}
+	if c.Transliteration {
+		opts = append(opts, WithTransliteration())
+	}
+	if c.Store.ReadOnly {
+		opts = append(opts, WithReadOnly())
+	}
+	opts = append(opts, c.Transport.options()...)
+	for _, w := range c.Webhooks {
+		opts = append(opts, WithWebhook(w))
+	}
+	if c.Elasticsearch.URL != "" {
+		opts = append(opts, WithElasticsearch(c.Elasticsearch))
+	}
+	if c.Search.Backend != "" {
+		opts = append(opts, WithSearchBackend(c.Search.Backend))
+	}
+	if c.Search.Backend == BackendBleve {
+		path := c.Search.BlevePath
+		if path == "" && c.Store.Path != "" && c.Store.Backend != BackendRedis {
+			path = c.Store.Path + ".bleve"
+		}
+		opts = append(opts, WithBleveIndex(path))
+	}
+	switch {
+	case c.TTS.GoogleAPIKey != "":
+		opts = append(opts, WithTTS(GoogleTTS{APIKey: c.TTS.GoogleAPIKey, Voices: c.TTS.Voices}))
+	case len(c.TTS.Command) > 0:
+		opts = append(opts, WithTTS(CommandTTS{Command: c.TTS.Command, Ext: c.TTS.Format, Voices: c.TTS.Voices}))
+	}
+	if s, err := ParseSchedule(c.AutoRefresh); c.AutoRefresh != "" && err == nil {
+		opts = append(opts, WithRefreshSchedule(s))
+	}
+	return opts
+}
@@ -0,0 +1,191 @@
+package quranapi
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CorpusFormat is a parallel corpus file format.
+type CorpusFormat string
+
+const (
+	// CorpusTSV writes a header and a verse_key, Arabic, translation row
+	// per verse.
+	CorpusTSV CorpusFormat = "tsv"
+	// CorpusTMX writes a TMX 1.4 translation memory with a translation unit
+	// per verse.
+	CorpusTMX CorpusFormat = "tmx"
+)
+
+// corpusLanguages are the ISO 639-1 codes of upstream language names, for
+// the xml:lang of TMX and the TSV header.
+var corpusLanguages = map[string]string{
+	"bengali":    "bn",
+	"bosnian":    "bs",
+	"chinese":    "zh",
+	"dutch":      "nl",
+	"english":    "en",
+	"french":     "fr",
+	"german":     "de",
+	"hindi":      "hi",
+	"indonesian": "id",
+	"italian":    "it",
+	"japanese":   "ja",
+	"korean":     "ko",
+	"malay":      "ms",
+	"persian":    "fa",
+	"portuguese": "pt",
+	"russian":    "ru",
+	"spanish":    "es",
+	"swahili":    "sw",
+	"swedish":    "sv",
+	"tamil":      "ta",
+	"turkish":    "tr",
+	"urdu":       "ur",
+}
+
+// CorpusOptions configures WriteParallelCorpus.
+type CorpusOptions struct {
+	Format CorpusFormat
+	// ResourceID is the translation paired with the Arabic. It may be left
+	// zero when the chapters are stored with a single translation.
+	ResourceID int
+	// Script is the Arabic edition, Uthmani unless set. Only editions stored
+	// with every verse (uthmani, indopak and simple) can be used.
+	Script Script
+	// NormalizeArabic drops diacritics, annotation signs and tatweel and
+	// folds alef and ya variants, as search does.
+	NormalizeArabic bool
+	// LowercaseTranslation lower-cases the translation.
+	LowercaseTranslation bool
+	// Language is the code of the translation's language, such as "en".
+	// It is looked up from the translation's language name when empty.
+	Language string
+}
+
+// corpusPair is an aligned verse and translation.
+type corpusPair struct {
+	key                VerseKey
+	arabic, translated string
+}
+
+// WriteParallelCorpus writes the verses of chapters paired with one of
+// their translations, a verse to a segment, as a parallel corpus for
+// machine translation and NLP work. Footnote markers and markup are
+// removed from the translation, and whitespace within a segment is
+// collapsed. Verses without the translation are left out. It returns the
+// number of pairs written.
+func WriteParallelCorpus(w io.Writer, chapters []Chapter, opts CorpusOptions) (int, error) {
+	if opts.Script == "" {
+		opts.Script = ScriptUthmani
+	}
+	if err := opts.Script.Validate(); err != nil {
+		return 0, err
+	}
+	if opts.Script.fetched() || opts.Script == ScriptUthmaniTajweed {
+		return 0, fmt.Errorf("corpus: script %s is not stored with every verse", opts.Script)
+	}
+	if opts.ResourceID == 0 {
+		ids := make(map[int]bool)
+		for _, c := range chapters {
+			for _, v := range c.Verses {
+				for _, tr := range v.Translations {
+					ids[tr.ResourceID] = true
+				}
+			}
+		}
+		if len(ids) != 1 {
+			return 0, fmt.Errorf("corpus: the chapters have %d translations; choose one", len(ids))
+		}
+		for id := range ids {
+			opts.ResourceID = id
+		}
+	}
+
+	var pairs []corpusPair
+	name, language := "", ""
+	for _, c := range chapters {
+		for _, v := range c.Verses {
+			var tr *Translation
+			for i := range v.Translations {
+				if v.Translations[i].ResourceID == opts.ResourceID {
+					tr = &v.Translations[i]
+				}
+			}
+			if tr == nil {
+				continue
+			}
+			if name == "" {
+				name, language = tr.ResourceName, tr.LanguageName
+			}
+			arabic := v.Text(opts.Script)
+			if opts.NormalizeArabic {
+				arabic = normalizeArabic(arabic)
+			}
+			translated := stripTags(tr.Text)
+			if opts.LowercaseTranslation {
+				translated = strings.ToLower(translated)
+			}
+			pairs = append(pairs, corpusPair{
+				key:        v.VerseKey,
+				arabic:     strings.Join(strings.Fields(arabic), " "),
+				translated: strings.Join(strings.Fields(translated), " "),
+			})
+		}
+	}
+	if len(pairs) == 0 {
+		return 0, fmt.Errorf("corpus: no verses stored with translation %d", opts.ResourceID)
+	}
+	if opts.Language == "" {
+		opts.Language = corpusLanguages[strings.ToLower(language)]
+	}
+	if opts.Language == "" {
+		return 0, fmt.Errorf("corpus: no language code for %q; set one", language)
+	}
+
+	bw := bufio.NewWriter(w)
+	switch opts.Format {
+	case CorpusTSV, "":
+		fmt.Fprintf(bw, "verse_key\tar\t%s\n", opts.Language)
+		for _, p := range pairs {
+			fmt.Fprintf(bw, "%s\t%s\t%s\n", p.key, p.arabic, p.translated)
+		}
+	case CorpusTMX:
+		writeTMX(bw, pairs, opts, name)
+	default:
+		return 0, fmt.Errorf("unsupported corpus format: %q", opts.Format)
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	return len(pairs), nil
+}
+
+// writeTMX writes pairs as a TMX 1.4 document, with the verse key as the
+// ID of each translation unit.
+func writeTMX(w io.Writer, pairs []corpusPair, opts CorpusOptions, name string) {
+	esc := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	io.WriteString(w, xml.Header)
+	io.WriteString(w, `<tmx version="1.4">`+"\n")
+	fmt.Fprintf(w, `<header creationtool="quranapi" creationtoolversion="1" segtype="sentence" o-tmf="quranapi" adminlang="en" srclang="ar" datatype="plaintext" creationdate="%s">`+"\n",
+		time.Now().UTC().Format("20060102T150405Z"))
+	fmt.Fprintf(w, `<prop type="x-translation">%s</prop>`+"\n", esc(name))
+	fmt.Fprintf(w, `<prop type="x-resource-id">%d</prop>`+"\n", opts.ResourceID)
+	fmt.Fprintf(w, `<prop type="x-script">%s</prop>`+"\n", opts.Script)
+	io.WriteString(w, "</header>\n<body>\n")
+	for _, p := range pairs {
+		fmt.Fprintf(w, `<tu tuid="%s">`+"\n", p.key)
+		fmt.Fprintf(w, `<tuv xml:lang="ar"><seg>%s</seg></tuv>`+"\n", esc(p.arabic))
+		fmt.Fprintf(w, `<tuv xml:lang="%s"><seg>%s</seg></tuv>`+"\n", esc(opts.Language), esc(p.translated))
+		io.WriteString(w, "</tu>\n")
+	}
+	io.WriteString(w, "</body>\n</tmx>\n")
+}
@@ -0,0 +1,184 @@
+package quranapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+//go:generate go run ./cmd/quranapi -store memory -translations 131 dataset -o dataset/quran.json.gz
+
+// Dataset is the offline snapshot of the text: every chapter with its
+// verses in the Uthmani script and a single translation.
+type Dataset struct {
+	Translation int              `json:"translation"`
+	Summaries   []ChapterSummary `json:"summaries"`
+	Chapters    []Chapter        `json:"chapters"`
+	// Checksums maps chapter IDs to Chapter.Checksum, recorded when the
+	// dataset was built.
+	Checksums map[int]string `json:"checksums"`
+}
+
+// ErrOffline is returned for any upstream request made by an offline
+// service.
+var ErrOffline = errors.New("upstream access disabled in offline mode")
+
+type offlineDoer struct{}
+
+func (offlineDoer) Do(*http.Request) (*http.Response, error) {
+	return nil, ErrOffline
+}
+
+// NewOfflineService returns a service seeded from the dataset embedded in
+// the binary. It never touches the network, and works without an existing
+// quran.db; pass WithStore to persist the seeded data somewhere else than
+// memory.
+func NewOfflineService(opts ...Option) (*QuranService, error) {
+	if len(embeddedDataset) == 0 {
+		return nil, errors.New("no embedded dataset: run go generate and build with -tags quranembed")
+	}
+
+	ds, err := ReadDataset(bytes.NewReader(embeddedDataset))
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := NewQuranService(offlineDoer{}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.SeedDataset(context.Background(), ds); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// SeedDataset writes every chapter in ds to the store, refusing chapters
+// that don't match the dataset checksums.
+func (q *QuranService) SeedDataset(ctx context.Context, ds *Dataset) error {
+	for _, chapter := range ds.Chapters {
+		if want, got := ds.Checksums[chapter.ID], chapter.Checksum(); want != got {
+			return fmt.Errorf("dataset chapter %d: checksum mismatch: want %s, got %s", chapter.ID, want, got)
+		}
+	}
+
+	if err := q.setSummaryDB(ctx, ds.Summaries); err != nil {
+		return err
+	}
+	for _, chapter := range ds.Chapters {
+		if err := q.setChapterDB(ctx, chapter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BuildDataset fetches every chapter, trimmed to the text and
+// translations, for embedding with WriteDataset.
+func (q *QuranService) BuildDataset(ctx context.Context, translation int) (*Dataset, error) {
+	summaries, err := q.ChaptersSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := &Dataset{
+		Translation: translation,
+		Summaries:   summaries,
+		Checksums:   make(map[int]string, len(summaries)),
+	}
+	for _, summary := range summaries {
+		chapter, err := q.GetChapter(ctx, summary.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		verses := make([]Verse, len(chapter.Verses))
+		for i, v := range chapter.Verses {
+			verses[i] = Verse{
+				ID:           v.ID,
+				VerseNumber:  v.VerseNumber,
+				ChapterID:    v.ChapterID,
+				VerseKey:     v.VerseKey,
+				TextMadani:   v.TextMadani,
+				JuzNumber:    v.JuzNumber,
+				HizbNumber:   v.HizbNumber,
+				RubNumber:    v.RubNumber,
+				Sajdah:       v.Sajdah,
+				SajdahNumber: v.SajdahNumber,
+				PageNumber:   v.PageNumber,
+				Translations: v.Translations,
+			}
+		}
+		chapter.Verses = verses
+		ds.Chapters = append(ds.Chapters, chapter)
+		ds.Checksums[chapter.ID] = chapter.Checksum()
+	}
+	return ds, nil
+}
+
+func runDataset(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("dataset", flag.ContinueOnError)
+	out := fs.String("o", "dataset/quran.json.gz", "output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(q.translations) != 1 {
+		return errors.New("the dataset holds exactly one translation: set -translations")
+	}
+
+	snap, err := q.exportSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	ds, err := snap.BuildDataset(ctx, q.translations[0])
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := WriteDataset(f, ds); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func ReadDataset(r io.Reader) (*Dataset, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var ds Dataset
+	if err := json.NewDecoder(zr).Decode(&ds); err != nil {
+		return nil, err
+	}
+	return &ds, nil
+}
+
+func WriteDataset(w io.Writer, ds *Dataset) error {
+	zw, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(zw).Encode(ds); err != nil {
+		return err
+	}
+	return zw.Close()
+}
@@ -0,0 +1,15 @@
+//go:build quranembed
+
+package quranapi
+
+import "embed"
+
+// The dataset is generated into dataset/ with `go generate` (see
+// dataset.go) against a reachable upstream before building with -tags
+// quranembed. Without it the build still succeeds, and NewOfflineService
+// reports the dataset missing.
+//
+//go:embed dataset
+var datasetFS embed.FS
+
+var embeddedDataset, _ = datasetFS.ReadFile("dataset/quran.json.gz")
@@ -0,0 +1,5 @@
+//go:build !quranembed
+
+package quranapi
+
+var embeddedDataset []byte
@@ -0,0 +1,207 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DiffOp is the kind of a DiffSegment.
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffDelete DiffOp = "delete"
+	DiffInsert DiffOp = "insert"
+)
+
+// DiffSegment is a run of characters both texts share, or that only the
+// first (DiffDelete) or the second (DiffInsert) has.
+type DiffSegment struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// EditionDiff is the character-level difference between a verse in two
+// editions of the text.
+type EditionDiff struct {
+	VerseKey VerseKey      `json:"verse_key"`
+	EditionA Script        `json:"edition_a"`
+	EditionB Script        `json:"edition_b"`
+	A        string        `json:"a"`
+	B        string        `json:"b"`
+	Segments []DiffSegment `json:"segments"`
+	// Distance is the number of characters deleted and inserted.
+	Distance int `json:"distance"`
+}
+
+// DiffEditions compares the verse with the given key in editions a and b,
+// such as ScriptUthmani and ScriptIndopak, character by character. Every
+// rune counts as a character, so a changed vowel mark shows up on its own
+// rather than as a changed letter. The markup of the tajweed edition is
+// ignored.
+func (q *QuranService) DiffEditions(ctx context.Context, key string, a, b Script) (EditionDiff, error) {
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return EditionDiff{}, err
+	}
+	c, err := q.GetChapter(ctx, chapter, WithScript(a, b))
+	if err != nil {
+		return EditionDiff{}, err
+	}
+	if verse > len(c.Verses) {
+		return EditionDiff{}, fmt.Errorf("%w: %s", ErrVerseNotFound, verseKey(chapter, verse))
+	}
+	return diffVerse(c.Verses[verse-1], a, b), nil
+}
+
+// DiffChapterEditions compares every verse of a chapter in editions a and
+// b, as DiffEditions does.
+func (q *QuranService) DiffChapterEditions(ctx context.Context, chapter int, a, b Script) ([]EditionDiff, error) {
+	c, err := q.GetChapter(ctx, chapter, WithScript(a, b))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]EditionDiff, 0, len(c.Verses))
+	for _, v := range c.Verses {
+		out = append(out, diffVerse(v, a, b))
+	}
+	return out, nil
+}
+
+func diffVerse(v Verse, a, b Script) EditionDiff {
+	text := func(s Script) string {
+		if s == ScriptUthmaniTajweed {
+			return stripTags(v.Text(s))
+		}
+		return v.Text(s)
+	}
+	d := EditionDiff{VerseKey: v.VerseKey, EditionA: a, EditionB: b, A: text(a), B: text(b)}
+	d.Segments = diffRunes([]rune(d.A), []rune(d.B))
+	for _, s := range d.Segments {
+		if s.Op != DiffEqual {
+			d.Distance += len([]rune(s.Text))
+		}
+	}
+	return d
+}
+
+// diffRunes returns the segments turning a into b, from their longest
+// common subsequence.
+func diffRunes(a, b []rune) []DiffSegment {
+	var segments []DiffSegment
+	emit := func(op DiffOp, r ...rune) {
+		if len(r) == 0 {
+			return
+		}
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += string(r)
+			return
+		}
+		segments = append(segments, DiffSegment{Op: op, Text: string(r)})
+	}
+
+	// the editions differ in a few places, so only the middle of the
+	// texts is left for the quadratic table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	emit(DiffEqual, a[:prefix]...)
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] is the length of the common subsequence of ma[i:] and mb[j:]
+	lcs := make([][]int32, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(ma) && j < len(mb) {
+		switch {
+		case ma[i] == mb[j]:
+			emit(DiffEqual, ma[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			emit(DiffDelete, ma[i])
+			i++
+		default:
+			emit(DiffInsert, mb[j])
+			j++
+		}
+	}
+	emit(DiffDelete, ma[i:]...)
+	emit(DiffInsert, mb[j:]...)
+
+	emit(DiffEqual, a[len(a)-suffix:]...)
+	return segments
+}
+
+// formatDiff renders segments inline, with deletions as [-text-] and
+// insertions as {+text+}.
+func formatDiff(segments []DiffSegment) string {
+	var b strings.Builder
+	for _, s := range segments {
+		switch s.Op {
+		case DiffDelete:
+			b.WriteString("[-" + s.Text + "-]")
+		case DiffInsert:
+			b.WriteString("{+" + s.Text + "+}")
+		default:
+			b.WriteString(s.Text)
+		}
+	}
+	return b.String()
+}
+
+const diffEditionsUsage = "usage: diff-editions [-a script] [-b script] [-changed] <verse key | chapter>"
+
+func runDiffEditions(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("diff-editions", flag.ContinueOnError)
+	a := fs.String("a", string(ScriptUthmani), "first edition")
+	b := fs.String("b", string(ScriptIndopak), "second edition")
+	changed := fs.Bool("changed", false, "print only verses that differ")
+	rest, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return errors.New(diffEditionsUsage)
+	}
+
+	var diffs []EditionDiff
+	if chapter, err := strconv.Atoi(rest[0]); err == nil {
+		if diffs, err = q.DiffChapterEditions(ctx, chapter, Script(*a), Script(*b)); err != nil {
+			return err
+		}
+	} else {
+		d, err := q.DiffEditions(ctx, rest[0], Script(*a), Script(*b))
+		if err != nil {
+			return err
+		}
+		diffs = append(diffs, d)
+	}
+	for _, d := range diffs {
+		if *changed && d.Distance == 0 {
+			continue
+		}
+		fmt.Printf("%s\t%d\t%s\n", d.VerseKey, d.Distance, formatDiff(d.Segments))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package quranapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrChapterNotFound is returned for chapters that don't exist, such as
+	// chapter 115.
+	ErrChapterNotFound = errors.New("chapter not found")
+	// ErrVerseNotFound is returned for verse keys past the end of their
+	// chapter.
+	ErrVerseNotFound = errors.New("verse not found")
+	// ErrPageNotFound is returned for pages outside the mushaf.
+	ErrPageNotFound = errors.New("page not found")
+	// ErrUpstreamUnavailable matches network failures, timeouts and 5xx or
+	// 429 responses from the upstream API.
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
+	// ErrCacheMiss is returned when the store has no copy of the requested
+	// data.
+	ErrCacheMiss = errors.New("cache miss")
+)
+
+// UpstreamError describes a failed request to the upstream API. StatusCode
+// is zero when no response was received.
+type UpstreamError struct {
+	Path       string
+	StatusCode int
+	Err        error
+}
+
+func (e *UpstreamError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("upstream %s: status %d", e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf("upstream %s: %v", e.Path, e.Err)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+func (e *UpstreamError) Is(target error) bool {
+	if target != ErrUpstreamUnavailable {
+		return false
+	}
+	return e.StatusCode == 0 ||
+		e.StatusCode >= http.StatusInternalServerError ||
+		e.StatusCode == http.StatusTooManyRequests
+}
+
+func isNotFound(err error) bool {
+	var ue *UpstreamError
+	return errors.As(err, &ue) && ue.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,218 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// EventType names a change to the stored data.
+type EventType string
+
+const (
+	// EventChapterSynced is emitted when a chapter fetched upstream has
+	// been stored.
+	EventChapterSynced EventType = "chapter_synced"
+	// EventSyncCompleted is emitted when a sync of every chapter ends.
+	EventSyncCompleted EventType = "sync_completed"
+	// EventCacheInvalidated is emitted when a chapter is dropped from the
+	// caches and store, to be refetched on its next read.
+	EventCacheInvalidated EventType = "cache_invalidated"
+)
+
+func (t EventType) Validate() error {
+	switch t {
+	case EventChapterSynced, EventSyncCompleted, EventCacheInvalidated:
+		return nil
+	}
+	return fmt.Errorf("unknown event type %q", string(t))
+}
+
+// Event is a change to the stored data that downstream systems, such as
+// search indexers or CDNs, may want to react to.
+type Event struct {
+	Type    EventType `json:"type"`
+	Time    time.Time `json:"time"`
+	Chapter int       `json:"chapter,omitempty"`
+	// Synced and Failed count the chapters of a completed sync.
+	Synced int `json:"synced,omitempty"`
+	Failed int `json:"failed,omitempty"`
+}
+
+type subscription struct {
+	ch    chan Event
+	types map[EventType]bool
+}
+
+type eventBus struct {
+	mu   sync.Mutex
+	subs map[*subscription]struct{}
+
+	// webhooks are the subscriptions of WithWebhook, ended by Close once
+	// their deliveries are done
+	webhooks   []func()
+	deliveries sync.WaitGroup
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subs: make(map[*subscription]struct{})}
+}
+
+// Subscribe returns a channel receiving events of the given types, or of
+// every type when none are given, and a function ending the subscription
+// and closing the channel. Events are emitted on the request path and never
+// wait for a subscriber: those arriving while the channel's buffer is full
+// are dropped.
+func (q *QuranService) Subscribe(buffer int, types ...EventType) (<-chan Event, func()) {
+	sub := &subscription{ch: make(chan Event, buffer)}
+	if len(types) > 0 {
+		sub.types = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+
+	b := q.events
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, sub)
+			close(sub.ch)
+			b.mu.Unlock()
+		})
+	}
+}
+
+func (q *QuranService) emit(ctx context.Context, e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	b := q.events
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs {
+		if sub.types != nil && !sub.types[e.Type] {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			q.log(ctx).Warn("event dropped", "type", e.Type, "chapter", e.Chapter)
+		}
+	}
+}
+
+// Webhook posts events as JSON to URL. With a Secret, each request carries
+// its HMAC-SHA256 as X-Quranapi-Signature: sha256=<hex> so the receiver can
+// check where it came from.
+type Webhook struct {
+	URL    string      `yaml:"url" toml:"url"`
+	Secret string      `yaml:"secret" toml:"secret"`
+	Events []EventType `yaml:"events" toml:"events"`
+}
+
+const (
+	// webhookBuffer holds the events of a full sync, which is emitted
+	// faster than deliveries go out
+	webhookBuffer   = 2 * ChapterCount
+	webhookAttempts = 3
+)
+
+// WithWebhook delivers events to a webhook in the background, retrying
+// failed deliveries with backoff. Deliveries use the service's Doer, and
+// Close waits for those of events already emitted.
+func WithWebhook(w Webhook) Option {
+	return func(q *QuranService) {
+		q.webhooks = append(q.webhooks, w)
+	}
+}
+
+func (q *QuranService) startWebhooks(doer Doer) {
+	b := q.events
+	for _, w := range q.webhooks {
+		events, unsubscribe := q.Subscribe(webhookBuffer, w.Events...)
+		b.mu.Lock()
+		b.webhooks = append(b.webhooks, unsubscribe)
+		b.mu.Unlock()
+		b.deliveries.Add(1)
+		go func(w Webhook) {
+			defer b.deliveries.Done()
+			for e := range events {
+				ctx := context.Background()
+				if err := deliverWebhook(ctx, doer, w, e); err != nil {
+					q.log(ctx).Warn("webhook delivery failed", "url", w.URL, "type", e.Type, "err", err)
+				}
+			}
+		}(w)
+	}
+}
+
+// stopWebhooks ends the webhook subscriptions and waits for the events
+// they hold to be delivered.
+func (q *QuranService) stopWebhooks() {
+	b := q.events
+	b.mu.Lock()
+	webhooks := b.webhooks
+	b.webhooks = nil
+	b.mu.Unlock()
+	for _, unsubscribe := range webhooks {
+		unsubscribe()
+	}
+	b.deliveries.Wait()
+}
+
+func deliverWebhook(ctx context.Context, doer Doer, w Webhook, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		err = postWebhook(ctx, doer, w, body)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func postWebhook(ctx context.Context, doer Doer, w Webhook, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Quranapi-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := doer.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
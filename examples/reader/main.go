@@ -0,0 +1,47 @@
+// Command reader prints a chapter, or a range of its verses, with the first
+// requested translation.
+//
+//	go run ./examples/reader 1
+//	go run ./examples/reader 2:255-257
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/alilmtech/quranapi"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: reader <chapter | chapter:from-to>")
+	}
+	scope, err := quranapi.ParseScope(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	q, err := quranapi.NewQuranService(&http.Client{Timeout: 10 * time.Second},
+		quranapi.WithTranslations(131),
+		quranapi.WithMemCache(16),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	verses, err := q.ScopeVerses(context.Background(), scope)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, v := range verses {
+		fmt.Printf("%s\n%s\n", v.VerseKey, v.TextMadani)
+		if len(v.Translations) > 0 {
+			fmt.Println(v.Translations[0].Text)
+		}
+		fmt.Println()
+	}
+}
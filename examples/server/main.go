@@ -0,0 +1,32 @@
+// Command server embeds the quranapi HTTP API in a program's own mux,
+// under /quran/.
+//
+//	go run ./examples/server
+//	curl localhost:8080/quran/pages/1
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/alilmtech/quranapi"
+)
+
+func main() {
+	q, err := quranapi.NewQuranService(&http.Client{Timeout: 10 * time.Second},
+		quranapi.WithMemCache(256),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/quran/", http.StripPrefix("/quran", quranapi.NewServer(q)))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("try /quran/pages/1\n"))
+	})
+
+	srv := &http.Server{Addr: ":8080", Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	log.Fatal(srv.ListenAndServe())
+}
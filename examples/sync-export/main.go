@@ -0,0 +1,61 @@
+// Command sync-export caches every chapter in a bbolt file and writes them
+// as a zip of static HTML pages.
+//
+//	go run ./examples/sync-export -db quran.db -o quran.zip
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/alilmtech/quranapi"
+)
+
+func main() {
+	db := flag.String("db", "quran.db", "bbolt database file")
+	out := flag.String("o", "quran.zip", "output zip")
+	flag.Parse()
+
+	store, err := quranapi.OpenStore(quranapi.StoreConfig{Backend: quranapi.BackendBBolt, Path: *db})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	q, err := quranapi.NewQuranService(&http.Client{Timeout: 10 * time.Second},
+		quranapi.WithStore(store),
+		quranapi.WithTranslations(131),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	summaries, err := q.ChaptersSummary(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	chapters := make([]quranapi.Chapter, 0, len(summaries))
+	for _, s := range summaries {
+		chapter, err := q.GetChapter(ctx, s.ID)
+		if err != nil {
+			log.Fatal(err)
+		}
+		chapters = append(chapters, chapter)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := quranapi.WriteHTMLZip(f, chapters, quranapi.HTMLZipOptions{}); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
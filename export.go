@@ -0,0 +1,161 @@
+package quranapi
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func runExport(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "docx", "export format: docx, html-zip, markdown-zip, jsonl, braille, brf, tsv or tmx")
+	out := fs.String("o", "", "output file (defaults to stdout)")
+	font := fs.String("font", "", "font file to embed in html-zip exports")
+	marks := fs.Bool("bookmarks", false, "mark bookmarked verses in html-zip exports")
+	baseURL := fs.String("base-url", "", "URL html-zip exports will be hosted at, for canonical links")
+	split := fs.String("split", "chapter", "split markdown-zip exports into a file per chapter or juz")
+	translit := fs.Bool("translit", false, "include the transliteration in markdown-zip exports")
+	translation := fs.Int("translation", 0, "translation resource id paired with the Arabic in tsv and tmx exports")
+	script := fs.String("script", string(ScriptUthmani), "Arabic script of tsv and tmx exports: uthmani, indopak or simple")
+	normalize := fs.Bool("normalize", false, "normalize the Arabic of tsv and tmx exports as search does")
+	lower := fs.Bool("lower", false, "lower-case the translation in tsv and tmx exports")
+	lang := fs.String("lang", "", "language code of the translation in tsv and tmx exports")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	snap, err := q.exportSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	chapters, err := snap.exportChapters(ctx, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch *format {
+	case "docx":
+		return WriteDOCX(w, chapters, DOCXOptions{})
+	case "html-zip":
+		opts := HTMLZipOptions{FontName: *font, BaseURL: *baseURL}
+		if *font != "" {
+			if opts.Font, err = os.ReadFile(*font); err != nil {
+				return err
+			}
+		}
+		if *marks {
+			if opts.Bookmarks, err = snap.ListBookmarks(ctx, BookmarkFilter{}); err != nil {
+				return err
+			}
+		}
+		return WriteHTMLZip(w, chapters, opts)
+	case "markdown-zip":
+		return WriteMarkdownZip(w, chapters, MarkdownOptions{Split: MarkdownSplit(*split), Transliteration: *translit})
+	case "jsonl":
+		_, err := WriteJSONL(w, chapters)
+		return err
+	case "braille":
+		return WriteBraille(w, chapters, BrailleOptions{Format: BrailleUnicode})
+	case "brf":
+		return WriteBraille(w, chapters, BrailleOptions{Format: BrailleBRF})
+	case "tsv", "tmx":
+		_, err := WriteParallelCorpus(w, chapters, CorpusOptions{
+			Format:               CorpusFormat(*format),
+			ResourceID:           *translation,
+			Script:               Script(*script),
+			NormalizeArabic:      *normalize,
+			LowercaseTranslation: *lower,
+			Language:             *lang,
+		})
+		return err
+	default:
+		return fmt.Errorf("unsupported export format: %q", *format)
+	}
+}
+
+// exportChapters loads the chapters named by args, or every chapter when
+// args is empty.
+func (q *QuranService) exportChapters(ctx context.Context, args []string) ([]Chapter, error) {
+	var ids []int
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chapter %q: %w", arg, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		summaries, err := q.ChaptersSummary(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range summaries {
+			ids = append(ids, s.ID)
+		}
+	}
+
+	chapters := make([]Chapter, 0, len(ids))
+	for _, id := range ids {
+		chapter, err := q.GetChapter(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		chapters = append(chapters, chapter)
+	}
+	return chapters, nil
+}
+
+const basmalah = "بِسْمِ ٱللَّهِ ٱلرَّحْمَٰنِ ٱلرَّحِيمِ"
+
+// hasBasmalahHeader reports whether the basmalah is displayed above the
+// chapter rather than being part of its first verse (Al-Fatihah).
+func (c Chapter) hasBasmalahHeader() bool {
+	return c.BismallahPre && c.Number != 1
+}
+
+var (
+	footnoteRE = regexp.MustCompile(`(?s)<sup[^>]*>.*?</sup>`)
+	tagRE      = regexp.MustCompile(`<[^>]*>`)
+)
+
+// stripTags removes footnote markers and any remaining markup from the
+// translation text returned upstream.
+func stripTags(s string) string {
+	s = footnoteRE.ReplaceAllString(s, "")
+	s = tagRE.ReplaceAllString(s, "")
+	return strings.TrimSpace(s)
+}
+
+// arabicDigits formats n using Arabic-Indic digits.
+func arabicDigits(n int) string {
+	var b strings.Builder
+	for _, r := range strconv.Itoa(n) {
+		if r >= '0' && r <= '9' {
+			r = '٠' + (r - '0')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// verseEndMarker returns the end of ayah sign with the verse number.
+func verseEndMarker(n int) string {
+	return "۝" + arabicDigits(n)
+}
@@ -0,0 +1,220 @@
+package quranapi
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Experimental: the mapping below follows the standard Arabic Braille
+// letter and vowel tables. Quranic annotation signs (small high letters,
+// waqf marks, madda) have no agreed cells and are dropped.
+
+type BrailleFormat int
+
+const (
+	// BrailleUnicode writes Unicode Braille patterns (U+2800 block).
+	BrailleUnicode BrailleFormat = iota
+	// BrailleBRF writes North American ASCII Braille as used by embossers
+	// and refreshable displays.
+	BrailleBRF
+)
+
+type BrailleOptions struct {
+	Format BrailleFormat
+	// LineWidth and PageLength default to the 40x25 BRF page.
+	LineWidth  int
+	PageLength int
+}
+
+var arabicBrailleDots = map[rune]string{
+	'ا': "1", 'ٱ': "1", 'ٰ': "1",
+	'ب': "12", 'ت': "2345", 'ث': "1456", 'ج': "245", 'ح': "156", 'خ': "1346",
+	'د': "145", 'ذ': "2346", 'ر': "1235", 'ز': "1356", 'س': "234", 'ش': "146",
+	'ص': "12346", 'ض': "1246", 'ط': "23456", 'ظ': "123456", 'ع': "12356", 'غ': "126",
+	'ف': "124", 'ق': "12345", 'ك': "13", 'ل': "123", 'م': "134", 'ن': "1345",
+	'ه': "125", 'و': "2456", 'ي': "24", 'ة': "16", 'ى': "135",
+	'ء': "3", 'ٔ': "3", 'أ': "34", 'إ': "46", 'آ': "345", 'ؤ': "1256", 'ئ': "13456",
+
+	'َ': "2",   // fatha
+	'ُ': "136", // damma
+	'ِ': "15",  // kasra
+	'ْ': "25",  // sukun
+	'ۡ': "25",  // Quranic sukun
+	'ّ': "6",   // shadda
+	'ً': "23",  // fathatan
+	'ٌ': "26",  // dammatan
+	'ٍ': "35",  // kasratan
+
+	'،': "5", '؛': "56", '؟': "236", '.': "256",
+}
+
+const (
+	brailleLamAlef     = "1236"
+	brailleNumberSign  = "3456"
+	brailleDigitLetter = "245 1 12 14 145 15 124 1245 125 24" // 0-9 as a-j
+)
+
+var brailleDigits = strings.Fields(brailleDigitLetter)
+
+// brailleCell converts a dot list such as "1245" to its Unicode pattern.
+func brailleCell(dots string) rune {
+	r := rune(0x2800)
+	for _, d := range dots {
+		r |= 1 << (d - '1')
+	}
+	return r
+}
+
+// BrailleText transliterates Arabic text to Unicode Braille.
+func BrailleText(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	inNumber := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if d, ok := digitValue(r); ok {
+			if !inNumber {
+				b.WriteRune(brailleCell(brailleNumberSign))
+				inNumber = true
+			}
+			b.WriteRune(brailleCell(brailleDigits[d]))
+			continue
+		}
+		inNumber = false
+
+		if r == ' ' || r == '\n' {
+			b.WriteRune(r)
+			continue
+		}
+
+		// lam followed by an alef form is written as the lam-alef contraction
+		if r == 'ل' && i+1 < len(runes) && (runes[i+1] == 'ا' || runes[i+1] == 'ٱ') {
+			b.WriteRune(brailleCell(brailleLamAlef))
+			i++
+			continue
+		}
+
+		if dots, ok := arabicBrailleDots[r]; ok {
+			b.WriteRune(brailleCell(dots))
+		}
+	}
+	return b.String()
+}
+
+func digitValue(r rune) (int, bool) {
+	switch {
+	case r >= '0' && r <= '9':
+		return int(r - '0'), true
+	case r >= '٠' && r <= '٩':
+		return int(r - '٠'), true
+	case r >= '۰' && r <= '۹':
+		return int(r - '۰'), true
+	}
+	return 0, false
+}
+
+// brfChars maps the 64 six-dot patterns, indexed by their dot bits, to North
+// American ASCII Braille.
+const brfChars = " A1B'K2L@CIF/MSP\"E3H9O6R^DJG>NTQ,*5<-U8V.%[$+X!&;:4\\0Z7(_?W]#Y)="
+
+// BrailleToBRF converts Unicode Braille to ASCII Braille. Runes outside the
+// six-dot Braille range are passed through.
+func BrailleToBRF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 0x2800 && r < 0x2840 {
+			b.WriteByte(brfChars[r-0x2800])
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// WriteBraille writes the chapters in Braille, wrapped to the page
+// dimensions in opts.
+func WriteBraille(w io.Writer, chapters []Chapter, opts BrailleOptions) error {
+	if opts.LineWidth <= 0 {
+		opts.LineWidth = 40
+	}
+	if opts.PageLength <= 0 {
+		opts.PageLength = 25
+	}
+
+	bw := bufio.NewWriter(w)
+	lines := 0
+	writeLine := func(line string) {
+		if opts.Format == BrailleBRF {
+			line = BrailleToBRF(line)
+			if lines > 0 && lines%opts.PageLength == 0 {
+				bw.WriteString("\f")
+			}
+		}
+		bw.WriteString(line)
+		bw.WriteString("\n")
+		lines++
+	}
+
+	for _, chapter := range chapters {
+		for _, line := range wrapCells(BrailleText(strconv.Itoa(chapter.Number)+" "+chapter.NameArabic), opts.LineWidth) {
+			writeLine(line)
+		}
+		if chapter.hasBasmalahHeader() {
+			for _, line := range wrapCells(BrailleText(basmalah), opts.LineWidth) {
+				writeLine(line)
+			}
+		}
+		for _, verse := range chapter.Verses {
+			text := BrailleText(verse.TextMadani + " " + strconv.Itoa(verse.VerseNumber))
+			for _, line := range wrapCells(text, opts.LineWidth) {
+				writeLine(line)
+			}
+		}
+		writeLine("")
+	}
+
+	return bw.Flush()
+}
+
+// wrapCells wraps s at spaces so that no line exceeds width cells. Words
+// longer than a line are split.
+func wrapCells(s string, width int) []string {
+	var (
+		lines []string
+		line  strings.Builder
+		n     int
+	)
+	flush := func() {
+		lines = append(lines, line.String())
+		line.Reset()
+		n = 0
+	}
+
+	for _, word := range strings.Fields(s) {
+		wn := utf8.RuneCountInString(word)
+		if n > 0 && n+1+wn > width {
+			flush()
+		}
+		for wn > width {
+			runes := []rune(word)
+			line.WriteString(string(runes[:width]))
+			flush()
+			word = string(runes[width:])
+			wn -= width
+		}
+		if n > 0 {
+			line.WriteByte(' ')
+			n++
+		}
+		line.WriteString(word)
+		n += wn
+	}
+	if n > 0 {
+		flush()
+	}
+	return lines
+}
@@ -0,0 +1,162 @@
+package quranapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type DOCXOptions struct {
+	ArabicFont          string
+	ArabicFontSize      int // in points
+	TranslationFont     string
+	TranslationFontSize int // in points
+	SkipTranslations    bool
+}
+
+func (o DOCXOptions) withDefaults() DOCXOptions {
+	if o.ArabicFont == "" {
+		o.ArabicFont = "KFGQPC Uthmanic Script HAFS"
+	}
+	if o.ArabicFontSize == 0 {
+		o.ArabicFontSize = 22
+	}
+	if o.TranslationFont == "" {
+		o.TranslationFont = "Calibri"
+	}
+	if o.TranslationFontSize == 0 {
+		o.TranslationFontSize = 11
+	}
+	return o
+}
+
+// WriteDOCX writes the chapters as a Word document. Arabic paragraphs are
+// laid out right-to-left, each followed by its translations.
+func WriteDOCX(w io.Writer, chapters []Chapter, opts DOCXOptions) error {
+	opts = opts.withDefaults()
+
+	parts := []struct {
+		name string
+		body []byte
+	}{
+		{name: "[Content_Types].xml", body: []byte(docxContentTypes)},
+		{name: "_rels/.rels", body: []byte(docxRels)},
+		{name: "word/_rels/document.xml.rels", body: []byte(docxDocumentRels)},
+		{name: "word/styles.xml", body: docxStyles(opts)},
+		{name: "word/document.xml", body: docxDocument(chapters, opts)},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(p.body); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func docxDocument(chapters []Chapter, opts DOCXOptions) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+
+	for i, chapter := range chapters {
+		if i > 0 {
+			buf.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+		}
+
+		title := fmt.Sprintf("%d. %s (%s)", chapter.Number,
+			chapter.Cite(0, 0).Format(CitationFormat{Style: CitationLong}), chapter.TranslatedName.Name)
+		docxParagraph(&buf, "Heading1", false, title)
+		docxParagraph(&buf, "ChapterName", true, chapter.NameArabic)
+		if chapter.hasBasmalahHeader() {
+			docxParagraph(&buf, "Basmalah", true, basmalah)
+		}
+
+		for _, verse := range chapter.Verses {
+			docxParagraph(&buf, "Arabic", true, verse.TextMadani+" "+verseEndMarker(verse.VerseNumber))
+			if opts.SkipTranslations {
+				continue
+			}
+			for _, tr := range verse.Translations {
+				text := fmt.Sprintf("%s (%s)", stripTags(tr.Text), chapter.Cite(verse.VerseNumber, 0))
+				docxParagraph(&buf, "Translation", false, text)
+			}
+		}
+	}
+
+	buf.WriteString(`<w:sectPr/></w:body></w:document>`)
+	return buf.Bytes()
+}
+
+func docxParagraph(buf *bytes.Buffer, style string, rtl bool, text string) {
+	buf.WriteString(`<w:p><w:pPr><w:pStyle w:val="` + style + `"/>`)
+	if rtl {
+		buf.WriteString(`<w:bidi/>`)
+	}
+	buf.WriteString(`</w:pPr><w:r>`)
+	if rtl {
+		buf.WriteString(`<w:rPr><w:rtl/></w:rPr>`)
+	}
+	buf.WriteString(`<w:t xml:space="preserve">`)
+	xml.EscapeText(buf, []byte(text))
+	buf.WriteString(`</w:t></w:r></w:p>`)
+}
+
+func docxStyles(opts DOCXOptions) []byte {
+	arabicFont := xmlAttr(opts.ArabicFont)
+	trFont := xmlAttr(opts.TranslationFont)
+	arabicSize := strconv.Itoa(opts.ArabicFontSize * 2) // half-points
+	trSize := strconv.Itoa(opts.TranslationFontSize * 2)
+
+	rtlStyle := func(id, name, size, jc string) string {
+		return `<w:style w:type="paragraph" w:styleId="` + id + `"><w:name w:val="` + name + `"/>` +
+			`<w:pPr><w:bidi/><w:jc w:val="` + jc + `"/><w:spacing w:before="120" w:after="60"/></w:pPr>` +
+			`<w:rPr><w:rFonts w:ascii="` + arabicFont + `" w:hAnsi="` + arabicFont + `" w:cs="` + arabicFont + `"/>` +
+			`<w:rtl/><w:sz w:val="` + size + `"/><w:szCs w:val="` + size + `"/><w:lang w:bidi="ar-SA"/></w:rPr></w:style>`
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">`)
+	buf.WriteString(`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/>` +
+		`<w:rPr><w:rFonts w:ascii="` + trFont + `" w:hAnsi="` + trFont + `"/><w:sz w:val="` + trSize + `"/></w:rPr></w:style>`)
+	buf.WriteString(`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/>` +
+		`<w:pPr><w:keepNext/><w:jc w:val="center"/><w:spacing w:before="240" w:after="120"/></w:pPr>` +
+		`<w:rPr><w:b/><w:sz w:val="32"/></w:rPr></w:style>`)
+	buf.WriteString(rtlStyle("ChapterName", "Chapter Name", arabicSize, "center"))
+	buf.WriteString(rtlStyle("Basmalah", "Basmalah", arabicSize, "center"))
+	buf.WriteString(rtlStyle("Arabic", "Arabic", arabicSize, "both"))
+	buf.WriteString(`<w:style w:type="paragraph" w:styleId="Translation"><w:name w:val="Translation"/><w:basedOn w:val="Normal"/>` +
+		`<w:pPr><w:spacing w:after="120"/></w:pPr><w:rPr><w:color w:val="404040"/></w:rPr></w:style>`)
+	buf.WriteString(`</w:styles>`)
+	return buf.Bytes()
+}
+
+func xmlAttr(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+const docxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`</Types>`
+
+const docxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`</Relationships>`
+
+const docxDocumentRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
@@ -0,0 +1,229 @@
+package quranapi
+
+import (
+	"archive/zip"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"strings"
+)
+
+type HTMLZipOptions struct {
+	// Font, when set, is embedded base64 encoded in each page's style and used
+	// for the Arabic text. FontName is the file name it was read from and is
+	// used to infer its format.
+	Font     []byte
+	FontName string
+	// Bookmarks are marked on their verses in the color of their label.
+	Bookmarks []Bookmark
+	// BaseURL, when set, is where the site will be hosted; chapter pages
+	// link their permalink under it as canonical.
+	BaseURL string
+}
+
+// WriteHTMLZip writes a zip of static HTML pages, an index and one page per
+// chapter. Each page carries its own style, so it has no external references
+// and displays the same opened on its own as straight after unzipping.
+func WriteHTMLZip(w io.Writer, chapters []Chapter, opts HTMLZipOptions) error {
+	zw := zip.NewWriter(w)
+	style := template.CSS(htmlStylesheet(opts))
+
+	f, err := zw.Create("index.html")
+	if err != nil {
+		return err
+	}
+	if err := htmlIndexTmpl.Execute(f, htmlIndex{Chapters: chapters, Style: style}); err != nil {
+		return err
+	}
+
+	bookmarks := make(map[VerseKey]Bookmark, len(opts.Bookmarks))
+	for _, b := range opts.Bookmarks {
+		bookmarks[b.VerseKey] = b
+	}
+
+	for _, chapter := range chapters {
+		f, err := zw.Create(htmlChapterFile(chapter.Number))
+		if err != nil {
+			return err
+		}
+		data := htmlChapter{Chapter: chapter, Bookmarks: bookmarks, Style: style}
+		if opts.BaseURL != "" {
+			data.Canonical = ChapterScope(chapter.Number).Permalink(opts.BaseURL)
+		}
+		if err := htmlChapterTmpl.Execute(f, data); err != nil {
+			return err
+		}
+		if err := writeSlugRedirects(zw, chapter); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+type htmlIndex struct {
+	Chapters []Chapter
+	Style    template.CSS
+}
+
+type htmlChapter struct {
+	Chapter
+	Bookmarks map[VerseKey]Bookmark
+	Canonical string
+	Style     template.CSS
+}
+
+// Bookmark returns the bookmark on the verse, if any.
+func (c htmlChapter) Bookmark(verseKey VerseKey) *Bookmark {
+	if b, ok := c.Bookmarks[verseKey]; ok {
+		return &b
+	}
+	return nil
+}
+
+// writeSlugRedirects adds pages at the chapter and verse permalink slugs,
+// "2/index.html" and "2/255/index.html", redirecting to the chapter page so
+// that links to a server also resolve on the static site. Ranges, juz and
+// pages have no static equivalent.
+func writeSlugRedirects(zw *zip.Writer, chapter Chapter) error {
+	slug := ChapterScope(chapter.Number).Slug()[1:]
+	if err := writeRedirect(zw, slug, "../"+htmlChapterFile(chapter.Number)); err != nil {
+		return err
+	}
+	for _, v := range chapter.Verses {
+		slug := VerseRangeScope(chapter.Number, v.VerseNumber, v.VerseNumber).Slug()[1:]
+		target := fmt.Sprintf("../../%s#%d", htmlChapterFile(chapter.Number), v.VerseNumber)
+		if err := writeRedirect(zw, slug, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeRedirect(zw *zip.Writer, dir, target string) error {
+	f, err := zw.Create(dir + "/index.html")
+	if err != nil {
+		return err
+	}
+	return htmlRedirectTmpl.Execute(f, target)
+}
+
+func htmlChapterFile(number int) string {
+	return fmt.Sprintf("%03d.html", number)
+}
+
+func htmlStylesheet(opts HTMLZipOptions) string {
+	if len(opts.Font) == 0 {
+		return htmlCSS
+	}
+
+	mime, format := "font/ttf", "truetype"
+	switch strings.ToLower(path.Ext(opts.FontName)) {
+	case ".woff2":
+		mime, format = "font/woff2", "woff2"
+	case ".woff":
+		mime, format = "font/woff", "woff"
+	case ".otf":
+		mime, format = "font/otf", "opentype"
+	}
+
+	fontFace := fmt.Sprintf("@font-face {\n  font-family: \"QuranFont\";\n  src: url(data:%s;base64,%s) format(%q);\n}\n",
+		mime, base64.StdEncoding.EncodeToString(opts.Font), format)
+	return fontFace + htmlCSS
+}
+
+const htmlCSS = `body { margin: 0 auto; max-width: 48em; padding: 1em; font-family: sans-serif; line-height: 1.6; color: #222; }
+h1 { text-align: center; }
+nav { text-align: center; margin: 1em 0; }
+.arabic { font-family: "QuranFont", "KFGQPC Uthmanic Script HAFS", "Amiri Quran", serif; font-size: 1.8em; line-height: 2.2; text-align: right; }
+.chapter-name, .basmalah { text-align: center; }
+.verse { border-bottom: 1px solid #eee; padding: .5em 0; }
+.translation { color: #444; }
+.marker { font-size: .8em; }
+.bookmark { border-left: 4px solid #888; padding-left: .75em; }
+.bookmark .note { font-style: italic; color: #555; }
+.mark-memorized { border-left-color: #2e7d32; }
+.mark-reflect { border-left-color: #1565c0; }
+.mark-dua { border-left-color: #f9a825; }
+ol.chapters { columns: 2; }
+`
+
+var htmlFuncs = template.FuncMap{
+	"file":      htmlChapterFile,
+	"marker":    verseEndMarker,
+	"stripTags": stripTags,
+	"basmalah":  func() string { return basmalah },
+	"cite": func(c Chapter, verse int) string {
+		return c.Cite(verse, 0).String()
+	},
+	"hasBasmalahHeader": func(c Chapter) bool {
+		return c.hasBasmalahHeader()
+	},
+}
+
+var htmlIndexTmpl = template.Must(template.New("index").Funcs(htmlFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>The Noble Qur'an</title>
+<style>{{.Style}}</style>
+</head>
+<body>
+<h1>The Noble Qur'an</h1>
+<ol class="chapters">
+{{- range .Chapters}}
+<li value="{{.Number}}"><a href="{{file .Number}}">{{.NameSimple}}</a> <span class="arabic" dir="rtl" lang="ar">{{.NameArabic}}</span></li>
+{{- end}}
+</ol>
+</body>
+</html>
+`))
+
+var htmlChapterTmpl = template.Must(template.New("chapter").Funcs(htmlFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Number}}. {{.NameSimple}}</title>
+<style>{{.Style}}</style>
+{{- with .Canonical}}
+<link rel="canonical" href="{{.}}">
+{{- end}}
+</head>
+<body>
+<nav><a href="index.html">Index</a></nav>
+<h1>{{.Number}}. {{.NameSimple}} <small>({{.TranslatedName.Name}})</small></h1>
+<p class="arabic chapter-name" dir="rtl" lang="ar">{{.NameArabic}}</p>
+{{- if hasBasmalahHeader .Chapter}}
+<p class="arabic basmalah" dir="rtl" lang="ar">{{basmalah}}</p>
+{{- end}}
+{{- range .Verses}}
+{{- $mark := $.Bookmark .VerseKey}}
+<div class="verse{{with $mark}} bookmark{{with .Category}} mark-{{.}}{{end}}{{end}}" id="{{.VerseNumber}}" title="{{cite $.Chapter .VerseNumber}}"
+{{- with $mark}}{{with .Color}} style="border-left-color: {{.}}"{{end}}{{end}}>
+<p class="arabic" dir="rtl" lang="ar">{{.TextMadani}} <span class="marker">{{marker .VerseNumber}}</span></p>
+{{- range .Translations}}
+<p class="translation">{{stripTags .Text}}</p>
+{{- end}}
+{{- with $mark}}{{with .Note}}
+<p class="note">{{.}}</p>
+{{- end}}{{end}}
+</div>
+{{- end}}
+</body>
+</html>
+`))
+
+var htmlRedirectTmpl = template.Must(template.New("redirect").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="0; url={{.}}">
+<title>Redirecting</title>
+</head>
+<body><a href="{{.}}">{{.}}</a></body>
+</html>
+`))
@@ -0,0 +1,184 @@
+package quranapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarkdownSplit selects how WriteMarkdownZip splits the verses into files.
+type MarkdownSplit string
+
+const (
+	MarkdownByChapter MarkdownSplit = "chapter"
+	MarkdownByJuz     MarkdownSplit = "juz"
+)
+
+type MarkdownOptions struct {
+	// Split defaults to MarkdownByChapter.
+	Split MarkdownSplit
+	// Transliteration and Translations select what follows each verse's
+	// Arabic, as in RenderOptions.
+	Transliteration bool
+	Translations    []int
+}
+
+// markdownFrontmatter is the YAML frontmatter of a Markdown file, read as
+// properties by Obsidian and Notion.
+type markdownFrontmatter struct {
+	Title           string   `yaml:"title"`
+	Chapter         int      `yaml:"chapter,omitempty"`
+	Juz             int      `yaml:"juz,omitempty"`
+	NameArabic      string   `yaml:"name_arabic,omitempty"`
+	NameTranslated  string   `yaml:"name_translated,omitempty"`
+	RevelationPlace string   `yaml:"revelation_place,omitempty"`
+	RevelationOrder int      `yaml:"revelation_order,omitempty"`
+	Chapters        []int    `yaml:"chapters,omitempty,flow"`
+	Verses          int      `yaml:"verses"`
+	Pages           []int    `yaml:"pages,flow"`
+	Tags            []string `yaml:"tags,flow"`
+}
+
+// markdownFile is the content of one file of a Markdown export.
+type markdownFile struct {
+	name   string
+	front  markdownFrontmatter
+	blocks []markdownBlock
+}
+
+// markdownBlock is a run of verses of one chapter.
+type markdownBlock struct {
+	chapter Chapter
+	verses  []Verse
+}
+
+// WriteMarkdownZip writes a zip of Markdown files for note-taking apps such
+// as Obsidian and Notion, one per chapter or juz, with YAML frontmatter.
+// Each verse has a heading and ends with a block id, such as ^2-255, so
+// that notes can link to it as [[002 Al-Baqarah#^2-255]].
+func WriteMarkdownZip(w io.Writer, chapters []Chapter, opts MarkdownOptions) error {
+	var files []*markdownFile
+	switch opts.Split {
+	case "", MarkdownByChapter:
+		for _, c := range chapters {
+			files = append(files, &markdownFile{
+				name: markdownChapterFile(c),
+				front: markdownFrontmatter{
+					Title:           c.NameSimple,
+					Chapter:         c.Number,
+					NameArabic:      c.NameArabic,
+					NameTranslated:  c.TranslatedName.Name,
+					RevelationPlace: c.RevelationPlace,
+					RevelationOrder: c.RevelationOrder,
+					Verses:          len(c.Verses),
+					Pages:           []int{c.Pages.Start, c.Pages.End},
+					Tags:            []string{"quran", "surah"},
+				},
+				blocks: []markdownBlock{{chapter: c, verses: c.Verses}},
+			})
+		}
+	case MarkdownByJuz:
+		files = markdownJuzFiles(chapters)
+	default:
+		return fmt.Errorf("invalid markdown split %q: want chapter or juz", opts.Split)
+	}
+
+	zw := zip.NewWriter(w)
+	for _, file := range files {
+		f, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if err := file.write(f, opts); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// markdownJuzFiles groups the verses of chapters by juz.
+func markdownJuzFiles(chapters []Chapter) []*markdownFile {
+	var files []*markdownFile
+	byJuz := make(map[int]*markdownFile)
+	for _, c := range chapters {
+		for _, v := range c.Verses {
+			file := byJuz[v.JuzNumber]
+			if file == nil {
+				file = &markdownFile{
+					name: fmt.Sprintf("Juz %02d.md", v.JuzNumber),
+					front: markdownFrontmatter{
+						Title: fmt.Sprintf("Juz %d", v.JuzNumber),
+						Juz:   v.JuzNumber,
+						Pages: []int{v.PageNumber, v.PageNumber},
+						Tags:  []string{"quran", "juz"},
+					},
+				}
+				byJuz[v.JuzNumber] = file
+				files = append(files, file)
+			}
+			if n := len(file.blocks); n == 0 || file.blocks[n-1].chapter.Number != c.Number {
+				file.blocks = append(file.blocks, markdownBlock{chapter: c})
+				file.front.Chapters = append(file.front.Chapters, c.Number)
+			}
+			b := &file.blocks[len(file.blocks)-1]
+			b.verses = append(b.verses, v)
+			file.front.Verses++
+			file.front.Pages[1] = max(file.front.Pages[1], v.PageNumber)
+		}
+	}
+	return files
+}
+
+func markdownChapterFile(c Chapter) string {
+	name := strings.NewReplacer("/", "-", "'", "").Replace(c.NameSimple)
+	return fmt.Sprintf("%03d %s.md", c.Number, name)
+}
+
+func (file *markdownFile) write(w io.Writer, opts MarkdownOptions) error {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	front, err := yaml.Marshal(file.front)
+	if err != nil {
+		return err
+	}
+	buf.Write(front)
+	buf.WriteString("---\n\n")
+
+	fmt.Fprintf(&buf, "# %s\n", file.front.Title)
+	for _, b := range file.blocks {
+		if file.front.Juz != 0 {
+			fmt.Fprintf(&buf, "\n## %d. %s\n", b.chapter.Number, b.chapter.NameSimple)
+		}
+		if len(b.verses) > 0 && b.verses[0].VerseNumber == 1 && b.chapter.hasBasmalahHeader() {
+			fmt.Fprintf(&buf, "\n%s\n", basmalah)
+		}
+		for _, v := range b.verses {
+			writeMarkdownVerse(&buf, v, opts)
+		}
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func writeMarkdownVerse(buf *bytes.Buffer, v Verse, opts MarkdownOptions) {
+	fmt.Fprintf(buf, "\n### %s\n\n%s %s ^%s\n", v.VerseKey, v.TextMadani, verseEndMarker(v.VerseNumber),
+		strings.ReplaceAll(v.VerseKey.String(), ":", "-"))
+	if opts.Transliteration {
+		translit := v.Transliteration
+		if translit == "" {
+			translit = Transliterate(v.TextMadani)
+		}
+		fmt.Fprintf(buf, "\n*%s*\n", markdownEscape(translit))
+	}
+	for _, tr := range selectTranslations(v.Translations, opts.Translations) {
+		fmt.Fprintf(buf, "\n%s", markdownEscape(stripTags(tr.Text)))
+		if tr.ResourceName != "" {
+			fmt.Fprintf(buf, " — %s", markdownEscape(tr.ResourceName))
+		}
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,111 @@
+package quranapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Feature is an optional subsystem that minimal deployments, such as a
+// text-only kiosk, can switch off.
+type Feature string
+
+const (
+	FeatureAudio      Feature = "audio"
+	FeatureTafsir     Feature = "tafsir"
+	FeatureSearch     Feature = "search"
+	FeatureMedia      Feature = "media"
+	FeatureMorphology Feature = "morphology"
+)
+
+// Features lists every Feature.
+var Features = []Feature{FeatureAudio, FeatureTafsir, FeatureSearch, FeatureMedia, FeatureMorphology}
+
+// ErrFeatureDisabled matches every *FeatureDisabledError.
+var ErrFeatureDisabled = errors.New("feature disabled")
+
+// FeatureDisabledError is returned by the APIs of a disabled feature.
+type FeatureDisabledError struct {
+	Feature Feature
+}
+
+func (e *FeatureDisabledError) Error() string {
+	return fmt.Sprintf("%s disabled", e.Feature)
+}
+
+func (e *FeatureDisabledError) Is(target error) bool {
+	return target == ErrFeatureDisabled
+}
+
+// ParseFeatures parses a comma separated list of feature names.
+func ParseFeatures(s string) ([]Feature, error) {
+	var out []Feature
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		f := Feature(name)
+		if err := f.Validate(); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+func (f Feature) Validate() error {
+	for _, known := range Features {
+		if f == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown feature %q", string(f))
+}
+
+// WithDisabledFeatures switches features off: their APIs return a
+// *FeatureDisabledError and their background work, such as search
+// indexing, is skipped.
+func WithDisabledFeatures(features ...Feature) Option {
+	return func(q *QuranService) {
+		if q.disabled == nil {
+			q.disabled = make(map[Feature]bool)
+		}
+		for _, f := range features {
+			q.disabled[f] = true
+		}
+	}
+}
+
+// FeatureEnabled reports whether f is available: not disabled and, for
+// media, configured WithMedia.
+func (q *QuranService) FeatureEnabled(f Feature) bool {
+	if q.disabled[f] {
+		return false
+	}
+	if f == FeatureMedia {
+		return q.media != 0
+	}
+	return true
+}
+
+// FeatureMatrix reports which features are available, for front ends to
+// hide what the deployment doesn't offer.
+func (q *QuranService) FeatureMatrix() map[Feature]bool {
+	out := make(map[Feature]bool, len(Features))
+	for _, f := range Features {
+		out[f] = q.FeatureEnabled(f)
+	}
+	return out
+}
+
+// requireFeature returns the error for a disabled feature.
+func (q *QuranService) requireFeature(f Feature) error {
+	if f == FeatureMedia && !q.FeatureEnabled(f) {
+		return ErrMediaDisabled
+	}
+	if !q.FeatureEnabled(f) {
+		return &FeatureDisabledError{Feature: f}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GlyphSet selects which Quran Complex (QCF) page fonts the word codes
+// refer to.
+type GlyphSet string
+
+const (
+	// GlyphSetV1 uses Word.Code with the QCF v1 page fonts.
+	GlyphSetV1 GlyphSet = "v1"
+	// GlyphSetV2 uses Word.CodeV3 with the QCF v2 page fonts.
+	GlyphSetV2 GlyphSet = "v2"
+)
+
+func (s GlyphSet) Validate() error {
+	if s != GlyphSetV1 && s != GlyphSetV2 {
+		return fmt.Errorf("invalid glyph set %q: want v1 or v2", string(s))
+	}
+	return nil
+}
+
+// DefaultFontBaseURL hosts the QCF page fonts as {set}/woff2/p{page}.woff2.
+const DefaultFontBaseURL = "https://static.qurancdn.com/fonts/quran/hafs"
+
+// PageFont is the font family name glyph codes of a page are drawn with;
+// each page of the mushaf has its own font.
+func PageFont(page int, set GlyphSet) string {
+	return fmt.Sprintf("p%d-%s", page, set)
+}
+
+// PageFontURL is where the font of a page can be downloaded from.
+func PageFontURL(base string, page int, set GlyphSet) string {
+	return fmt.Sprintf("%s/%s/woff2/p%d.woff2", strings.TrimSuffix(base, "/"), set, page)
+}
+
+// GlyphWord is a word, or verse end marker, as drawn with a page font.
+type GlyphWord struct {
+	VerseKey VerseKey `json:"verse_key"`
+	Position int      `json:"position"`
+	CharType string   `json:"char_type"`
+	Code     string   `json:"code"`
+	// Text is the word in Unicode for copying and accessibility.
+	Text string `json:"text"`
+}
+
+type GlyphLine struct {
+	Number int         `json:"number"`
+	Words  []GlyphWord `json:"words"`
+}
+
+// PageGlyphs is a mushaf page as lines of glyph codes, to be drawn with
+// the page's own font for a pixel-accurate rendering.
+type PageGlyphs struct {
+	Page  int         `json:"page"`
+	Set   GlyphSet    `json:"set"`
+	Font  string      `json:"font"`
+	Lines []GlyphLine `json:"lines"`
+}
+
+// GetPageGlyphs returns the words on mushaf page n grouped into its
+// printed lines.
+func (q *QuranService) GetPageGlyphs(ctx context.Context, n int, set GlyphSet) (PageGlyphs, error) {
+	if err := set.Validate(); err != nil {
+		return PageGlyphs{}, err
+	}
+	words, err := q.pageWords(ctx, n)
+	if err != nil {
+		return PageGlyphs{}, err
+	}
+
+	out := PageGlyphs{Page: n, Set: set, Font: PageFont(n, set)}
+	for _, w := range words {
+		code := w.Code
+		if set == GlyphSetV2 {
+			code = w.CodeV3
+		}
+		if len(out.Lines) == 0 || out.Lines[len(out.Lines)-1].Number != w.LineNumber {
+			out.Lines = append(out.Lines, GlyphLine{Number: w.LineNumber})
+		}
+		line := &out.Lines[len(out.Lines)-1]
+		line.Words = append(line.Words, GlyphWord{
+			VerseKey: w.VerseKey,
+			Position: w.Position,
+			CharType: w.CharType,
+			Code:     code,
+			Text:     w.TextMadani,
+		})
+	}
+	return out, nil
+}
+
+// pageWords returns the words printed on mushaf page n in order, including
+// those of a verse begun on the previous page.
+func (q *QuranService) pageWords(ctx context.Context, n int) ([]Word, error) {
+	if q.profile == ProfileLite {
+		return nil, ErrWordsNotStored
+	}
+	verses, err := q.Page(ctx, n)
+	if err != nil {
+		return nil, err
+	}
+	if prev, err := PrevPage(n); err == nil {
+		before, err := q.Page(ctx, prev)
+		if err != nil {
+			return nil, err
+		}
+		if len(before) > 0 {
+			verses = append(before[len(before)-1:], verses...)
+		}
+	}
+
+	var out []Word
+	for _, v := range verses {
+		for _, w := range v.Words {
+			if w.PageNumber == n {
+				if w.VerseKey == "" {
+					w.VerseKey = v.VerseKey
+				}
+				out = append(out, w)
+			}
+		}
+	}
+	return out, nil
+}
+
+// DownloadPageFonts saves the fonts of the given pages, or of every page
+// when none are given, into dir as {set}/p{page}.woff2, so that the sets
+// don't overwrite each other. Fonts already saved are skipped, so an
+// interrupted download can be resumed.
+func DownloadPageFonts(ctx context.Context, client Doer, base, dir string, set GlyphSet, pages ...int) error {
+	if err := set.Validate(); err != nil {
+		return err
+	}
+	if len(pages) == 0 {
+		for p := 1; p <= MushafPages; p++ {
+			pages = append(pages, p)
+		}
+	}
+	dir = filepath.Join(dir, string(set))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	for _, page := range pages {
+		if err := validPage(page); err != nil {
+			return err
+		}
+		path := filepath.Join(dir, fmt.Sprintf("p%d.woff2", page))
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := downloadFile(ctx, client, PageFontURL(base, page, set), path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func downloadFile(ctx context.Context, client Doer, url, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download %s: status %d", url, resp.StatusCode)
+	}
+
+	// write aside and rename so that a partial file is never skipped as
+	// complete
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+const glyphsUsage = `usage: glyphs [-set v1|v2] <page>
+       glyphs fonts [-set v1|v2] [-dir fonts] [-base url] [page...]`
+
+func runGlyphs(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) > 0 && args[0] == "fonts" {
+		fs := flag.NewFlagSet("glyphs fonts", flag.ContinueOnError)
+		set := fs.String("set", string(GlyphSetV1), "glyph set")
+		dir := fs.String("dir", "fonts", "directory to save the fonts in")
+		base := fs.String("base", DefaultFontBaseURL, "font base URL")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		pages, err := parseInts(strings.Join(fs.Args(), ","))
+		if err != nil {
+			return err
+		}
+		return DownloadPageFonts(ctx, q.doer, *base, *dir, GlyphSet(*set), pages...)
+	}
+
+	fs := flag.NewFlagSet("glyphs", flag.ContinueOnError)
+	set := fs.String("set", string(GlyphSetV1), "glyph set")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New(glyphsUsage)
+	}
+	page, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return errors.New(glyphsUsage)
+	}
+
+	glyphs, err := q.GetPageGlyphs(ctx, page, GlyphSet(*set))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("page %d, font %s\n", glyphs.Page, glyphs.Font)
+	for _, line := range glyphs.Lines {
+		codes := make([]string, len(line.Words))
+		for i, w := range line.Words {
+			codes[i] = w.Code
+		}
+		fmt.Printf("%2d\t%s\n", line.Number, strings.Join(codes, " "))
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const bucketHifz = "hifz"
+
+type HifzStatus string
+
+const (
+	HifzMemorized HifzStatus = "memorized"
+	HifzReviewing HifzStatus = "under-review"
+)
+
+// HifzItem tracks the memorization of a span of verses. Reviews are
+// scheduled with the SM-2 algorithm: each graded review grows or resets
+// the interval until the next one.
+type HifzItem struct {
+	ID          string     `json:"id"` // the scope, e.g. "2:1-5", "juz 30" or "hizb 59"
+	Scope       Scope      `json:"scope"`
+	Status      HifzStatus `json:"status"`
+	Ease        float64    `json:"ease"`
+	Repetitions int        `json:"repetitions"`
+	IntervalDay int        `json:"interval_days"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ReviewedAt  time.Time  `json:"reviewed_at,omitempty"`
+	Due         time.Time  `json:"due"`
+}
+
+const (
+	sm2InitialEase = 2.5
+	sm2MinEase     = 1.3
+)
+
+// review grades a recall from 0 (blackout) to 5 (perfect) and schedules the
+// next review as SM-2 does.
+func (h HifzItem) review(quality int, now time.Time) HifzItem {
+	if quality < 3 {
+		h.Repetitions = 0
+		h.IntervalDay = 1
+		h.Status = HifzReviewing
+	} else {
+		h.Repetitions++
+		switch h.Repetitions {
+		case 1:
+			h.IntervalDay = 1
+		case 2:
+			h.IntervalDay = 6
+		default:
+			h.IntervalDay = int(math.Round(float64(h.IntervalDay) * h.Ease))
+		}
+		h.Status = HifzMemorized
+	}
+
+	miss := float64(5 - quality)
+	h.Ease += 0.1 - miss*(0.08+miss*0.02)
+	if h.Ease < sm2MinEase {
+		h.Ease = sm2MinEase
+	}
+
+	h.ReviewedAt = now
+	h.Due = now.AddDate(0, 0, h.IntervalDay)
+	return h
+}
+
+func (q *QuranService) markHifz(ctx context.Context, scope Scope, status HifzStatus, now time.Time) (HifzItem, error) {
+	if err := scope.Validate(); err != nil {
+		return HifzItem{}, err
+	}
+
+	item, err := q.getHifz(ctx, scope.String())
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		item = HifzItem{
+			ID:        scope.String(),
+			Scope:     scope,
+			Ease:      sm2InitialEase,
+			CreatedAt: now,
+		}
+	case err != nil:
+		return HifzItem{}, err
+	}
+
+	item.Status = status
+	if status == HifzReviewing {
+		item.Due = now
+	} else if item.Due.IsZero() {
+		item = item.review(5, now)
+	}
+	return item, q.putValue(ctx, bucketHifz, item.ID, item)
+}
+
+// MarkMemorized records the verses in scope as memorized, scheduling their
+// first review for the next day.
+func (q *QuranService) MarkMemorized(ctx context.Context, scope Scope, now time.Time) (HifzItem, error) {
+	return q.markHifz(ctx, scope, HifzMemorized, now)
+}
+
+// MarkForReview flags the verses in scope as under review, due right away.
+func (q *QuranService) MarkForReview(ctx context.Context, scope Scope, now time.Time) (HifzItem, error) {
+	return q.markHifz(ctx, scope, HifzReviewing, now)
+}
+
+// ReviewHifz grades a review of the item with the given ID, from 0 (forgot)
+// to 5 (perfect recall), and schedules the next one.
+func (q *QuranService) ReviewHifz(ctx context.Context, id string, quality int, now time.Time) (HifzItem, error) {
+	if quality < 0 || quality > 5 {
+		return HifzItem{}, fmt.Errorf("review quality %d is outside 0-5", quality)
+	}
+	item, err := q.getHifz(ctx, id)
+	if err != nil {
+		return HifzItem{}, err
+	}
+	item = item.review(quality, now)
+	return item, q.putValue(ctx, bucketHifz, item.ID, item)
+}
+
+// HifzProgress returns every tracked item in mushaf order.
+func (q *QuranService) HifzProgress(ctx context.Context) ([]HifzItem, error) {
+	var out []HifzItem
+	err := q.iterate(ctx, bucketHifz, func(key string, value []byte) error {
+		var item HifzItem
+		if err := valueDecode(value, &item); err != nil {
+			return err
+		}
+		out = append(out, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].Scope, out[j].Scope
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Number != b.Number {
+			return a.Number < b.Number
+		}
+		return a.FromVerse < b.FromVerse
+	})
+	return out, nil
+}
+
+// DueForReview returns the items due at now, most overdue first.
+func (q *QuranService) DueForReview(ctx context.Context, now time.Time) ([]HifzItem, error) {
+	items, err := q.HifzProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var due []HifzItem
+	for _, item := range items {
+		if !item.Due.After(now) {
+			due = append(due, item)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].Due.Before(due[j].Due)
+	})
+	return due, nil
+}
+
+func (q *QuranService) getHifz(ctx context.Context, id string) (HifzItem, error) {
+	var item HifzItem
+	err := q.getValue(ctx, bucketHifz, id, &item)
+	return item, err
+}
+
+const hifzUsage = "usage: hifz list|due|memorized <scope>|review <scope>|grade <scope> <0-5>"
+
+func runHifz(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) == 0 {
+		return errors.New(hifzUsage)
+	}
+
+	now := time.Now().UTC()
+
+	printItems := func(items []HifzItem) {
+		for _, item := range items {
+			fmt.Printf("%s\t%s\tdue %s\n", item.ID, item.Status, item.Due.Format(time.DateOnly))
+		}
+	}
+
+	switch args[0] {
+	case "list":
+		items, err := q.HifzProgress(ctx)
+		if err != nil {
+			return err
+		}
+		printItems(items)
+		return nil
+	case "due":
+		items, err := q.DueForReview(ctx, now)
+		if err != nil {
+			return err
+		}
+		printItems(items)
+		return nil
+	case "memorized", "review":
+		if len(args) != 2 {
+			return errors.New(hifzUsage)
+		}
+		scope, err := q.ResolveScope(args[1])
+		if err != nil {
+			return err
+		}
+		if args[0] == "memorized" {
+			_, err = q.MarkMemorized(ctx, scope, now)
+		} else {
+			_, err = q.MarkForReview(ctx, scope, now)
+		}
+		return err
+	case "grade":
+		if len(args) != 3 {
+			return errors.New(hifzUsage)
+		}
+		scope, err := q.ResolveScope(args[1])
+		if err != nil {
+			return err
+		}
+		quality, err := strconv.Atoi(args[2])
+		if err != nil {
+			return fmt.Errorf("invalid review quality %q", args[2])
+		}
+		item, err := q.ReviewHifz(ctx, scope.String(), quality, now)
+		if err != nil {
+			return err
+		}
+		printItems([]HifzItem{item})
+		return nil
+	default:
+		return fmt.Errorf("unknown hifz command: %q", args[0])
+	}
+}
@@ -0,0 +1,42 @@
+package quranapi
+
+import "context"
+
+type CacheLayer string
+
+const (
+	CacheLayerMemory CacheLayer = "memory"
+	CacheLayerStore  CacheLayer = "store"
+)
+
+// Hooks receives usage events so embedders can feed their own analytics.
+// Methods are called synchronously on the request path and should return
+// quickly. Embed NopHooks to implement only the events of interest.
+type Hooks interface {
+	CacheHit(ctx context.Context, layer CacheLayer, key string)
+	CacheMiss(ctx context.Context, layer CacheLayer, key string)
+	Search(ctx context.Context, query string, results int)
+	VerseRead(ctx context.Context, verseKey string)
+}
+
+type NopHooks struct{}
+
+func (NopHooks) CacheHit(context.Context, CacheLayer, string)  {}
+func (NopHooks) CacheMiss(context.Context, CacheLayer, string) {}
+func (NopHooks) Search(context.Context, string, int)           {}
+func (NopHooks) VerseRead(context.Context, string)             {}
+
+func WithHooks(h Hooks) Option {
+	return func(q *QuranService) {
+		q.hooks = h
+	}
+}
+
+func (q *QuranService) cacheEvent(ctx context.Context, layer CacheLayer, key string, hit bool) {
+	q.log(ctx).DebugContext(ctx, "cache lookup", "layer", layer, "key", key, "hit", hit)
+	if hit {
+		q.hooks.CacheHit(ctx, layer, key)
+		return
+	}
+	q.hooks.CacheMiss(ctx, layer, key)
+}
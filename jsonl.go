@@ -0,0 +1,164 @@
+package quranapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// JSONLVerse is a line of a JSON Lines export: a verse with the summary of
+// its chapter, so that each line stands alone in data pipelines and an
+// import needs nothing else to rebuild the chapters.
+type JSONLVerse struct {
+	Verse
+	Chapter ChapterSummary `json:"chapter"`
+}
+
+// JSONLImportReport counts what ImportJSONL stored.
+type JSONLImportReport struct {
+	Chapters int
+	Verses   int
+}
+
+// WriteJSONL writes the verses of chapters as JSON Lines, one JSONLVerse
+// per line, returning the number of verses written.
+func WriteJSONL(w io.Writer, chapters []Chapter) (int, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	n := 0
+	for _, c := range chapters {
+		summary := c.summary()
+		for _, v := range c.Verses {
+			if err := enc.Encode(JSONLVerse{Verse: v, Chapter: summary}); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, bw.Flush()
+}
+
+// ExportJSONL streams every verse of the Qur'an to w as JSON Lines,
+// fetching the chapters that are not stored, and returns the number of
+// verses written.
+func (q *QuranService) ExportJSONL(ctx context.Context, w io.Writer) (int, error) {
+	n := 0
+	for id := 1; id <= ChapterCount; id++ {
+		chapter, err := q.GetChapter(ctx, id)
+		if err != nil {
+			return n, err
+		}
+		written, err := WriteJSONL(w, []Chapter{chapter})
+		n += written
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// ImportJSONL stores the chapters in a JSON Lines export, without
+// upstream requests, so that a fresh store can be seeded from a file. The
+// verses of each chapter must be consecutive. The chapter summaries are
+// stored too when the export holds every chapter.
+func (q *QuranService) ImportJSONL(ctx context.Context, r io.Reader) (JSONLImportReport, error) {
+	var (
+		report    JSONLImportReport
+		summaries []ChapterSummary
+		current   *Chapter
+	)
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		chapter := *current
+		current = nil
+		if got, want := len(chapter.Verses), chapterVerseCounts[chapter.ID-1]; got != want {
+			return fmt.Errorf("chapter %d: %d of %d verses", chapter.ID, got, want)
+		}
+		q.profile.strip(chapter.Verses)
+		if err := q.setChapterDB(ctx, chapter); err != nil {
+			return err
+		}
+		q.uncacheChapter(chapter.ID)
+		summaries = append(summaries, chapter.summary())
+		report.Chapters++
+		report.Verses += len(chapter.Verses)
+		return nil
+	}
+
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		var v JSONLVerse
+		err := dec.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("line %d: %w", line, err)
+		}
+		chapter, verse, err := ValidateVerseKey(string(v.VerseKey))
+		if err != nil {
+			return report, fmt.Errorf("line %d: %w", line, err)
+		}
+		if chapter != v.Chapter.ID {
+			return report, fmt.Errorf("line %d: verse %s is not in chapter %d", line, v.VerseKey, v.Chapter.ID)
+		}
+
+		if current == nil || current.ID != chapter {
+			if err := flush(); err != nil {
+				return report, err
+			}
+			if slices.ContainsFunc(summaries, func(s ChapterSummary) bool { return s.ID == chapter }) {
+				return report, fmt.Errorf("line %d: verses of chapter %d are not consecutive", line, chapter)
+			}
+			c := newChapter(v.Chapter, nil)
+			current = &c
+		}
+		if verse != len(current.Verses)+1 {
+			return report, fmt.Errorf("line %d: want verse %d of chapter %d, got %s", line, len(current.Verses)+1, chapter, v.VerseKey)
+		}
+		current.Verses = append(current.Verses, v.Verse)
+	}
+	if err := flush(); err != nil {
+		return report, err
+	}
+
+	if len(summaries) == ChapterCount {
+		slices.SortFunc(summaries, func(a, b ChapterSummary) int { return a.ID - b.ID })
+		if err := q.setSummaryDB(ctx, summaries); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func runImportJSONL(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: import-jsonl <file|->")
+	}
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	report, err := q.ImportJSONL(ctx, r)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("imported %d verses in %d chapters\n", report.Verses, report.Chapters)
+	return nil
+}
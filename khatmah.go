@@ -0,0 +1,315 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const bucketKhatmahs = "khatmahs"
+
+// ErrKhatmahNotFound is returned for khatmah IDs with no stored khatmah.
+var ErrKhatmahNotFound = errors.New("khatmah not found")
+
+// Khatmah tracks a reading of the whole mushaf, done at the reader's own
+// pace and in any order, by page or by verse. Several can run at once.
+type Khatmah struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Unit is PlanByPages or PlanByVerses.
+	Unit  PlanUnit  `json:"unit"`
+	Start time.Time `json:"start"`
+	// Read are the spans read so far, sorted and merged. Spans are page
+	// numbers or verse ordinals (1-6236) depending on Unit.
+	Read        []KhatmahSpan `json:"read"`
+	Log         []ReadingLog  `json:"log"`
+	CompletedAt time.Time     `json:"completed_at,omitempty"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// KhatmahSpan is a run of pages or verses, First to Last inclusive.
+type KhatmahSpan struct {
+	First int `json:"first"`
+	Last  int `json:"last"`
+}
+
+// ReadingLog is a span read at a time.
+type ReadingLog struct {
+	KhatmahSpan
+	At time.Time `json:"at"`
+}
+
+// KhatmahProgress summarizes how far a khatmah has come.
+type KhatmahProgress struct {
+	Read    int     `json:"read"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+	// NextUnread is the first page or verse ordinal not yet read, zero
+	// once the khatmah is complete.
+	NextUnread int `json:"next_unread,omitempty"`
+	// ProjectedCompletion is when the khatmah completes at the pace kept
+	// since it started. It is zero before anything is read and once it is
+	// complete.
+	ProjectedCompletion time.Time `json:"projected_completion,omitempty"`
+}
+
+// verseOrdinal returns the position of a verse in the mushaf, counted
+// from 1; verseAt is its inverse.
+func verseOrdinal(chapter, verse int) int {
+	n := verse
+	for _, count := range chapterVerseCounts[:chapter-1] {
+		n += count
+	}
+	return n
+}
+
+// parse parses a page number or a verse key, as u counts.
+func (u PlanUnit) parse(s string) (int, error) {
+	if u == PlanByVerses {
+		chapter, verse, err := ValidateVerseKey(s)
+		if err != nil {
+			return 0, err
+		}
+		return verseOrdinal(chapter, verse), nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid page %q", s)
+	}
+	return n, validPage(n)
+}
+
+// format formats a page number or verse ordinal as parse reads it.
+func (u PlanUnit) format(n int) string {
+	if u == PlanByVerses {
+		return verseKey(verseAt(n))
+	}
+	return strconv.Itoa(n)
+}
+
+// addSpan returns spans with s added, sorted and with overlapping and
+// adjacent spans merged.
+func addSpan(spans []KhatmahSpan, s KhatmahSpan) []KhatmahSpan {
+	spans = append(slices.Clone(spans), s)
+	sort.Slice(spans, func(i, j int) bool { return spans[i].First < spans[j].First })
+	out := spans[:1]
+	for _, s := range spans[1:] {
+		last := &out[len(out)-1]
+		if s.First <= last.Last+1 {
+			last.Last = max(last.Last, s.Last)
+		} else {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Progress returns the progress of k as of now.
+func (k Khatmah) Progress(now time.Time) KhatmahProgress {
+	total, _ := k.Unit.total()
+	p := KhatmahProgress{Total: total, NextUnread: 1}
+	for _, s := range k.Read {
+		p.Read += s.Last - s.First + 1
+		if s.First <= p.NextUnread {
+			p.NextUnread = s.Last + 1
+		}
+	}
+	p.Percent = math.Round(float64(p.Read)/float64(total)*1000) / 10
+	if p.Read >= total {
+		p.NextUnread = 0
+		return p
+	}
+	if p.Read > 0 {
+		days := max(civilDate(now).Sub(k.Start).Hours()/24+1, 1)
+		perDay := float64(p.Read) / days
+		remaining := math.Ceil(float64(total-p.Read) / perDay)
+		p.ProjectedCompletion = civilDate(now).AddDate(0, 0, int(remaining))
+	}
+	return p
+}
+
+// StartKhatmah begins a khatmah by pages or verses on start, or today
+// when start is zero.
+func (q *QuranService) StartKhatmah(ctx context.Context, name string, unit PlanUnit, start time.Time) (Khatmah, error) {
+	if unit == "" {
+		unit = PlanByPages
+	}
+	if unit != PlanByPages && unit != PlanByVerses {
+		return Khatmah{}, fmt.Errorf("a khatmah is tracked by pages or verses, not %q", unit)
+	}
+	if start.IsZero() {
+		start = time.Now()
+	}
+	k := Khatmah{
+		ID:        randomID(),
+		Name:      name,
+		Unit:      unit,
+		Start:     civilDate(start),
+		Read:      []KhatmahSpan{},
+		Log:       []ReadingLog{},
+		UpdatedAt: time.Now().UTC(),
+	}
+	return k, q.putValue(ctx, bucketKhatmahs, k.ID, k)
+}
+
+// GetKhatmah returns the khatmah with the given ID.
+func (q *QuranService) GetKhatmah(ctx context.Context, id string) (Khatmah, error) {
+	var k Khatmah
+	err := q.getValue(ctx, bucketKhatmahs, id, &k)
+	if errors.Is(err, ErrKeyNotFound) {
+		return Khatmah{}, fmt.Errorf("%w: %s", ErrKhatmahNotFound, id)
+	}
+	return k, err
+}
+
+// Khatmahs returns the stored khatmahs, most recently started first.
+func (q *QuranService) Khatmahs(ctx context.Context) ([]Khatmah, error) {
+	var out []Khatmah
+	err := q.iterate(ctx, bucketKhatmahs, func(key string, value []byte) error {
+		var k Khatmah
+		if err := valueDecode(value, &k); err != nil {
+			return err
+		}
+		out = append(out, k)
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Start.After(out[j].Start)
+	})
+	return out, err
+}
+
+// DeleteKhatmah moves the khatmah to the trash.
+func (q *QuranService) DeleteKhatmah(ctx context.Context, id string) error {
+	err := q.softDelete(ctx, bucketKhatmahs, id)
+	if errors.Is(err, ErrKeyNotFound) {
+		return fmt.Errorf("%w: %s", ErrKhatmahNotFound, id)
+	}
+	return err
+}
+
+// LogReading records that the pages or verses from to to, page numbers or
+// verse keys depending on the khatmah's unit, were read. Reading them
+// again does not count twice. The khatmah is marked complete once every
+// page or verse is read.
+func (q *QuranService) LogReading(ctx context.Context, id, from, to string) (Khatmah, error) {
+	k, err := q.GetKhatmah(ctx, id)
+	if err != nil {
+		return Khatmah{}, err
+	}
+	first, err := k.Unit.parse(from)
+	if err != nil {
+		return Khatmah{}, err
+	}
+	last, err := k.Unit.parse(to)
+	if err != nil {
+		return Khatmah{}, err
+	}
+	if last < first {
+		return Khatmah{}, fmt.Errorf("reading ends at %s before it starts at %s", to, from)
+	}
+
+	now := time.Now().UTC()
+	span := KhatmahSpan{First: first, Last: last}
+	k.Read = addSpan(k.Read, span)
+	k.Log = append(k.Log, ReadingLog{KhatmahSpan: span, At: now})
+	k.UpdatedAt = now
+	if total, _ := k.Unit.total(); k.CompletedAt.IsZero() && len(k.Read) == 1 && k.Read[0] == (KhatmahSpan{1, total}) {
+		k.CompletedAt = now
+	}
+	return k, q.putValue(ctx, bucketKhatmahs, k.ID, k)
+}
+
+const khatmahUsage = "usage: khatmah new [-by pages|verses] [-name name] [-start yyyy-mm-dd] | list | log <id> <from> [to] | show <id> | rm <id>"
+
+func printKhatmah(k Khatmah, now time.Time) {
+	p := k.Progress(now)
+	fmt.Printf("%s\t%s\t%d/%d %s (%.1f%%)", k.ID, k.Name, p.Read, p.Total, k.Unit, p.Percent)
+	switch {
+	case !k.CompletedAt.IsZero():
+		fmt.Printf("\tcompleted %s", k.CompletedAt.Format(time.DateOnly))
+	case p.NextUnread > 0:
+		fmt.Printf("\tnext %s", k.Unit.format(p.NextUnread))
+		if !p.ProjectedCompletion.IsZero() {
+			fmt.Printf("\tcompletes %s", p.ProjectedCompletion.Format(time.DateOnly))
+		}
+	}
+	fmt.Println()
+}
+
+func runKhatmah(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) == 0 {
+		return errors.New(khatmahUsage)
+	}
+
+	switch args[0] {
+	case "new":
+		fs := flag.NewFlagSet("khatmah new", flag.ContinueOnError)
+		by := fs.String("by", string(PlanByPages), "track by pages or verses")
+		name := fs.String("name", "", "khatmah name")
+		start := fs.String("start", "", "day the khatmah started (defaults to today)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		var startDate time.Time
+		if *start != "" {
+			t, err := time.Parse(time.DateOnly, *start)
+			if err != nil {
+				return err
+			}
+			startDate = t
+		}
+		k, err := q.StartKhatmah(ctx, *name, PlanUnit(*by), startDate)
+		if err != nil {
+			return err
+		}
+		fmt.Println(k.ID)
+		return nil
+	case "list":
+		khatmahs, err := q.Khatmahs(ctx)
+		if err != nil {
+			return err
+		}
+		for _, k := range khatmahs {
+			printKhatmah(k, time.Now())
+		}
+		return nil
+	case "log":
+		if len(args) != 3 && len(args) != 4 {
+			return errors.New(khatmahUsage)
+		}
+		to := args[len(args)-1]
+		k, err := q.LogReading(ctx, args[1], args[2], to)
+		if err != nil {
+			return err
+		}
+		printKhatmah(k, time.Now())
+		return nil
+	case "show":
+		if len(args) != 2 {
+			return errors.New(khatmahUsage)
+		}
+		k, err := q.GetKhatmah(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		printKhatmah(k, time.Now())
+		for _, l := range k.Log {
+			fmt.Printf("%s\t%s-%s\n", l.At.Format(time.DateTime), k.Unit.format(l.First), k.Unit.format(l.Last))
+		}
+		return nil
+	case "rm":
+		if len(args) != 2 {
+			return errors.New(khatmahUsage)
+		}
+		return q.DeleteKhatmah(ctx, args[1])
+	default:
+		return fmt.Errorf("unknown khatmah command: %q", args[0])
+	}
+}
@@ -0,0 +1,234 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const bucketLanguages = "languages"
+
+// ErrLanguageNotFound is returned for languages upstream has no
+// translations in, and by RemoveLanguage for languages not installed.
+var ErrLanguageNotFound = errors.New("language not found")
+
+// LanguagePack is the set of translations stored for one language, such as
+// "english". Bytes is the size of their text in the stored chapters.
+type LanguagePack struct {
+	Language    string
+	ResourceIDs []int
+	InstalledAt time.Time
+	Bytes       int64
+}
+
+type translationResource struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	LanguageName string `json:"language_name"`
+}
+
+// languageResources returns the ids of the translations upstream has in
+// lang.
+func (q *QuranService) languageResources(ctx context.Context, lang string) ([]int, error) {
+	var resp struct {
+		Translations []translationResource `json:"translations"`
+	}
+	path := "/options/translations"
+	ctx, span := q.startSpan(ctx, "GET "+path)
+	err := q.fetch(ctx, path, q.httpClient.Get(path), &resp)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for _, r := range resp.Translations {
+		if strings.EqualFold(r.LanguageName, lang) {
+			ids = append(ids, r.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrLanguageNotFound, lang)
+	}
+	return ids, nil
+}
+
+// InstallLanguage stores every translation upstream has in lang, such as
+// "english" or "urdu", with the stored chapters and those fetched later.
+func (q *QuranService) InstallLanguage(ctx context.Context, lang string) (LanguagePack, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	ids, err := q.languageResources(ctx, lang)
+	if err != nil {
+		return LanguagePack{}, err
+	}
+
+	pack := LanguagePack{Language: lang, ResourceIDs: ids, InstalledAt: time.Now().UTC()}
+	// record the pack first, so that chapters fetched meanwhile include it
+	if err := q.putValue(ctx, bucketLanguages, lang, pack); err != nil {
+		return LanguagePack{}, err
+	}
+	report, err := q.SyncTranslations(ctx, ids)
+	if err != nil {
+		return pack, err
+	}
+	if len(report.Failed) > 0 {
+		return pack, fmt.Errorf("install %s: %d chapters failed, install again to retry", lang, len(report.Failed))
+	}
+	return pack, nil
+}
+
+// RemoveLanguage drops the translations of an installed language from the
+// stored chapters, keeping those the service was configured with.
+func (q *QuranService) RemoveLanguage(ctx context.Context, lang string) error {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	var pack LanguagePack
+	err := q.getValue(ctx, bucketLanguages, lang, &pack)
+	if errors.Is(err, ErrKeyNotFound) {
+		return fmt.Errorf("%w: %s is not installed", ErrLanguageNotFound, lang)
+	}
+	if err != nil {
+		return err
+	}
+
+	var remove []int
+	for _, id := range pack.ResourceIDs {
+		if !slices.Contains(q.translations, id) {
+			remove = append(remove, id)
+		}
+	}
+	stored, err := q.storedChapters(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range stored {
+		if err := q.removeTranslations(ctx, id, remove); err != nil {
+			return err
+		}
+	}
+	return q.deleteValue(ctx, bucketLanguages, lang)
+}
+
+func (q *QuranService) removeTranslations(ctx context.Context, id int, resourceIDs []int) error {
+	chapter, err := q.getChapterDB(ctx, id)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i := range chapter.Verses {
+		v := &chapter.Verses[i]
+		n := len(v.Translations)
+		v.Translations = slices.DeleteFunc(v.Translations, func(tr Translation) bool {
+			return slices.Contains(resourceIDs, tr.ResourceID)
+		})
+		changed = changed || len(v.Translations) != n
+	}
+	if !changed {
+		return nil
+	}
+	if err := q.setChapterDB(ctx, chapter); err != nil {
+		return err
+	}
+	q.uncacheChapter(id)
+	return nil
+}
+
+// ListInstalled returns the installed language packs by language, with
+// the size of their text in the stored chapters.
+func (q *QuranService) ListInstalled(ctx context.Context) ([]LanguagePack, error) {
+	var packs []LanguagePack
+	err := q.store.Iterate(ctx, bucketLanguages, func(_ string, value []byte) error {
+		var pack LanguagePack
+		if err := valueDecode(value, &pack); err != nil {
+			return err
+		}
+		packs = append(packs, pack)
+		return nil
+	})
+	if err != nil || len(packs) == 0 {
+		return packs, err
+	}
+
+	stored, err := q.storedChapters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range stored {
+		chapter, err := q.getChapterDB(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range chapter.Verses {
+			for _, tr := range v.Translations {
+				for i := range packs {
+					if slices.Contains(packs[i].ResourceIDs, tr.ResourceID) {
+						packs[i].Bytes += int64(len(tr.Text))
+					}
+				}
+			}
+		}
+	}
+	slices.SortFunc(packs, func(a, b LanguagePack) int { return strings.Compare(a.Language, b.Language) })
+	return packs, nil
+}
+
+// fetchedTranslations are the translations requested with chapters: the
+// configured ones and those of the installed language packs.
+func (q *QuranService) fetchedTranslations(ctx context.Context) []int {
+	ids := slices.Clone(q.translations)
+	err := q.store.Iterate(ctx, bucketLanguages, func(_ string, value []byte) error {
+		var pack LanguagePack
+		if err := valueDecode(value, &pack); err != nil {
+			return err
+		}
+		for _, id := range pack.ResourceIDs {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		q.log(ctx).Warn("read language packs", "err", err)
+	}
+	return ids
+}
+
+const languagesUsage = "usage: languages list|install <language>|remove <language>"
+
+func runLanguages(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) == 0 {
+		return errors.New(languagesUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		packs, err := q.ListInstalled(ctx)
+		if err != nil {
+			return err
+		}
+		for _, p := range packs {
+			fmt.Printf("%s\t%d translations\t%s\tinstalled %s\n", p.Language, len(p.ResourceIDs), formatBytes(p.Bytes), p.InstalledAt.Format(time.DateOnly))
+		}
+		return nil
+	case "install":
+		if len(args) != 2 {
+			return errors.New(languagesUsage)
+		}
+		pack, err := q.InstallLanguage(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("installed %s: translations %s\n", pack.Language, joinInts(pack.ResourceIDs))
+		return nil
+	case "remove":
+		if len(args) != 2 {
+			return errors.New(languagesUsage)
+		}
+		return q.RemoveLanguage(ctx, args[1])
+	default:
+		return errors.New(languagesUsage)
+	}
+}
@@ -0,0 +1,106 @@
+package quranapi
+
+import (
+	"context"
+)
+
+// LinesPerPage is the line count of a page of the Madani mushaf. The first
+// two pages are shorter and centered.
+const LinesPerPage = 15
+
+type LineKind string
+
+const (
+	LineText        LineKind = "text"
+	LineSurahHeader LineKind = "surah_header"
+	LineBasmalah    LineKind = "basmalah"
+)
+
+// Line is a line of a mushaf page: words of text, or the header or
+// basmalah opening the chapter Chapter.
+type Line struct {
+	Number  int      `json:"number"`
+	Kind    LineKind `json:"kind"`
+	Chapter int      `json:"chapter,omitempty"`
+	Words   []Word   `json:"words,omitempty"`
+}
+
+// PageLayout is a mushaf page line by line, as printed.
+type PageLayout struct {
+	Page  int    `json:"page"`
+	Lines []Line `json:"lines"`
+}
+
+// chapterOpening returns the lines printed above the first verse of a
+// chapter: its header and, except for Al-Fatihah and At-Tawbah, the
+// basmalah.
+func chapterOpening(chapter int) []LineKind {
+	if chapter == 1 || chapter == 9 {
+		return []LineKind{LineSurahHeader}
+	}
+	return []LineKind{LineSurahHeader, LineBasmalah}
+}
+
+// GetPageLayout returns mushaf page n line by line. Text lines come from
+// the words' line numbers; the lines left free above a chapter's first
+// verse hold its header and basmalah, which may also close a page whose
+// next page begins a chapter.
+func (q *QuranService) GetPageLayout(ctx context.Context, n int) (PageLayout, error) {
+	words, err := q.pageWords(ctx, n)
+	if err != nil {
+		return PageLayout{}, err
+	}
+
+	byLine := make(map[int]*Line)
+	last := 0
+	for _, w := range words {
+		l, ok := byLine[w.LineNumber]
+		if !ok {
+			l = &Line{Number: w.LineNumber, Kind: LineText}
+			byLine[w.LineNumber] = l
+		}
+		l.Words = append(l.Words, w)
+		last = max(last, w.LineNumber)
+
+		chapter, verse, err := w.VerseKey.Split()
+		if err != nil || verse != 1 || w.Position != 1 {
+			continue
+		}
+		opening := chapterOpening(chapter)
+		for i, kind := range opening {
+			number := w.LineNumber - len(opening) + i
+			if number >= 1 && byLine[number] == nil {
+				byLine[number] = &Line{Number: number, Kind: kind, Chapter: chapter}
+			}
+		}
+	}
+
+	// free lines at the bottom open the chapter starting the next page
+	if next, err := NextPage(n); err == nil && n > 2 && last < LinesPerPage {
+		verses, err := q.Page(ctx, next)
+		if err != nil {
+			return PageLayout{}, err
+		}
+		if len(verses) > 0 && verses[0].VerseNumber == 1 {
+			opening := chapterOpening(verses[0].ChapterID)
+			for i, kind := range opening {
+				number := LinesPerPage - len(opening) + 1 + i
+				if number > last {
+					byLine[number] = &Line{Number: number, Kind: kind, Chapter: verses[0].ChapterID}
+				}
+			}
+		}
+	}
+
+	count := LinesPerPage
+	if n <= 2 {
+		count = last
+	}
+	out := PageLayout{Page: n}
+	for number := 1; number <= count; number++ {
+		if l, ok := byLine[number]; ok {
+			out.Lines = append(out.Lines, *l)
+		}
+	}
+	return out, nil
+}
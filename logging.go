@@ -0,0 +1,67 @@
+package quranapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// WithLogger sets the logger used by the service. It defaults to
+// slog.Default().
+func WithLogger(l *slog.Logger) Option {
+	return func(q *QuranService) {
+		q.logger = l
+	}
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID tags ctx with a request ID included in every log
+// line the service writes while handling it.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+func NewRequestID() string {
+	return randomID()
+}
+
+// randomID returns 16 random hex digits.
+func randomID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func ParseLogLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToUpper(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+func (q *QuranService) log(ctx context.Context) *slog.Logger {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return q.logger.With("request_id", id)
+	}
+	return q.logger
+}
+
+func (q *QuranService) logUpstream(ctx context.Context, path string, start time.Time, err error) {
+	latency := time.Since(start)
+	if err != nil {
+		q.log(ctx).WarnContext(ctx, "upstream request failed", "path", path, "latency", latency, "err", err)
+		return
+	}
+	q.log(ctx).DebugContext(ctx, "upstream request", "path", path, "latency", latency)
+}
@@ -0,0 +1,119 @@
+package quranapi
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const bucketMedia = "media"
+
+// ErrMediaDisabled is returned by GetVerseMedia when the service was
+// created without WithMedia or with FeatureMedia disabled.
+var ErrMediaDisabled error = &FeatureDisabledError{Feature: FeatureMedia}
+
+// MediaContent is a media embed attached to a verse upstream.
+type MediaContent struct {
+	URL        string `json:"url"`
+	EmbedText  string `json:"embed_text"`
+	Provider   string `json:"provider"`
+	AuthorName string `json:"author_name"`
+}
+
+// MediaItem is a MediaContent with its provider identified. YouTubeID is
+// set for YouTube videos.
+type MediaItem struct {
+	Provider   string `json:"provider"`
+	URL        string `json:"url"`
+	AuthorName string `json:"author_name,omitempty"`
+	YouTubeID  string `json:"youtube_id,omitempty"`
+	EmbedText  string `json:"embed_text,omitempty"`
+}
+
+// VerseMedia is the media attached to a verse.
+type VerseMedia struct {
+	VerseKey VerseKey    `json:"verse_key"`
+	Items    []MediaItem `json:"items"`
+}
+
+// WithMedia enables GetVerseMedia, fetching media from the upstream media
+// resource with the given id. Chapter fetches never include media.
+func WithMedia(resourceID int) Option {
+	return func(q *QuranService) {
+		q.media = resourceID
+	}
+}
+
+var youtubeIDRE = regexp.MustCompile(`(?:youtube(?:-nocookie)?\.com/(?:embed/|watch\?v=|v/)|youtu\.be/)([A-Za-z0-9_-]{11})`)
+
+// youtubeID extracts the video ID from a YouTube URL or embed snippet.
+func youtubeID(s string) string {
+	if m := youtubeIDRE.FindStringSubmatch(s); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func parseMedia(mc MediaContent) MediaItem {
+	item := MediaItem{
+		Provider:   strings.ToLower(mc.Provider),
+		URL:        mc.URL,
+		AuthorName: mc.AuthorName,
+		EmbedText:  mc.EmbedText,
+	}
+	id := youtubeID(mc.URL)
+	if id == "" {
+		id = youtubeID(mc.EmbedText)
+	}
+	if id != "" {
+		item.Provider = "youtube"
+		item.YouTubeID = id
+		if item.URL == "" {
+			item.URL = "https://www.youtube.com/watch?v=" + id
+		}
+	}
+	return item
+}
+
+// GetVerseMedia returns the media attached to the verse with the given
+// key, caching it in its own namespace.
+func (q *QuranService) GetVerseMedia(ctx context.Context, key string) (VerseMedia, error) {
+	if err := q.requireFeature(FeatureMedia); err != nil {
+		return VerseMedia{}, err
+	}
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return VerseMedia{}, err
+	}
+	key = verseKey(chapter, verse)
+
+	var media VerseMedia
+	err = q.getValue(ctx, bucketMedia, key, &media)
+	q.cacheEvent(ctx, CacheLayerStore, "media/"+key, err == nil)
+	if err == nil {
+		return media, nil
+	}
+
+	var resp struct {
+		Verse Verse `json:"verse"`
+	}
+	path := fmt.Sprintf("/chapters/%d/verses/%d", chapter, verse)
+	req := q.httpClient.Get(path).QueryParam("media", strconv.Itoa(q.media))
+	if err := q.fetch(ctx, path, req, &resp); err != nil {
+		if isNotFound(err) {
+			return VerseMedia{}, fmt.Errorf("%w: %s", ErrVerseNotFound, key)
+		}
+		return VerseMedia{}, err
+	}
+
+	media = VerseMedia{VerseKey: VerseKey(key), Items: []MediaItem{}}
+	for _, mc := range resp.Verse.MediaContents {
+		media.Items = append(media.Items, parseMedia(mc))
+	}
+	if err := q.putValue(ctx, bucketMedia, key, media); err != nil {
+		q.log(ctx).Error("cache verse media", "verse", key, "err", err)
+	}
+	return media, nil
+}
@@ -0,0 +1,155 @@
+package quranapi
+
+import (
+	"container/list"
+	"sync"
+)
+
+// WithMemCache keeps up to size decoded chapters, and as many verses, in
+// memory so hot reads skip the DB lookup and gob decode.
+func WithMemCache(size int) Option {
+	return func(q *QuranService) {
+		if size <= 0 {
+			return
+		}
+		q.chapterCache = newLRU[Chapter](size)
+		q.verseCache = newLRU[Verse](size)
+	}
+}
+
+type CacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+func (c CacheStats) HitRate() float64 {
+	total := c.Hits + c.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(c.Hits) / float64(total)
+}
+
+type MemCacheStats struct {
+	Chapters CacheStats
+	Verses   CacheStats
+}
+
+// MemCacheStats reports hit rates for the in-memory cache. It is zero when
+// the service was created without WithMemCache.
+func (q *QuranService) MemCacheStats() MemCacheStats {
+	return MemCacheStats{
+		Chapters: q.chapterCache.stats(),
+		Verses:   q.verseCache.stats(),
+	}
+}
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+}
+
+type lru[V any] struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+
+	hits, misses uint64
+}
+
+func newLRU[V any](size int) *lru[V] {
+	return &lru[V]{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *lru[V]) get(key string) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return zero, false
+	}
+	c.hits++
+	c.ll.MoveToFront(el)
+	return el.Value.(*lruEntry[V]).value, true
+}
+
+func (c *lru[V]) put(key string, value V) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry[V]).value = value
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry[V]{key: key, value: value})
+	for c.ll.Len() > c.size {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[V]).key)
+	}
+}
+
+func (c *lru[V]) remove(key string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// removeFunc drops every entry whose key matches fn.
+func (c *lru[V]) removeFunc(fn func(key string) bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.items {
+		if fn(key) {
+			c.ll.Remove(el)
+			delete(c.items, key)
+		}
+	}
+}
+
+func (c *lru[V]) stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Hits:    c.hits,
+		Misses:  c.misses,
+		Entries: c.ll.Len(),
+	}
+}
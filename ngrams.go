@@ -0,0 +1,331 @@
+package quranapi
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NGram is a run of consecutive words, normalized as search normalizes
+// them, with how often it occurs and in how many verses. N-grams never
+// span verses.
+type NGram struct {
+	Tokens []string `json:"tokens"`
+	Count  int      `json:"count"`
+	Verses int      `json:"verses"`
+}
+
+// WordPair is a cell of a co-occurrence matrix: two words, A sorting
+// before B, and how often they occur together.
+type WordPair struct {
+	A     string `json:"a"`
+	B     string `json:"b"`
+	Count int    `json:"count"`
+}
+
+// TextStatsOptions selects the text NGrams and Cooccurrences count and
+// trims their results.
+type TextStatsOptions struct {
+	// N is the n-gram length, 2 for bigrams unless set.
+	N int
+	// Window counts two words as co-occurring when at most this many words
+	// apart. Zero counts the verses where both occur.
+	Window int
+	// Words keeps the co-occurrences involving one of these words.
+	Words []string
+	// Translation counts the words of this translation resource rather
+	// than the Arabic text.
+	Translation int
+	// Chapters limits the count to these chapters; nil counts every
+	// stored chapter.
+	Chapters []int
+	// MinCount leaves out results occurring fewer times.
+	MinCount int
+	// Limit caps the results returned, most frequent first; zero returns
+	// all of them.
+	Limit int
+}
+
+// verseTokens calls fn with the words of each stored verse selected by
+// opts, in mushaf order. It never fetches from upstream.
+func (q *QuranService) verseTokens(ctx context.Context, opts TextStatsOptions, fn func(tokens []string)) error {
+	var chapters []Chapter
+	err := q.store.Iterate(ctx, bucketChapters, func(key string, value []byte) error {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil // not a chapter, e.g. the summaries entry
+		}
+		if opts.Chapters != nil && !slices.Contains(opts.Chapters, id) {
+			return nil
+		}
+		var chapter Chapter
+		if err := valueDecode(value, &chapter); err != nil {
+			return fmt.Errorf("chapter %s: %w", key, err)
+		}
+		chapters = append(chapters, chapter)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].ID < chapters[j].ID })
+
+	for _, c := range chapters {
+		for _, v := range c.Verses {
+			if opts.Translation == 0 {
+				fn(tokenize(v.TextMadani))
+				continue
+			}
+			for _, tr := range v.Translations {
+				if tr.ResourceID == opts.Translation {
+					fn(tokenize(stripTags(tr.Text)))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// NGrams returns the n-grams of the stored text, most frequent first.
+func (q *QuranService) NGrams(ctx context.Context, opts TextStatsOptions) ([]NGram, error) {
+	if opts.N == 0 {
+		opts.N = 2
+	}
+	if opts.N < 1 {
+		return nil, fmt.Errorf("invalid n-gram length %d", opts.N)
+	}
+
+	counts := make(map[string]*NGram)
+	err := q.verseTokens(ctx, opts, func(tokens []string) {
+		seen := make(map[string]bool)
+		for i := 0; i+opts.N <= len(tokens); i++ {
+			key := strings.Join(tokens[i:i+opts.N], " ")
+			g := counts[key]
+			if g == nil {
+				g = &NGram{Tokens: tokens[i : i+opts.N]}
+				counts[key] = g
+			}
+			g.Count++
+			if !seen[key] {
+				seen[key] = true
+				g.Verses++
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]NGram, 0, len(counts))
+	for _, g := range counts {
+		if g.Count >= opts.MinCount {
+			out = append(out, *g)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return slices.Compare(out[i].Tokens, out[j].Tokens) < 0
+	})
+	if opts.Limit > 0 && len(out) > opts.Limit {
+		out = out[:opts.Limit]
+	}
+	return out, nil
+}
+
+// Cooccurrences returns the nonzero cells of the co-occurrence matrix of
+// the stored text, most frequent first. A word is never paired with
+// itself.
+func (q *QuranService) Cooccurrences(ctx context.Context, opts TextStatsOptions) ([]WordPair, error) {
+	if opts.Window < 0 {
+		return nil, fmt.Errorf("invalid co-occurrence window %d", opts.Window)
+	}
+	var words map[string]bool
+	if len(opts.Words) > 0 {
+		words = make(map[string]bool)
+		for _, w := range opts.Words {
+			for _, t := range tokenize(w) {
+				words[t] = true
+			}
+		}
+	}
+
+	counts := make(map[[2]string]int)
+	add := func(a, b string) {
+		if a == b || (words != nil && !words[a] && !words[b]) {
+			return
+		}
+		if b < a {
+			a, b = b, a
+		}
+		counts[[2]string{a, b}]++
+	}
+	err := q.verseTokens(ctx, opts, func(tokens []string) {
+		if opts.Window > 0 {
+			for i := range tokens {
+				for j := i + 1; j < len(tokens) && j-i <= opts.Window; j++ {
+					add(tokens[i], tokens[j])
+				}
+			}
+			return
+		}
+		var distinct []string
+		for _, t := range tokens {
+			if !slices.Contains(distinct, t) {
+				distinct = append(distinct, t)
+			}
+		}
+		for i := range distinct {
+			for _, b := range distinct[i+1:] {
+				add(distinct[i], b)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]WordPair, 0, len(counts))
+	for pair, n := range counts {
+		if n >= opts.MinCount {
+			out = append(out, WordPair{A: pair[0], B: pair[1], Count: n})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		if out[i].A != out[j].A {
+			return out[i].A < out[j].A
+		}
+		return out[i].B < out[j].B
+	})
+	if opts.Limit > 0 && len(out) > opts.Limit {
+		out = out[:opts.Limit]
+	}
+	return out, nil
+}
+
+// WriteNGramsCSV writes ngrams as CSV with an ngram, count and verses
+// column.
+func WriteNGramsCSV(w io.Writer, ngrams []NGram) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"ngram", "count", "verses"})
+	for _, g := range ngrams {
+		cw.Write([]string{strings.Join(g.Tokens, " "), strconv.Itoa(g.Count), strconv.Itoa(g.Verses)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCooccurrenceCSV writes pairs as CSV: a row per pair with a word_a,
+// word_b and count column, or, when dense is set, the full symmetric
+// matrix over the words of pairs with a header row and a row per word.
+func WriteCooccurrenceCSV(w io.Writer, pairs []WordPair, dense bool) error {
+	cw := csv.NewWriter(w)
+	if !dense {
+		cw.Write([]string{"word_a", "word_b", "count"})
+		for _, p := range pairs {
+			cw.Write([]string{p.A, p.B, strconv.Itoa(p.Count)})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	index := make(map[string]int)
+	var words []string
+	for _, p := range pairs {
+		for _, word := range []string{p.A, p.B} {
+			if _, ok := index[word]; !ok {
+				index[word] = len(words)
+				words = append(words, word)
+			}
+		}
+	}
+	matrix := make([][]int, len(words))
+	for i := range matrix {
+		matrix[i] = make([]int, len(words))
+	}
+	for _, p := range pairs {
+		a, b := index[p.A], index[p.B]
+		matrix[a][b], matrix[b][a] = p.Count, p.Count
+	}
+	cw.Write(append([]string{""}, words...))
+	for i, row := range matrix {
+		record := make([]string, 0, len(row)+1)
+		record = append(record, words[i])
+		for _, n := range row {
+			record = append(record, strconv.Itoa(n))
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+const ngramsUsage = "usage: ngrams [-n 2] [-t id] [-min n] [-limit n] [-o file] [chapter...] | ngrams cooccur [-window n] [-word w]... [-dense] [-t id] [-min n] [-limit n] [-o file] [chapter...]"
+
+func runNGrams(ctx context.Context, q *QuranService, args []string) error {
+	cooccur := len(args) > 0 && args[0] == "cooccur"
+	if cooccur {
+		args = args[1:]
+	}
+	fs := flag.NewFlagSet("ngrams", flag.ContinueOnError)
+	n := fs.Int("n", 2, "n-gram length")
+	window := fs.Int("window", 0, "co-occurrence window in words, 0 for the whole verse")
+	var words tagList
+	fs.Var(&words, "word", "only pairs with this word, repeated or comma separated")
+	dense := fs.Bool("dense", false, "write the co-occurrences as a full matrix")
+	translation := fs.Int("t", 0, "count this translation resource rather than the Arabic")
+	minCount := fs.Int("min", 2, "leave out results occurring fewer times")
+	limit := fs.Int("limit", 0, "number of results, 0 for all")
+	out := fs.String("o", "", "output file (defaults to stdout)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w\n%s", err, ngramsUsage)
+	}
+	opts := TextStatsOptions{
+		N:           *n,
+		Window:      *window,
+		Words:       words,
+		Translation: *translation,
+		MinCount:    *minCount,
+		Limit:       *limit,
+	}
+	for _, arg := range fs.Args() {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid chapter %q: %w", arg, err)
+		}
+		opts.Chapters = append(opts.Chapters, id)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if cooccur {
+		pairs, err := q.Cooccurrences(ctx, opts)
+		if err != nil {
+			return err
+		}
+		return WriteCooccurrenceCSV(w, pairs, *dense)
+	}
+	ngrams, err := q.NGrams(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return WriteNGramsCSV(w, ngrams)
+}
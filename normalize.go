@@ -0,0 +1,84 @@
+package quranapi
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeArabic folds Arabic text for matching: diacritics, Quranic
+// annotation signs and tatweel are dropped, and the alef and alef maqsura
+// variants are folded to bare alef and ya.
+func normalizeArabic(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r, ok := foldArabic(r); ok {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// foldArabic folds a single rune as normalizeArabic does, reporting false
+// for runes that are dropped.
+func foldArabic(r rune) (rune, bool) {
+	switch {
+	case unicode.Is(unicode.Mn, r), r == 'ـ', r == 'ۥ', r == 'ۦ':
+		return 0, false
+	case r == 'ٱ', r == 'أ', r == 'إ', r == 'آ':
+		return 'ا', true
+	case r == 'ى':
+		return 'ي', true
+	}
+	return r, true
+}
+
+// tokenize splits text into normalized, lower cased words.
+func tokenize(text string) []string {
+	spans := tokenSpans(text)
+	out := make([]string, len(spans))
+	for i, s := range spans {
+		out[i] = s.term
+	}
+	return out
+}
+
+// tokenSpan is a word of a text with its normalized form and its byte
+// range in the original text. The range covers any diacritics within or
+// trailing the word, so highlighting it never splits a letter from its
+// marks.
+type tokenSpan struct {
+	term       string
+	start, end int
+}
+
+func tokenSpans(text string) []tokenSpan {
+	var (
+		out  []tokenSpan
+		term strings.Builder
+		cur  = -1 // start of the current word, or -1 between words
+	)
+	flush := func(end int) {
+		if cur >= 0 {
+			out = append(out, tokenSpan{term: term.String(), start: cur, end: end})
+			term.Reset()
+			cur = -1
+		}
+	}
+	for i, r := range text {
+		folded, ok := foldArabic(r)
+		switch {
+		case !ok:
+			// dropped marks belong to the word they follow
+		case unicode.IsLetter(folded) || unicode.IsDigit(folded):
+			if cur < 0 {
+				cur = i
+			}
+			term.WriteString(strings.ToLower(string(folded)))
+		default:
+			flush(i)
+		}
+	}
+	flush(len(text))
+	return out
+}
@@ -0,0 +1,308 @@
+package quranapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const bucketNotes = "notes"
+
+// ErrNoteNotFound is returned for note IDs with no stored note.
+var ErrNoteNotFound = errors.New("note not found")
+
+// Note is a Markdown note on a verse. A verse may have any number of
+// notes, each kept under its own ID.
+type Note struct {
+	ID        string    `json:"id"`
+	VerseKey  VerseKey  `json:"verse_key"`
+	Text      string    `json:"text"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HasTag reports whether n is tagged tag, ignoring case and a leading #.
+func (n Note) HasTag(tag string) bool {
+	return slices.Contains(n.Tags, normalizeTag(tag))
+}
+
+// normalizeTag returns tag in lower case without a leading #.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// normalizeTags returns tags normalized, without empty or repeated tags.
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, t := range tags {
+		if t = normalizeTag(t); t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// NoteFilter selects notes. Zero fields match any note; Tags match notes
+// with every one of them.
+type NoteFilter struct {
+	VerseKey string
+	Tags     []string
+	// Text matches notes containing it, ignoring case.
+	Text string
+}
+
+func (f NoteFilter) match(n Note) bool {
+	for _, t := range f.Tags {
+		if !n.HasTag(t) {
+			return false
+		}
+	}
+	return (f.VerseKey == "" || n.VerseKey.String() == f.VerseKey) &&
+		(f.Text == "" || strings.Contains(strings.ToLower(n.Text), strings.ToLower(f.Text)))
+}
+
+// AddNote stores a new note on the verse with the given key.
+func (q *QuranService) AddNote(ctx context.Context, key, text string, tags ...string) (Note, error) {
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return Note{}, err
+	}
+	if strings.TrimSpace(text) == "" {
+		return Note{}, errors.New("note has no text")
+	}
+	now := time.Now().UTC()
+	n := Note{
+		ID:        randomID(),
+		VerseKey:  NewVerseKey(chapter, verse),
+		Text:      text,
+		Tags:      normalizeTags(tags),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return n, q.putValue(ctx, bucketNotes, n.ID, n)
+}
+
+// GetNote returns the note with the given ID.
+func (q *QuranService) GetNote(ctx context.Context, id string) (Note, error) {
+	var n Note
+	err := q.getValue(ctx, bucketNotes, id, &n)
+	if errors.Is(err, ErrKeyNotFound) {
+		return Note{}, fmt.Errorf("%w: %s", ErrNoteNotFound, id)
+	}
+	return n, err
+}
+
+// UpdateNote replaces the text and tags of the note with the given ID.
+func (q *QuranService) UpdateNote(ctx context.Context, id, text string, tags ...string) (Note, error) {
+	if strings.TrimSpace(text) == "" {
+		return Note{}, errors.New("note has no text")
+	}
+	n, err := q.GetNote(ctx, id)
+	if err != nil {
+		return Note{}, err
+	}
+	n.Text, n.Tags, n.UpdatedAt = text, normalizeTags(tags), time.Now().UTC()
+	return n, q.putValue(ctx, bucketNotes, n.ID, n)
+}
+
+// DeleteNote moves the note with the given ID to the trash.
+func (q *QuranService) DeleteNote(ctx context.Context, id string) error {
+	err := q.softDelete(ctx, bucketNotes, id)
+	if errors.Is(err, ErrKeyNotFound) {
+		return fmt.Errorf("%w: %s", ErrNoteNotFound, id)
+	}
+	return err
+}
+
+// ListNotes returns the notes matching filter in mushaf order, the notes
+// on a verse oldest first.
+func (q *QuranService) ListNotes(ctx context.Context, filter NoteFilter) ([]Note, error) {
+	if filter.VerseKey != "" {
+		chapter, verse, err := ValidateVerseKey(filter.VerseKey)
+		if err != nil {
+			return nil, err
+		}
+		filter.VerseKey = verseKey(chapter, verse)
+	}
+	out := []Note{}
+	err := q.iterate(ctx, bucketNotes, func(key string, value []byte) error {
+		var n Note
+		if err := valueDecode(value, &n); err != nil {
+			return err
+		}
+		if filter.match(n) {
+			out = append(out, n)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if c := out[i].VerseKey.Compare(out[j].VerseKey); c != 0 {
+			return c < 0
+		}
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+	return out, nil
+}
+
+// NoteTags returns how many notes have each tag.
+func (q *QuranService) NoteTags(ctx context.Context) (map[string]int, error) {
+	notes, err := q.ListNotes(ctx, NoteFilter{})
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]int)
+	for _, n := range notes {
+		for _, t := range n.Tags {
+			tags[t]++
+		}
+	}
+	return tags, nil
+}
+
+// Note export formats.
+const (
+	NotesMarkdown = "markdown"
+	NotesJSON     = "json"
+)
+
+// ExportNotes writes the notes matching filter to w, as JSON or as a
+// Markdown document with a section for each verse.
+func (q *QuranService) ExportNotes(ctx context.Context, w io.Writer, filter NoteFilter, format string) error {
+	notes, err := q.ListNotes(ctx, filter)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case NotesJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(notes)
+	case NotesMarkdown, "":
+		var last VerseKey
+		for _, n := range notes {
+			if n.VerseKey != last {
+				if _, err := fmt.Fprintf(w, "## %s\n\n", n.VerseKey); err != nil {
+					return err
+				}
+				last = n.VerseKey
+			}
+			meta := n.UpdatedAt.Format(time.DateOnly)
+			for _, t := range n.Tags {
+				meta += " #" + t
+			}
+			// the note is Markdown already, so it is written as is
+			if _, err := fmt.Fprintf(w, "%s\n\n*%s*\n\n", strings.TrimSpace(n.Text), meta); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported notes format %q: want markdown or json", format)
+	}
+}
+
+// tagList collects repeated -tag flags.
+type tagList []string
+
+func (t *tagList) String() string { return strings.Join(*t, ",") }
+
+func (t *tagList) Set(s string) error {
+	*t = append(*t, strings.Split(s, ",")...)
+	return nil
+}
+
+const notesUsage = `usage: notes add [-tag t]... <verse> <text> | edit [-tag t]... <id> <text> | rm <id>
+       notes list [-verse k] [-tag t]... [-text s] | tags
+       notes export [-format markdown|json] [-verse k] [-tag t]... [-o file]`
+
+func runNotes(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) == 0 {
+		return errors.New(notesUsage)
+	}
+
+	fs := flag.NewFlagSet("notes "+args[0], flag.ContinueOnError)
+	var tags tagList
+	fs.Var(&tags, "tag", "tag, repeated or comma separated")
+	verse := fs.String("verse", "", "only notes on this verse")
+	text := fs.String("text", "", "only notes containing this text")
+	format := fs.String("format", NotesMarkdown, "markdown or json")
+	out := fs.String("o", "", "output file (defaults to stdout)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	filter := NoteFilter{VerseKey: *verse, Tags: tags, Text: *text}
+
+	switch args[0] {
+	case "add", "edit":
+		if fs.NArg() < 2 {
+			return errors.New(notesUsage)
+		}
+		body := strings.Join(fs.Args()[1:], " ")
+		var n Note
+		var err error
+		if args[0] == "add" {
+			n, err = q.AddNote(ctx, fs.Arg(0), body, tags...)
+		} else {
+			n, err = q.UpdateNote(ctx, fs.Arg(0), body, tags...)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(n.ID)
+		return nil
+	case "rm":
+		if fs.NArg() != 1 {
+			return errors.New(notesUsage)
+		}
+		return q.DeleteNote(ctx, fs.Arg(0))
+	case "list":
+		notes, err := q.ListNotes(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, n := range notes {
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", n.ID, n.VerseKey, n.UpdatedAt.Format(time.DateOnly),
+				strings.Join(n.Tags, ","), strings.Join(strings.Fields(n.Text), " "))
+		}
+		return nil
+	case "tags":
+		counts, err := q.NoteTags(ctx)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(counts))
+		for t := range counts {
+			names = append(names, t)
+		}
+		sort.Strings(names)
+		for _, t := range names {
+			fmt.Printf("%s\t%d\n", t, counts[t])
+		}
+		return nil
+	case "export":
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		return q.ExportNotes(ctx, w, filter, *format)
+	default:
+		return fmt.Errorf("unknown notes command: %q", args[0])
+	}
+}
@@ -0,0 +1,195 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// BasmalahNumbering is whether the basmalah opening a chapter is numbered
+// as a verse of its own. Verses are otherwise always divided as in the Kufan
+// count followed by upstream; the other traditional counts, such as the
+// Basri, Shami and Madani, divide verses differently and aren't supported.
+type BasmalahNumbering string
+
+const (
+	// BasmalahUnnumbered numbers the basmalah only as the first verse of
+	// Al-Fatihah, as upstream and most printed mushafs do: 6236 verses.
+	BasmalahUnnumbered BasmalahNumbering = "unnumbered"
+	// BasmalahNumbered also numbers the basmalah opening every other
+	// chapter but At-Tawbah, which has none, as its verse 1, so that each
+	// following verse is numbered one more than in BasmalahUnnumbered.
+	BasmalahNumbered BasmalahNumbering = "numbered"
+)
+
+// ErrUnnumbered is returned converting a verse that has no number in the
+// target numbering, such as the basmalah of Al-Baqarah into
+// BasmalahUnnumbered.
+var ErrUnnumbered = errors.New("verse has no number in this numbering")
+
+// ParseBasmalahNumbering returns the numbering named s; an empty s is
+// BasmalahUnnumbered.
+func ParseBasmalahNumbering(s string) (BasmalahNumbering, error) {
+	n := BasmalahNumbering(strings.ToLower(strings.TrimSpace(s)))
+	if n == "" {
+		return BasmalahUnnumbered, nil
+	}
+	return n, n.Validate()
+}
+
+func (n BasmalahNumbering) Validate() error {
+	switch n {
+	case BasmalahUnnumbered, BasmalahNumbered:
+		return nil
+	}
+	return fmt.Errorf("invalid basmalah numbering %q: want unnumbered or numbered", n)
+}
+
+// numbersBasmalah reports whether n numbers the basmalah above chapter as
+// a verse of its own.
+func (n BasmalahNumbering) numbersBasmalah(chapter int) bool {
+	return n == BasmalahNumbered && chapter != 1 && chapter != 9
+}
+
+// VerseCount returns the number of verses of chapter in n.
+func (n BasmalahNumbering) VerseCount(chapter int) (int, error) {
+	if err := n.Validate(); err != nil {
+		return 0, err
+	}
+	if err := ValidateChapter(chapter); err != nil {
+		return 0, err
+	}
+	count := chapterVerseCounts[chapter-1]
+	if n.numbersBasmalah(chapter) {
+		count++
+	}
+	return count, nil
+}
+
+// ConvertVerseKey returns the key, in numbering to, of the verse numbered
+// key in numbering from.
+func ConvertVerseKey(key VerseKey, from, to BasmalahNumbering) (VerseKey, error) {
+	chapter, verse, err := key.Split()
+	if err != nil {
+		return "", err
+	}
+	count, err := from.VerseCount(chapter)
+	if err != nil {
+		return "", err
+	}
+	if err := to.Validate(); err != nil {
+		return "", err
+	}
+	if verse < 1 || verse > count {
+		return "", fmt.Errorf("%w: %s, chapter %d has %d verses with the basmalah %s", ErrVerseNotFound, key, chapter, count, from)
+	}
+
+	if from.numbersBasmalah(chapter) {
+		if verse == 1 && !to.numbersBasmalah(chapter) {
+			return "", fmt.Errorf("%w: %s is the basmalah", ErrUnnumbered, key)
+		}
+		verse--
+	}
+	if to.numbersBasmalah(chapter) {
+		verse++
+	}
+	return NewVerseKey(chapter, verse), nil
+}
+
+// WithBasmalahNumbering returns the verses numbered in n rather than as
+// they are stored, in BasmalahUnnumbered. With BasmalahNumbered, the
+// basmalah is returned as verse 1 of the chapters it opens, with the words
+// and translations of the basmalah of Al-Fatihah.
+func WithBasmalahNumbering(n BasmalahNumbering) ChapterOption {
+	return func(o *chapterOptions) {
+		o.numbering = n
+	}
+}
+
+// renumber returns verses, numbered in BasmalahUnnumbered, numbered in n.
+func (q *QuranService) renumber(ctx context.Context, chapter Chapter, verses []Verse, n BasmalahNumbering) ([]Verse, error) {
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	if !n.numbersBasmalah(chapter.ID) || len(verses) == 0 {
+		return verses, nil
+	}
+	fatihah, err := q.GetChapter(ctx, 1)
+	if err != nil {
+		return nil, fmt.Errorf("basmalah: %w", err)
+	}
+	if len(fatihah.Verses) == 0 {
+		return nil, fmt.Errorf("basmalah: %w: 1:1", ErrVerseNotFound)
+	}
+
+	first := verses[0]
+	b := fatihah.Verses[0]
+	b.ID = 0
+	b.ChapterID = chapter.ID
+	b.JuzNumber, b.HizbNumber, b.RubNumber = first.JuzNumber, first.HizbNumber, first.RubNumber
+	b.PageNumber = first.PageNumber
+	b.Sajdah, b.SajdahNumber = "", 0
+	b.Audio.URL, b.Audio.Duration, b.Audio.Segments, b.Audio.Format = "", 0, nil, ""
+	b.MediaContents = nil
+	b.Words = append([]Word(nil), b.Words...)
+	for i := range b.Words {
+		w := &b.Words[i]
+		w.ID, w.LineNumber, w.PageNumber = 0, 0, first.PageNumber
+		w.Code, w.CodeV3 = "", ""
+		w.Audio.URL = ""
+	}
+
+	out := make([]Verse, 0, len(verses)+1)
+	out = append(out, b)
+	out = append(out, verses...)
+	for i := range out {
+		v := &out[i]
+		v.VerseNumber = i + 1
+		v.VerseKey = NewVerseKey(chapter.ID, i+1)
+		if i > 0 {
+			v.Words = append([]Word(nil), v.Words...)
+		}
+		for j := range v.Words {
+			w := &v.Words[j]
+			w.VerseKey = v.VerseKey
+			if w.CharType == "end" {
+				w.TextMadani = arabicDigits(v.VerseNumber)
+			}
+		}
+	}
+	return out, nil
+}
+
+func runNumbering(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("numbering", flag.ContinueOnError)
+	from := fs.String("from", "unnumbered", "basmalah numbering of the keys: unnumbered or numbered")
+	to := fs.String("to", "numbered", "basmalah numbering to convert to: unnumbered or numbered")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: numbering [-from n] [-to n] <verse key>...")
+	}
+	fromN, err := ParseBasmalahNumbering(*from)
+	if err != nil {
+		return err
+	}
+	toN, err := ParseBasmalahNumbering(*to)
+	if err != nil {
+		return err
+	}
+	for _, arg := range fs.Args() {
+		key, err := ConvertVerseKey(VerseKey(strings.TrimSpace(arg)), fromN, toN)
+		if errors.Is(err, ErrUnnumbered) {
+			fmt.Printf("%s\t-\n", arg)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\t%s\n", arg, key)
+	}
+	return nil
+}
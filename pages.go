@@ -0,0 +1,52 @@
+package quranapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// MushafPages is the page count of the standard Madani mushaf the upstream
+// page numbers refer to.
+const MushafPages = 604
+
+func validPage(n int) error {
+	if n < 1 || n > MushafPages {
+		return fmt.Errorf("%w: %d", ErrPageNotFound, n)
+	}
+	return nil
+}
+
+// NextPage returns the page after current, or ErrPageNotFound on the last
+// page.
+func NextPage(current int) (int, error) {
+	if err := validPage(current + 1); err != nil {
+		return 0, err
+	}
+	return current + 1, nil
+}
+
+// PrevPage returns the page before current, or ErrPageNotFound on the
+// first page.
+func PrevPage(current int) (int, error) {
+	if err := validPage(current - 1); err != nil {
+		return 0, err
+	}
+	return current - 1, nil
+}
+
+// PageOfVerse returns the mushaf page the verse with the given key is on.
+func (q *QuranService) PageOfVerse(ctx context.Context, key string) (int, error) {
+	verse, err := q.GetVerse(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return verse.PageNumber, nil
+}
+
+// Page returns the verses printed on mushaf page n, in order.
+func (q *QuranService) Page(ctx context.Context, n int) ([]Verse, error) {
+	if err := validPage(n); err != nil {
+		return nil, err
+	}
+	return q.ScopeVerses(ctx, PageScope(n))
+}
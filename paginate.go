@@ -0,0 +1,101 @@
+package quranapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// versesPerPage is the most verses upstream returns per request.
+const versesPerPage = 50
+
+// Paging selects how paged upstream lists are requested.
+type Paging int
+
+const (
+	// PagingOffset requests each page by the number of results
+	// already fetched, with offset and limit, as the v3 API does.
+	PagingOffset Paging = iota
+	// PagingPage requests pages by number, counted from 1, with page
+	// and per_page, as the v4 API does.
+	PagingPage
+)
+
+// WithPaging sets how paged lists are requested from upstream, for
+// base URLs serving another API version. It defaults to PagingOffset.
+func WithPaging(p Paging) Option {
+	return func(q *QuranService) {
+		q.paging = p
+	}
+}
+
+// APIVersion is the version of the quran.com API at the base URL.
+type APIVersion int
+
+const (
+	APIv3 APIVersion = 3
+	APIv4 APIVersion = 4
+)
+
+// WithAPIVersion sets the version of the API at the base URL, APIv3 by
+// default. APIv4 pages by number, as WithPaging(PagingPage) does, and
+// serves the editions of the text fetched separately from chapters, such
+// as ScriptImlaei, which APIv3 lacks.
+func WithAPIVersion(v APIVersion) Option {
+	return func(q *QuranService) {
+		q.apiVersion = v
+		if v == APIv4 {
+			q.paging = PagingPage
+		}
+	}
+}
+
+// queryParam is a query parameter of a paged request.
+type queryParam struct {
+	name, value string
+}
+
+// params returns the query parameters of the page after fetched results,
+// the pageth request counted from 0, of size results.
+func (p Paging) params(page, fetched, size int) []queryParam {
+	if p == PagingPage {
+		return []queryParam{
+			{"page", strconv.Itoa(page + 1)},
+			{"per_page", strconv.Itoa(size)},
+		}
+	}
+	return []queryParam{
+		{"offset", strconv.Itoa(fetched)},
+		{"limit", strconv.Itoa(size)},
+	}
+}
+
+// paginate fetches pages of size results until a short or empty page, or
+// until total results when total is known. key identifies results, so that
+// a page repeating earlier ones, as when upstream ignores the paging
+// parameters, ends the fetch with an error rather than looping or
+// duplicating them.
+func paginate[T any](ctx context.Context, p Paging, size, total int, key func(T) int, fetch func(context.Context, []queryParam) ([]T, error)) ([]T, error) {
+	out := make([]T, 0, max(total, 0))
+	seen := make(map[int]bool, max(total, 0))
+	for page := 0; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		batch, err := fetch(ctx, p.params(page, len(out), size))
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range batch {
+			k := key(v)
+			if seen[k] {
+				return nil, fmt.Errorf("page %d repeats result %d: upstream ignored the paging parameters", page+1, k)
+			}
+			seen[k] = true
+			out = append(out, v)
+		}
+		if len(batch) < size || total > 0 && len(out) >= total {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// fakePages serves n results, numbered from 1, honoring the parameters of
+// both paging modes unless ignoreParams is set.
+type fakePages struct {
+	n            int
+	ignoreParams bool
+	requests     int
+}
+
+func (f *fakePages) fetch(_ context.Context, params []queryParam) ([]int, error) {
+	f.requests++
+	values := make(map[string]int)
+	for _, p := range params {
+		v, err := strconv.Atoi(p.value)
+		if err != nil {
+			return nil, err
+		}
+		values[p.name] = v
+	}
+
+	start, size := values["offset"], values["limit"]
+	if page, ok := values["page"]; ok {
+		size = values["per_page"]
+		start = (page - 1) * size
+	}
+	if f.ignoreParams {
+		start = 0
+	}
+	var out []int
+	for i := start + 1; i <= f.n && len(out) < size; i++ {
+		out = append(out, i)
+	}
+	return out, nil
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name     string
+		n, total int
+		requests int
+	}{
+		{"empty", 0, 0, 1},
+		{"short page", 7, 7, 1},
+		{"exactly one page", 50, 50, 1},
+		{"exactly one page, total unknown", 50, 0, 2},
+		{"one over a page", 51, 51, 2},
+		{"al-baqarah", 286, 286, 6},
+		{"al-baqarah, total unknown", 286, 0, 6},
+		{"exact pages, total unknown", 100, 0, 3},
+	}
+	for _, mode := range []Paging{PagingOffset, PagingPage} {
+		for _, tt := range tests {
+			t.Run(strconv.Itoa(int(mode))+"/"+tt.name, func(t *testing.T) {
+				f := &fakePages{n: tt.n}
+				got, err := paginate(context.Background(), mode, versesPerPage, tt.total,
+					func(v int) int { return v }, f.fetch)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(got) != tt.n {
+					t.Fatalf("got %d results, want %d", len(got), tt.n)
+				}
+				for i, v := range got {
+					if v != i+1 {
+						t.Fatalf("result %d is %d, want %d", i, v, i+1)
+					}
+				}
+				if f.requests != tt.requests {
+					t.Errorf("made %d requests, want %d", f.requests, tt.requests)
+				}
+			})
+		}
+	}
+}
+
+func TestPaginateRepeatedPage(t *testing.T) {
+	f := &fakePages{n: 120, ignoreParams: true}
+	_, err := paginate(context.Background(), PagingOffset, versesPerPage, 120,
+		func(v int) int { return v }, f.fetch)
+	if err == nil {
+		t.Fatal("want an error for a repeated page")
+	}
+	if f.requests != 2 {
+		t.Errorf("made %d requests, want 2", f.requests)
+	}
+}
+
+func TestPaginateError(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	calls := 0
+	_, err := paginate(context.Background(), PagingOffset, versesPerPage, 0,
+		func(v int) int { return v },
+		func(context.Context, []queryParam) ([]int, error) {
+			calls++
+			if calls == 2 {
+				return nil, errFetch
+			}
+			page := make([]int, versesPerPage)
+			for i := range page {
+				page[i] = i + 1
+			}
+			return page, nil
+		})
+	if !errors.Is(err, errFetch) {
+		t.Fatalf("got %v, want %v", err, errFetch)
+	}
+}
@@ -0,0 +1,92 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/alilmtech/quranapi/tokenizer"
+)
+
+// PauseRule is the stopping rule of a pause mark (waqf sign).
+type PauseRule string
+
+const (
+	// PauseMandatory (ۘ, lazim) must be stopped at, as continuing would
+	// change the meaning.
+	PauseMandatory PauseRule = "mandatory"
+	// PauseProhibited (ۙ, la) must not be stopped at.
+	PauseProhibited PauseRule = "prohibited"
+	// PausePermissible (ۚ, ja'iz) may be stopped at or continued through
+	// equally.
+	PausePermissible PauseRule = "permissible"
+	// PauseStopPreferred (ۗ, qili) may be continued through, though
+	// stopping is better.
+	PauseStopPreferred PauseRule = "stop_preferred"
+	// PauseContinuePreferred (ۖ, sili) may be stopped at, though
+	// continuing is better.
+	PauseContinuePreferred PauseRule = "continue_preferred"
+	// PauseEmbraced (ۛ, mu'anaqah) comes in pairs; the reciter stops at
+	// one of the two but not both.
+	PauseEmbraced PauseRule = "embraced"
+	// PauseSaktah (ۜ) is a brief silence without taking a breath.
+	PauseSaktah PauseRule = "saktah"
+)
+
+var pauseRules = map[rune]PauseRule{
+	'ۖ': PauseContinuePreferred,
+	'ۗ': PauseStopPreferred,
+	'ۘ': PauseMandatory,
+	'ۙ': PauseProhibited,
+	'ۚ': PausePermissible,
+	'ۛ': PauseEmbraced,
+	'ۜ': PauseSaktah,
+}
+
+// PauseMark is a pause mark in the Uthmani text of a verse.
+type PauseMark struct {
+	Mark string    `json:"mark"`
+	Rule PauseRule `json:"rule"`
+	// Position is the Word.Position of the word the mark follows.
+	Position int `json:"position"`
+	// Offset is the byte offset of the mark in Verse.TextMadani.
+	Offset int `json:"offset"`
+}
+
+// PauseMarks returns the pause marks of the verse's Uthmani text, in text
+// order, with the words they follow.
+func (v Verse) PauseMarks() []PauseMark {
+	marks := []PauseMark{}
+	for _, t := range tokenizer.Tokenize(v.TextMadani) {
+		if t.Kind != tokenizer.KindPause {
+			continue
+		}
+		r, _ := utf8.DecodeRuneInString(t.Text)
+		marks = append(marks, PauseMark{Mark: t.Text, Rule: pauseRules[r], Position: t.Position, Offset: t.Start})
+	}
+	return marks
+}
+
+// VersePauseMarks returns the pause marks of the verse with the given key.
+func (q *QuranService) VersePauseMarks(ctx context.Context, key string) ([]PauseMark, error) {
+	v, err := q.GetVerse(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return v.PauseMarks(), nil
+}
+
+func runPauseMarks(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: pauses <verse key>")
+	}
+	marks, err := q.VersePauseMarks(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	for _, m := range marks {
+		fmt.Printf("%d\t%s\t%s\n", m.Position, m.Mark, m.Rule)
+	}
+	return nil
+}
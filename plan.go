@@ -0,0 +1,277 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const bucketPlans = "plans"
+
+// ErrNotInPlan is returned by TodayPortion for dates before a plan starts
+// or after it ends.
+var ErrNotInPlan = errors.New("date outside reading plan")
+
+type PlanUnit string
+
+const (
+	PlanByPages  PlanUnit = "pages"
+	PlanByJuz    PlanUnit = "juz"
+	PlanByVerses PlanUnit = "verses"
+)
+
+// total returns the number of units in the mushaf.
+func (u PlanUnit) total() (int, error) {
+	switch u {
+	case PlanByPages, "":
+		return MushafPages, nil
+	case PlanByJuz:
+		return 30, nil
+	case PlanByVerses:
+		return VerseCount, nil
+	default:
+		return 0, fmt.Errorf("unknown plan unit %q", u)
+	}
+}
+
+type PlanOptions struct {
+	Name string
+	// Unit is what the mushaf is divided by; it defaults to pages.
+	Unit PlanUnit
+	// Start is the date of the first portion; it defaults to today.
+	Start time.Time
+}
+
+// Plan is a reading schedule completing the mushaf in Days days.
+type Plan struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name,omitempty"`
+	Unit     PlanUnit  `json:"unit"`
+	Start    time.Time `json:"start"`
+	Portions []Portion `json:"portions"`
+}
+
+// Portion is the reading for one day of a plan. First and Last are page
+// numbers, juz numbers or verse ordinals (1-6236) depending on Unit.
+type Portion struct {
+	Day   int      `json:"day"`
+	Unit  PlanUnit `json:"unit"`
+	First int      `json:"first"`
+	Last  int      `json:"last"`
+}
+
+func (p Portion) String() string {
+	switch p.Unit {
+	case PlanByJuz:
+		if p.First == p.Last {
+			return fmt.Sprintf("juz %d", p.First)
+		}
+		return fmt.Sprintf("juz %d-%d", p.First, p.Last)
+	case PlanByVerses:
+		return verseKey(verseAt(p.First)) + " to " + verseKey(verseAt(p.Last))
+	default:
+		if p.First == p.Last {
+			return fmt.Sprintf("page %d", p.First)
+		}
+		return fmt.Sprintf("pages %d-%d", p.First, p.Last)
+	}
+}
+
+// Scopes returns the portion as scopes accepted by ScopeVerses.
+func (p Portion) Scopes() []Scope {
+	var out []Scope
+	switch p.Unit {
+	case PlanByJuz:
+		for n := p.First; n <= p.Last; n++ {
+			out = append(out, JuzScope(n))
+		}
+	case PlanByVerses:
+		fc, fv := verseAt(p.First)
+		lc, lv := verseAt(p.Last)
+		for c := fc; c <= lc; c++ {
+			s := ChapterScope(c)
+			if c == fc && fv > 1 {
+				s.FromVerse = fv
+			}
+			if c == lc && lv < chapterVerseCounts[c-1] {
+				s.FromVerse = max(s.FromVerse, 1)
+				s.ToVerse = lv
+			}
+			out = append(out, s)
+		}
+	default:
+		for n := p.First; n <= p.Last; n++ {
+			out = append(out, PageScope(n))
+		}
+	}
+	return out
+}
+
+// splitPlan divides total units into days portions as evenly as possible.
+func splitPlan(unit PlanUnit, total, days int) []Portion {
+	portions := make([]Portion, days)
+	for i := range portions {
+		portions[i] = Portion{
+			Day:   i + 1,
+			Unit:  unit,
+			First: i*total/days + 1,
+			Last:  (i + 1) * total / days,
+		}
+	}
+	return portions
+}
+
+// GeneratePlan splits the mushaf into days daily portions and stores the
+// plan. A 30 day plan by juz is the usual Ramadan khatm.
+func (q *QuranService) GeneratePlan(ctx context.Context, days int, opts PlanOptions) (Plan, error) {
+	if opts.Unit == "" {
+		opts.Unit = PlanByPages
+	}
+	total, err := opts.Unit.total()
+	if err != nil {
+		return Plan{}, err
+	}
+	if days < 1 || days > total {
+		return Plan{}, fmt.Errorf("a plan by %s takes 1 to %d days, got %d", opts.Unit, total, days)
+	}
+	if opts.Start.IsZero() {
+		opts.Start = time.Now()
+	}
+
+	plan := Plan{
+		ID:       randomID(),
+		Name:     opts.Name,
+		Unit:     opts.Unit,
+		Start:    civilDate(opts.Start),
+		Portions: splitPlan(opts.Unit, total, days),
+	}
+	return plan, q.putValue(ctx, bucketPlans, plan.ID, plan)
+}
+
+func (q *QuranService) GetPlan(ctx context.Context, id string) (Plan, error) {
+	var plan Plan
+	err := q.getValue(ctx, bucketPlans, id, &plan)
+	return plan, err
+}
+
+// Plans returns the stored plans, most recently started first.
+func (q *QuranService) Plans(ctx context.Context) ([]Plan, error) {
+	var out []Plan
+	err := q.iterate(ctx, bucketPlans, func(key string, value []byte) error {
+		var plan Plan
+		if err := valueDecode(value, &plan); err != nil {
+			return err
+		}
+		out = append(out, plan)
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Start.After(out[j].Start)
+	})
+	return out, err
+}
+
+// DeletePlan moves the plan to the trash.
+func (q *QuranService) DeletePlan(ctx context.Context, id string) error {
+	return q.softDelete(ctx, bucketPlans, id)
+}
+
+// TodayPortion returns the portion of the plan scheduled for date.
+func (q *QuranService) TodayPortion(ctx context.Context, planID string, date time.Time) (Portion, error) {
+	plan, err := q.GetPlan(ctx, planID)
+	if err != nil {
+		return Portion{}, err
+	}
+	return plan.portionOn(date)
+}
+
+func (p Plan) portionOn(date time.Time) (Portion, error) {
+	day := int(civilDate(date).Sub(p.Start).Hours()/24) + 1
+	if day < 1 || day > len(p.Portions) {
+		return Portion{}, fmt.Errorf("%w: %s is day %d of a %d day plan", ErrNotInPlan, date.Format(time.DateOnly), day, len(p.Portions))
+	}
+	return p.Portions[day-1], nil
+}
+
+// civilDate returns midnight UTC of the calendar date of t in its own
+// location, so that days are counted the same in every time zone.
+func civilDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+const planUsage = "usage: plan new [-days n] [-by pages|juz|verses] [-start yyyy-mm-dd] [-name name] | list | today <id> | show <id> | rm <id>"
+
+func runPlan(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) == 0 {
+		return errors.New(planUsage)
+	}
+
+	switch args[0] {
+	case "new":
+		fs := flag.NewFlagSet("plan new", flag.ContinueOnError)
+		days := fs.Int("days", 30, "number of days to complete the mushaf in")
+		by := fs.String("by", string(PlanByPages), "split by pages, juz or verses")
+		start := fs.String("start", "", "first day of the plan (defaults to today)")
+		name := fs.String("name", "", "plan name")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		opts := PlanOptions{Name: *name, Unit: PlanUnit(*by)}
+		if *start != "" {
+			t, err := time.Parse(time.DateOnly, *start)
+			if err != nil {
+				return err
+			}
+			opts.Start = t
+		}
+		plan, err := q.GeneratePlan(ctx, *days, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Println(plan.ID)
+		return nil
+	case "list":
+		plans, err := q.Plans(ctx)
+		if err != nil {
+			return err
+		}
+		for _, p := range plans {
+			fmt.Printf("%s\t%s\t%d days by %s from %s\n", p.ID, p.Name, len(p.Portions), p.Unit, p.Start.Format(time.DateOnly))
+		}
+		return nil
+	case "today":
+		if len(args) != 2 {
+			return errors.New(planUsage)
+		}
+		portion, err := q.TodayPortion(ctx, args[1], time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("day %d: %s\n", portion.Day, portion)
+		return nil
+	case "show":
+		if len(args) != 2 {
+			return errors.New(planUsage)
+		}
+		plan, err := q.GetPlan(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		for _, p := range plan.Portions {
+			date := plan.Start.AddDate(0, 0, p.Day-1)
+			fmt.Printf("%s\tday %d\t%s\n", date.Format(time.DateOnly), p.Day, p)
+		}
+		return nil
+	case "rm":
+		if len(args) != 2 {
+			return errors.New(planUsage)
+		}
+		return q.DeletePlan(ctx, args[1])
+	default:
+		return fmt.Errorf("unknown plan command: %q", args[0])
+	}
+}
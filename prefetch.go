@@ -0,0 +1,181 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// PrefetchSpec selects what Prefetch stores. Empty Chapters means every
+// chapter.
+type PrefetchSpec struct {
+	Chapters []int
+	// Translations are fetched along with those the service was created
+	// with; chapters already stored with all of them are skipped.
+	Translations []int
+	// Recitations selects the verse audio to store. Verses hold the audio
+	// of one recitation, so at most one may be given; it replaces the
+	// service's for the chapters fetched.
+	Recitations []int
+	// Concurrency defaults to 4.
+	Concurrency int
+}
+
+// PrefetchProgress is reported after each chapter.
+type PrefetchProgress struct {
+	Chapter int
+	Done    int
+	Total   int
+	// Cached is set when the chapter was already stored.
+	Cached bool
+	Bytes  int64
+	Err    error
+}
+
+// PrefetchReport summarizes a prefetch. Failed maps the chapters that
+// could not be fetched or stored to their error.
+type PrefetchReport struct {
+	Fetched int
+	Cached  int
+	Bytes   int64
+	Failed  map[int]error
+}
+
+// Prefetch warms the store with the chapters of spec so that they can be
+// read offline, calling progress, if not nil, after each chapter. Calls to
+// progress are serialized. Chapters that fail are reported rather than
+// ending the prefetch; cancelling ctx stops it with ctx's error.
+func (q *QuranService) Prefetch(ctx context.Context, spec PrefetchSpec, progress func(PrefetchProgress)) (PrefetchReport, error) {
+	report := PrefetchReport{Failed: make(map[int]error)}
+	if len(spec.Recitations) > 1 {
+		return report, fmt.Errorf("prefetch: verses hold one recitation, got %d", len(spec.Recitations))
+	}
+	chapters := spec.Chapters
+	if len(chapters) == 0 {
+		for id := 1; id <= 114; id++ {
+			chapters = append(chapters, id)
+		}
+	}
+	for _, id := range chapters {
+		if err := ValidateChapter(id); err != nil {
+			return report, err
+		}
+	}
+
+	// fetch through a copy so that the service's own settings are left
+	// alone
+	f := *q
+	f.translations = slices.Clone(q.translations)
+	for _, id := range spec.Translations {
+		if !slices.Contains(f.translations, id) {
+			f.translations = append(f.translations, id)
+		}
+	}
+	if len(spec.Recitations) == 1 {
+		f.recitation = spec.Recitations[0]
+	}
+
+	concurrency := spec.Concurrency
+	if concurrency < 1 {
+		concurrency = 4
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+	for _, id := range chapters {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(id int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			cached, n, err := f.prefetchChapter(ctx, id, len(spec.Recitations) == 1)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				report.Failed[id] = err
+			case cached:
+				report.Cached++
+			default:
+				report.Fetched++
+				report.Bytes += n
+			}
+			if progress != nil {
+				progress(PrefetchProgress{
+					Chapter: id,
+					Done:    report.Fetched + report.Cached + len(report.Failed),
+					Total:   len(chapters),
+					Cached:  cached,
+					Bytes:   n,
+					Err:     err,
+				})
+			}
+		}(id)
+	}
+	wg.Wait()
+	return report, ctx.Err()
+}
+
+// prefetchChapter stores chapter id unless it is already stored with the
+// service's translations, returning the encoded size stored.
+func (q *QuranService) prefetchChapter(ctx context.Context, id int, refetch bool) (cached bool, n int64, err error) {
+	if !refetch {
+		stored, err := q.getChapterDB(ctx, id)
+		if err == nil && hasTranslations(stored, q.translations) {
+			return true, 0, nil
+		}
+		if err != nil && !errors.Is(err, ErrCacheMiss) {
+			return false, 0, err
+		}
+	}
+
+	chapter, err := q.getChapter(ctx, id)
+	if err != nil {
+		return false, 0, err
+	}
+	q.transliterate(&chapter)
+	q.profile.strip(chapter.Verses)
+	if err := q.setChapterDB(ctx, chapter); err != nil {
+		return false, 0, err
+	}
+	q.uncacheChapter(id)
+
+	buf, err := valueEncoder(chapter)
+	if err != nil {
+		return false, 0, err
+	}
+	return false, int64(buf.Len()), nil
+}
+
+func hasTranslations(chapter Chapter, resourceIDs []int) bool {
+	if len(chapter.Verses) == 0 {
+		return false
+	}
+	for _, id := range resourceIDs {
+		found := false
+		for _, tr := range chapter.Verses[0].Translations {
+			if tr.ResourceID == id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
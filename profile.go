@@ -0,0 +1,51 @@
+package quranapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrWordsNotStored is returned by the word based APIs, such as page glyphs
+// and layouts, of services storing the lite profile.
+var ErrWordsNotStored = errors.New("words are not stored with the lite storage profile")
+
+// StorageProfile selects how much of each verse is stored.
+type StorageProfile string
+
+const (
+	// ProfileFull keeps verses as upstream serves them.
+	ProfileFull StorageProfile = "full"
+	// ProfileLite keeps the text and translations of verses, dropping
+	// their words, media contents and audio segments, for readers that
+	// only display text. It makes quran.db several times smaller and
+	// chapters quicker to decode.
+	ProfileLite StorageProfile = "lite"
+)
+
+func (p StorageProfile) Validate() error {
+	switch p {
+	case "", ProfileFull, ProfileLite:
+		return nil
+	}
+	return fmt.Errorf("invalid storage profile %q: want full or lite", string(p))
+}
+
+// WithStorageProfile sets the storage profile of chapters fetched from now
+// on; chapters already stored keep theirs until refreshed.
+func WithStorageProfile(p StorageProfile) Option {
+	return func(q *QuranService) {
+		q.profile = p
+	}
+}
+
+// strip drops from verses what the profile doesn't store.
+func (p StorageProfile) strip(verses []Verse) {
+	if p != ProfileLite {
+		return
+	}
+	for i := range verses {
+		verses[i].Words = nil
+		verses[i].MediaContents = nil
+		verses[i].Audio.Segments = nil
+	}
+}
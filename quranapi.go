@@ -0,0 +1,748 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jsteenb2/httpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ChapterSummary struct {
+	ID                  int    `json:"id"`
+	Number              int    `json:"chapter_number"`
+	BismallahPre        bool   `json:"bismillah_pre"`
+	RevelationOrder     int    `json:"revelation_order"`
+	RevelationPlace     string `json:"revelation_place"`
+	NameTransliteration string `json:"name_complex"`
+	NameArabic          string `json:"name_arabic"`
+	NameSimple          string `json:"name_simple"`
+	VerseCount          int    `json:"verses_count"`
+	Pages               [2]int `json:"pages"`
+	TranslatedName      struct {
+		LanguageName string `json:"language_name"`
+		Name         string `json:"name"`
+	} `json:"translated_name"`
+}
+
+func (c ChapterSummary) startPage() int {
+	return c.Pages[0]
+}
+
+func (c ChapterSummary) endPage() int {
+	return c.Pages[1]
+}
+
+type Chapter struct {
+	ID                  int    `json:"id"`
+	Number              int    `json:"chapter_number"`
+	BismallahPre        bool   `json:"bismillah_pre"`
+	RevelationOrder     int    `json:"revelation_order"`
+	RevelationPlace     string `json:"revelation_place"`
+	NameTransliteration string `json:"name_complex"`
+	NameArabic          string `json:"name_arabic"`
+	NameSimple          string `json:"name_simple"`
+	Pages               Pages  `json:"pages"`
+	TranslatedName      struct {
+		LanguageName string `json:"language_name"`
+		Name         string `json:"name"`
+	} `json:"translated_name"`
+	Verses []Verse `json:"verses"`
+}
+
+type Pages struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type Verse struct {
+	ID           int      `json:"id"`
+	VerseNumber  int      `json:"verse_number"`
+	ChapterID    int      `json:"chapter_id"`
+	VerseKey     VerseKey `json:"verse_key"`
+	TextMadani   string   `json:"text_madani"`
+	TextIndopak  string   `json:"text_indopak"`
+	TextSimple   string   `json:"text_simple"`
+	JuzNumber    int      `json:"juz_number"`
+	HizbNumber   int      `json:"hizb_number"`
+	RubNumber    int      `json:"rub_number"`
+	Sajdah       string   `json:"sajdah"`
+	SajdahNumber int      `json:"sajdah_number"`
+	PageNumber   int      `json:"page_number"`
+	Audio        struct {
+		URL      string     `json:"url"`
+		Duration int        `json:"duration"`
+		Segments [][]string `json:"segments"`
+		Format   string     `json:"format"`
+	} `json:"audio"`
+	Translations  []Translation  `json:"translations"`
+	MediaContents []MediaContent `json:"media_contents"`
+	Words         []Word         `json:"words"`
+	// Transliteration is the verse in Latin script, set by services created
+	// WithTransliteration.
+	Transliteration string `json:"transliteration"`
+	// TextImlaei and TextUthmaniTajweed are only set when requested
+	// WithScript.
+	TextImlaei         string `json:"text_imlaei,omitempty"`
+	TextUthmaniTajweed string `json:"text_uthmani_tajweed,omitempty"`
+	// Companions is related content from the registered companion
+	// providers, only set when requested WithCompanions.
+	Companions []Companion `json:"companions,omitempty"`
+}
+
+type Translation struct {
+	ID           int    `json:"id"`
+	LanguageName string `json:"language_name"`
+	Text         string `json:"text"`
+	ResourceName string `json:"resource_name"`
+	ResourceID   int    `json:"resource_id"`
+}
+
+type Word struct {
+	ID          int      `json:"id"`
+	Position    int      `json:"position"`
+	TextMadani  string   `json:"text_madani"`
+	TextIndopak string   `json:"text_indopak"`
+	TextSimple  string   `json:"text_simple"`
+	VerseKey    VerseKey `json:"verse_key"`
+	ClassName   string   `json:"class_name"`
+	LineNumber  int      `json:"line_number"`
+	PageNumber  int      `json:"page_number"`
+	Code        string   `json:"code"`
+	CodeV3      string   `json:"code_v3"`
+	CharType    string   `json:"char_type"`
+	Audio       struct {
+		URL string `json:"url"`
+	} `json:"audio"`
+	Translation     Translation `json:"translation"`
+	Transliteration struct {
+		LanguageName string `json:"language_name"`
+		Text         string `json:"text"`
+	} `json:"transliteration"`
+}
+
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+type QuranService struct {
+	httpClient *httpc.Client
+	// doer fetches what is not under baseURL, such as audio files.
+	doer  Doer
+	store Store
+
+	baseURL      string
+	translations []int
+	recitation   int
+	media        int
+
+	hooks  Hooks
+	logger *slog.Logger
+	tracer trace.Tracer
+
+	trashRetention  time.Duration
+	bareNumbers     BareNumberPolicy
+	transliteration bool
+	contentHash     func() hash.Hash
+	disabled        map[Feature]bool
+	refreshSchedule Schedule
+	readOnly        bool
+	profile         StorageProfile
+	paging          Paging
+	apiVersion      APIVersion
+	searchKind      SearchBackendKind
+	searchBackends  map[SearchBackendKind]SearchBackend
+	blevePath       string
+	audioDir        string
+	tts             TTS
+	asbabURL        string
+	hijriAdjustment int
+	transport       transportOptions
+
+	companions *companionProviders
+	syncMu     *sync.Mutex
+	dataUsers  *sync.Map
+	retryQueue *retryQueue
+	search     *searchIndex
+	elastic    *elasticIndex
+	events     *eventBus
+	webhooks   []Webhook
+
+	chapterCache *lru[Chapter]
+	verseCache   *lru[Verse]
+}
+
+type Option func(*QuranService)
+
+// WithStore sets the store backing the cache. Without it the service keeps
+// everything in memory.
+func WithStore(store Store) Option {
+	return func(q *QuranService) {
+		q.store = store
+	}
+}
+
+// WithTranslations includes the given translation resources with every
+// verse fetched from upstream.
+func WithTranslations(resourceIDs ...int) Option {
+	return func(q *QuranService) {
+		q.translations = resourceIDs
+	}
+}
+
+// WithBaseURL points the service at a different upstream API.
+func WithBaseURL(baseURL string) Option {
+	return func(q *QuranService) {
+		q.baseURL = baseURL
+	}
+}
+
+// WithRecitation includes audio for the given recitation with every verse
+// fetched from upstream.
+func WithRecitation(recitationID int) Option {
+	return func(q *QuranService) {
+		q.recitation = recitationID
+	}
+}
+
+// NewQuranService returns a service fetching from upstream with doer. A nil
+// doer uses an http.Client configured by WithProxy, WithTLSConfig,
+// WithCAFile and WithTimeout.
+func NewQuranService(doer Doer, opts ...Option) (*QuranService, error) {
+	svc := &QuranService{
+		baseURL: defaultBaseURL,
+		store:   NewMemoryStore(),
+		hooks:   NopHooks{},
+		logger:  slog.Default(),
+		tracer:  otel.Tracer(tracerName),
+		search:  newSearchIndex(),
+		events:  newEventBus(),
+
+		trashRetention: defaultTrashRetention,
+		companions:     &companionProviders{},
+		syncMu:         &sync.Mutex{},
+		dataUsers:      &sync.Map{},
+	}
+	svc.searchBackends = map[SearchBackendKind]SearchBackend{BackendBuiltin: builtinSearch{svc}}
+	for _, o := range opts {
+		o(svc)
+	}
+	doer, err := svc.newDoer(doer)
+	if err != nil {
+		return nil, err
+	}
+	svc.doer = doer
+	svc.httpClient = httpc.New(doer, httpc.WithBaseURL(svc.baseURL))
+	if svc.retryQueue != nil {
+		if err := svc.retryQueue.load(); err != nil {
+			return nil, fmt.Errorf("load cache write queue: %w", err)
+		}
+	}
+	if svc.elastic != nil {
+		svc.elastic.doer = doer
+	}
+	if err := svc.openSearchBackends(); err != nil {
+		return nil, err
+	}
+	svc.startWebhooks(doer)
+
+	return svc, nil
+}
+
+// GetChapter returns a chapter with its verses, from the memory cache, the
+// store or upstream in that order.
+func (q *QuranService) GetChapter(ctx context.Context, id int, opts ...ChapterOption) (_ Chapter, err error) {
+	if err := ValidateChapter(id); err != nil {
+		return Chapter{}, err
+	}
+
+	ctx, span := q.startSpan(ctx, "GetChapter", attribute.Int("chapter.id", id))
+	defer func() { endSpan(span, err) }()
+
+	cacheKey := "chapter/" + strconv.Itoa(id)
+	if q.chapterCache != nil {
+		chapter, ok := q.chapterCache.get(strconv.Itoa(id))
+		q.cacheEvent(ctx, CacheLayerMemory, cacheKey, ok)
+		if ok {
+			return q.applyChapterOptions(ctx, chapter, opts)
+		}
+	}
+
+	chapter, err := q.getChapterDB(ctx, id)
+	q.cacheEvent(ctx, CacheLayerStore, cacheKey, err == nil)
+	if err == nil {
+		q.transliterate(&chapter)
+		q.chapterCache.put(strconv.Itoa(id), chapter)
+		return q.applyChapterOptions(ctx, chapter, opts)
+	}
+
+	chapter, err = q.getChapter(ctx, id)
+	if err != nil {
+		return Chapter{}, err
+	}
+	q.transliterate(&chapter)
+	q.profile.strip(chapter.Verses)
+
+	q.cacheChapter(ctx, chapter)
+	q.chapterCache.put(strconv.Itoa(id), chapter)
+
+	return q.applyChapterOptions(ctx, chapter, opts)
+}
+
+// GetVerse returns a single verse by its "chapter:verse" key.
+func (q *QuranService) GetVerse(ctx context.Context, key string) (Verse, error) {
+	chapterID, verseNum, err := ValidateVerseKey(key)
+	if err != nil {
+		return Verse{}, err
+	}
+	key = verseKey(chapterID, verseNum)
+
+	if q.verseCache != nil {
+		verse, ok := q.verseCache.get(key)
+		q.cacheEvent(ctx, CacheLayerMemory, "verse/"+key, ok)
+		if ok {
+			q.hooks.VerseRead(ctx, key)
+			return verse, nil
+		}
+	}
+
+	verse, err := q.getVerseDB(ctx, key)
+	if err == nil {
+		q.cacheEvent(ctx, CacheLayerStore, "verse/"+key, true)
+		q.verseCache.put(key, verse)
+		q.hooks.VerseRead(ctx, key)
+		return verse, nil
+	}
+	indexMissing := errors.Is(err, ErrCacheMiss)
+	if !indexMissing {
+		q.log(ctx).Warn("verse index", "key", key, "err", err)
+	}
+
+	chapter, err := q.GetChapter(ctx, chapterID)
+	if err != nil {
+		return Verse{}, err
+	}
+	if indexMissing {
+		q.backfillVerseIndex(ctx, chapter)
+	}
+
+	for _, verse := range chapter.Verses {
+		if verse.VerseNumber == verseNum {
+			q.verseCache.put(key, verse)
+			q.hooks.VerseRead(ctx, key)
+			return verse, nil
+		}
+	}
+	return Verse{}, fmt.Errorf("%w: %s", ErrVerseNotFound, key)
+}
+
+// GetChapterSummary returns the summary of a single chapter, from the
+// stored summaries when available and upstream otherwise.
+func (q *QuranService) GetChapterSummary(ctx context.Context, id int) (ChapterSummary, error) {
+	if err := ValidateChapter(id); err != nil {
+		return ChapterSummary{}, err
+	}
+	return q.getChapterSummary(ctx, id)
+}
+
+// ChapterSummary returns the summary of a single chapter.
+//
+// Deprecated: use GetChapterSummary.
+func (q *QuranService) ChapterSummary(ctx context.Context, id int) (ChapterSummary, error) {
+	return q.GetChapterSummary(ctx, id)
+}
+
+// findSummary looks up chapter id, which normally sits at index id-1.
+func findSummary(chapters []ChapterSummary, id int) (ChapterSummary, bool) {
+	if i := id - 1; i >= 0 && i < len(chapters) && chapters[i].ID == id {
+		return chapters[i], true
+	}
+	for _, c := range chapters {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return ChapterSummary{}, false
+}
+
+func (q *QuranService) getChapterSummary(ctx context.Context, id int) (ChapterSummary, error) {
+	chapters, err := q.getSummaryDB(ctx)
+	if summary, ok := findSummary(chapters, id); ok {
+		return summary, nil
+	}
+
+	var chapter struct {
+		Summary ChapterSummary `json:"chapter"`
+	}
+	path := fmt.Sprintf("/chapters/%d", id)
+	ctx, span := q.startSpan(ctx, "GET "+path)
+	err = q.fetch(ctx, path, q.httpClient.Get(path), &chapter)
+	endSpan(span, err)
+	if isNotFound(err) {
+		return ChapterSummary{}, fmt.Errorf("%w: %d", ErrChapterNotFound, id)
+	}
+	if err != nil {
+		return ChapterSummary{}, err
+	}
+
+	return chapter.Summary, nil
+}
+
+func (q *QuranService) getChapter(ctx context.Context, id int) (_ Chapter, err error) {
+	ctx, span := q.startSpan(ctx, "getChapter", attribute.Int("chapter.id", id))
+	defer func() { endSpan(span, err) }()
+
+	chapter, err := q.getChapterSummary(ctx, id)
+	if err != nil {
+		return Chapter{}, err
+	}
+
+	translations := q.fetchedTranslations(ctx)
+	path := fmt.Sprintf("/chapters/%d/verses", id)
+	verses, err := paginate(ctx, q.paging, versesPerPage, chapter.VerseCount,
+		func(v Verse) int { return v.VerseNumber },
+		func(ctx context.Context, params []queryParam) ([]Verse, error) {
+			var versesResp struct {
+				Verses []Verse `json:"verses"`
+			}
+			req := q.httpClient.Get(path)
+			attrs := make([]attribute.KeyValue, 0, len(params))
+			for _, p := range params {
+				req = req.QueryParam(p.name, p.value)
+				attrs = append(attrs, attribute.String(p.name, p.value))
+			}
+			if len(translations) > 0 {
+				req = req.QueryParam("translations", joinInts(translations))
+			}
+			if q.recitation != 0 && q.FeatureEnabled(FeatureAudio) {
+				req = req.QueryParam("recitation", strconv.Itoa(q.recitation))
+			}
+			ctx, span := q.startSpan(ctx, "GET "+path, attrs...)
+			err := q.fetch(ctx, path, req, &versesResp)
+			endSpan(span, err)
+			return versesResp.Verses, err
+		})
+	if err != nil {
+		return Chapter{}, err
+	}
+
+	return newChapter(chapter, verses), nil
+}
+
+// newChapter returns the chapter summarized by s with its verses.
+func newChapter(s ChapterSummary, verses []Verse) Chapter {
+	return Chapter{
+		ID:                  s.ID,
+		Number:              s.Number,
+		BismallahPre:        s.BismallahPre,
+		RevelationOrder:     s.RevelationOrder,
+		RevelationPlace:     s.RevelationPlace,
+		NameArabic:          s.NameArabic,
+		NameSimple:          s.NameSimple,
+		NameTransliteration: s.NameTransliteration,
+		Pages: Pages{
+			Start: s.startPage(),
+			End:   s.endPage(),
+		},
+		TranslatedName: s.TranslatedName,
+		Verses:         verses,
+	}
+}
+
+// summary returns the summary of c, the inverse of newChapter.
+func (c Chapter) summary() ChapterSummary {
+	return ChapterSummary{
+		ID:                  c.ID,
+		Number:              c.Number,
+		BismallahPre:        c.BismallahPre,
+		RevelationOrder:     c.RevelationOrder,
+		RevelationPlace:     c.RevelationPlace,
+		NameTransliteration: c.NameTransliteration,
+		NameArabic:          c.NameArabic,
+		NameSimple:          c.NameSimple,
+		VerseCount:          len(c.Verses),
+		Pages:               [2]int{c.Pages.Start, c.Pages.End},
+		TranslatedName:      c.TranslatedName,
+	}
+}
+
+func (q *QuranService) ChaptersSummary(ctx context.Context) ([]ChapterSummary, error) {
+	summaries, err := q.getSummaryDB(ctx)
+	if err == nil {
+		return summaries, nil
+	}
+
+	summaries, err = q.getSummaryAPI(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := q.setSummaryDB(ctx, summaries); err != nil {
+		q.log(ctx).Error("cache chapter summaries", "err", err)
+	}
+
+	return summaries, nil
+}
+
+func (q *QuranService) getSummaryAPI(ctx context.Context) ([]ChapterSummary, error) {
+	var chapters struct {
+		Chapters []ChapterSummary `json:"chapters"`
+	}
+	err := q.fetch(ctx, "/chapters", q.httpClient.Get("/chapters"), &chapters)
+	return chapters.Chapters, err
+}
+
+// fetch sends req and decodes its JSON response into v. Failures are
+// returned as an *UpstreamError carrying the response status.
+func (q *QuranService) fetch(ctx context.Context, path string, req *httpc.Request, v interface{}) error {
+	var status int
+	start := time.Now()
+	err := req.
+		Success(func(code int) bool {
+			status = code
+			return code == http.StatusOK
+		}).
+		DecodeJSON(v).
+		Do(ctx)
+	q.logUpstream(ctx, path, start, err)
+	if err == nil {
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return &UpstreamError{Path: path, StatusCode: status, Err: err}
+}
+
+const (
+	bucketChapters  = "chapters"
+	bucketChecksums = "checksums"
+
+	keyChaptersSummary = "chapters_summary"
+)
+
+// uncacheChapter drops chapter id from the memory caches, so that its next
+// read comes from the store.
+func (q *QuranService) uncacheChapter(id int) {
+	q.chapterCache.remove(strconv.Itoa(id))
+	prefix := strconv.Itoa(id) + ":"
+	q.verseCache.removeFunc(func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+func (q *QuranService) deleteChapterDB(ctx context.Context, id int) error {
+	q.uncacheChapter(id)
+
+	// the stored chapter lists the index entries to remove
+	if err := q.unindexVerses(ctx, id); err != nil {
+		return err
+	}
+	if err := q.deleteValue(ctx, bucketChapters, strconv.Itoa(id)); err != nil {
+		return err
+	}
+	if err := q.deleteValue(ctx, bucketChecksums, strconv.Itoa(id)); err != nil {
+		return err
+	}
+	for _, s := range fetchedScripts {
+		if err := q.deleteValue(ctx, bucketScripts, scriptKey(s, id)); err != nil {
+			return err
+		}
+	}
+	for _, bucket := range []string{bucketWordAlignments, bucketAudio} {
+		if err := q.deleteChapterEntries(ctx, bucket, id); err != nil {
+			return err
+		}
+	}
+	if err := q.invalidateStats(ctx); err != nil {
+		return err
+	}
+	if err := q.unindexChapter(ctx, id); err != nil {
+		return err
+	}
+	q.emit(ctx, Event{Type: EventCacheInvalidated, Chapter: id})
+	return nil
+}
+
+// deleteChapterEntries drops the entries of chapter from a bucket keyed
+// "{variant}/{chapter}", such as the word alignments of each language.
+func (q *QuranService) deleteChapterEntries(ctx context.Context, bucket string, chapter int) error {
+	suffix := "/" + strconv.Itoa(chapter)
+	var keys []string
+	err := q.store.Iterate(ctx, bucket, func(key string, _ []byte) error {
+		if strings.HasSuffix(key, suffix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := q.deleteValue(ctx, bucket, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q *QuranService) getChapterDB(ctx context.Context, id int) (Chapter, error) {
+	var out Chapter
+	err := q.getValue(ctx, bucketChapters, strconv.Itoa(id), &out)
+	if errors.Is(err, ErrKeyNotFound) {
+		return out, fmt.Errorf("chapter %d: %w", id, ErrCacheMiss)
+	}
+	return out, err
+}
+
+func (q *QuranService) setChapterDB(ctx context.Context, chapter Chapter) error {
+	if err := q.putValue(ctx, bucketChapters, strconv.Itoa(chapter.ID), chapter); err != nil {
+		return err
+	}
+	if err := q.putRaw(ctx, bucketChecksums, strconv.Itoa(chapter.ID), []byte(chapter.Checksum())); err != nil {
+		return err
+	}
+	if err := q.recordTranslationHashes(ctx, chapter); err != nil {
+		return err
+	}
+	if err := q.invalidateStats(ctx); err != nil {
+		return err
+	}
+	if err := q.indexChapter(ctx, chapter); err != nil {
+		return err
+	}
+	if err := q.indexVerses(ctx, chapter); err != nil {
+		return err
+	}
+	q.emit(ctx, Event{Type: EventChapterSynced, Chapter: chapter.ID})
+	return nil
+}
+
+func (q *QuranService) getSummaryDB(ctx context.Context) ([]ChapterSummary, error) {
+	var out []ChapterSummary
+	err := q.getValue(ctx, bucketChapters, keyChaptersSummary, &out)
+
+	if len(out) != 114 {
+		return nil, fmt.Errorf("chapter summaries: %w", ErrCacheMiss)
+	}
+
+	return out, err
+}
+
+func (q *QuranService) setSummaryDB(ctx context.Context, chapters []ChapterSummary) error {
+	return q.putValue(ctx, bucketChapters, keyChaptersSummary, chapters)
+}
+
+func (q *QuranService) getValue(ctx context.Context, namespace, key string, v interface{}) error {
+	_, span := q.startSpan(ctx, "store.Get", storeAttrs(namespace, key)...)
+	b, err := q.store.Get(ctx, userNamespace(ctx, namespace), key)
+	if errors.Is(err, ErrKeyNotFound) {
+		// a miss is expected on a cold cache, not a failed span
+		span.SetAttributes(attribute.Bool("store.hit", false))
+		span.End()
+		return err
+	}
+	endSpan(span, err)
+	if err != nil {
+		return err
+	}
+	return valueDecode(b, v)
+}
+
+func (q *QuranService) putValue(ctx context.Context, namespace, key string, v interface{}) error {
+	buf, err := valueEncoder(v)
+	if err != nil {
+		return err
+	}
+	return q.putRaw(ctx, namespace, key, buf.Bytes())
+}
+
+func (q *QuranService) putRaw(ctx context.Context, namespace, key string, b []byte) error {
+	if q.readOnly {
+		return readOnlyWrite(namespace)
+	}
+	_, span := q.startSpan(ctx, "store.Put", storeAttrs(namespace, key)...)
+	err := q.store.Put(ctx, userNamespace(ctx, namespace), key, b)
+	endSpan(span, err)
+	if err == nil && isUserDataNamespace[namespace] {
+		err = q.registerDataUser(ctx)
+	}
+	if err == nil && slices.Contains(syncNamespaces, namespace) {
+		err = q.touchSyncRecord(ctx, namespace, key)
+	}
+	return err
+}
+
+func (q *QuranService) deleteValue(ctx context.Context, namespace, key string) error {
+	if q.readOnly {
+		return readOnlyWrite(namespace)
+	}
+	_, span := q.startSpan(ctx, "store.Delete", storeAttrs(namespace, key)...)
+	err := q.store.Delete(ctx, userNamespace(ctx, namespace), key)
+	endSpan(span, err)
+	if err == nil && slices.Contains(syncNamespaces, namespace) {
+		err = q.touchSyncRecord(ctx, namespace, key)
+	}
+	return err
+}
+
+// iterate calls fn with the entries of namespace, those of the user of ctx
+// for user data like getValue.
+func (q *QuranService) iterate(ctx context.Context, namespace string, fn func(key string, value []byte) error) error {
+	return q.store.Iterate(ctx, userNamespace(ctx, namespace), fn)
+}
+
+func parseInts(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var out []int
+	for _, part := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q: %w", part, err)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+func joinInts(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
+
+func valueDecode(b []byte, v interface{}) error {
+	buf := bytes.NewBuffer(b)
+
+	if err := gob.NewDecoder(buf).Decode(v); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func valueEncoder(v interface{}) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
@@ -0,0 +1,210 @@
+package quranapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alilmtech/quranapi"
+)
+
+// EnvRecord, set to a non-empty value, makes NewReplayService record its
+// cassette from the live API instead of replaying it.
+const EnvRecord = "QURANAPI_RECORD"
+
+// Mode selects whether a Recorder replays or records its cassette.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and fails requests it
+	// has no response for.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests upstream and records their responses,
+	// replacing the cassette on Save.
+	ModeRecord
+)
+
+// Interaction is a recorded request and its response. URL is the request
+// path and query, without the scheme and host.
+type Interaction struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	Text        string          `json:"text,omitempty"` // a body that is not JSON
+}
+
+// Cassette is the file a Recorder replays from and records to.
+type Cassette struct {
+	RecordedAt   time.Time     `json:"recorded_at"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is a quranapi.Doer replaying a cassette, or recording one from
+// another Doer, so that integration tests run without network access.
+type Recorder struct {
+	path string
+	mode Mode
+	doer quranapi.Doer
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a Recorder for the cassette at path. Replaying reads
+// the cassette; recording sends requests with doer, or
+// http.DefaultClient when it is nil.
+func NewRecorder(path string, mode Mode, doer quranapi.Doer) (*Recorder, error) {
+	r := &Recorder{path: path, mode: mode, doer: doer}
+	if mode == ModeRecord {
+		if r.doer == nil {
+			r.doer = http.DefaultClient
+		}
+		r.cassette.RecordedAt = time.Now().UTC()
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	return r, nil
+}
+
+func requestURL(req *http.Request) string {
+	return req.URL.RequestURI()
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeRecord {
+		return r.record(req)
+	}
+
+	url := requestURL(req)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, in := range r.cassette.Interactions {
+		if in.Method == req.Method && in.URL == url {
+			return in.response(req), nil
+		}
+	}
+	return nil, fmt.Errorf("cassette %s: no recorded response for %s %s", r.path, req.Method, url)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	resp, err := r.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	in := Interaction{
+		Method:      req.Method,
+		URL:         requestURL(req),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if json.Valid(body) {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, body, "", "  "); err != nil {
+			return nil, err
+		}
+		in.Body = buf.Bytes()
+	} else {
+		in.Text = string(body)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	r.mu.Unlock()
+	return in.response(req), nil
+}
+
+func (in Interaction) response(req *http.Request) *http.Response {
+	body := []byte(in.Body)
+	if in.Body == nil {
+		body = []byte(in.Text)
+	}
+	header := make(http.Header)
+	if in.ContentType != "" {
+		header.Set("Content-Type", in.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+		StatusCode:    in.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// Save writes the recorded cassette. It does nothing when replaying.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	r.mu.Lock()
+	err := enc.Encode(r.cassette)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, buf.Bytes(), 0o644)
+}
+
+// NewReplayService returns a service replaying the cassette at path or,
+// with EnvRecord set, recording it from the live API and saving it when
+// the test ends. opts are applied after WithTranslations(TranslationID).
+func NewReplayService(t testing.TB, path string, opts ...quranapi.Option) *quranapi.QuranService {
+	t.Helper()
+	mode := ModeReplay
+	if os.Getenv(EnvRecord) != "" {
+		mode = ModeRecord
+	}
+	rec, err := NewRecorder(path, mode, nil)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("quranapitest: cassette %s not recorded; run the test with %s=1", path, EnvRecord)
+	}
+	if err != nil {
+		t.Fatalf("quranapitest: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := rec.Save(); err != nil {
+			t.Errorf("quranapitest: save cassette: %v", err)
+		}
+	})
+
+	opts = append([]quranapi.Option{quranapi.WithTranslations(TranslationID)}, opts...)
+	q, err := quranapi.NewQuranService(rec, opts...)
+	if err != nil {
+		t.Fatalf("quranapitest: %v", err)
+	}
+	return q
+}
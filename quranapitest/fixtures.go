@@ -0,0 +1,181 @@
+package quranapitest
+
+// TranslationID is the translation resource served with the fixture
+// verses, Sahih International.
+const TranslationID = 20
+
+// Chapters lists the chapters whose verses the fake server serves. Every
+// chapter has a summary.
+var Chapters = []int{1, 112, 113, 114}
+
+type summary struct {
+	id         int
+	name       string
+	verses     int
+	start, end int
+	place      string
+}
+
+var summaries = []summary{
+	{1, "Al-Fatihah", 7, 1, 1, "makkah"},
+	{2, "Al-Baqarah", 286, 2, 49, "madinah"},
+	{3, "Ali 'Imran", 200, 50, 76, "madinah"},
+	{4, "An-Nisa", 176, 77, 106, "madinah"},
+	{5, "Al-Ma'idah", 120, 106, 127, "madinah"},
+	{6, "Al-An'am", 165, 128, 150, "makkah"},
+	{7, "Al-A'raf", 206, 151, 176, "makkah"},
+	{8, "Al-Anfal", 75, 177, 186, "madinah"},
+	{9, "At-Tawbah", 129, 187, 207, "madinah"},
+	{10, "Yunus", 109, 208, 221, "makkah"},
+	{11, "Hud", 123, 221, 235, "makkah"},
+	{12, "Yusuf", 111, 235, 248, "makkah"},
+	{13, "Ar-Ra'd", 43, 249, 255, "makkah"},
+	{14, "Ibrahim", 52, 255, 261, "makkah"},
+	{15, "Al-Hijr", 99, 262, 267, "makkah"},
+	{16, "An-Nahl", 128, 267, 281, "makkah"},
+	{17, "Al-Isra", 111, 282, 293, "makkah"},
+	{18, "Al-Kahf", 110, 293, 304, "makkah"},
+	{19, "Maryam", 98, 305, 312, "makkah"},
+	{20, "Taha", 135, 312, 321, "makkah"},
+	{21, "Al-Anbya", 112, 322, 331, "makkah"},
+	{22, "Al-Hajj", 78, 332, 341, "madinah"},
+	{23, "Al-Mu'minun", 118, 342, 349, "makkah"},
+	{24, "An-Nur", 64, 350, 359, "madinah"},
+	{25, "Al-Furqan", 77, 359, 366, "makkah"},
+	{26, "Ash-Shu'ara", 227, 367, 376, "makkah"},
+	{27, "An-Naml", 93, 377, 385, "makkah"},
+	{28, "Al-Qasas", 88, 385, 396, "makkah"},
+	{29, "Al-'Ankabut", 69, 396, 404, "makkah"},
+	{30, "Ar-Rum", 60, 404, 410, "makkah"},
+	{31, "Luqman", 34, 411, 414, "makkah"},
+	{32, "As-Sajdah", 30, 415, 417, "makkah"},
+	{33, "Al-Ahzab", 73, 418, 427, "madinah"},
+	{34, "Saba", 54, 428, 434, "makkah"},
+	{35, "Fatir", 45, 434, 440, "makkah"},
+	{36, "Ya-Sin", 83, 440, 445, "makkah"},
+	{37, "As-Saffat", 182, 446, 452, "makkah"},
+	{38, "Sad", 88, 453, 458, "makkah"},
+	{39, "Az-Zumar", 75, 458, 467, "makkah"},
+	{40, "Ghafir", 85, 467, 476, "makkah"},
+	{41, "Fussilat", 54, 477, 482, "makkah"},
+	{42, "Ash-Shuraa", 53, 483, 489, "makkah"},
+	{43, "Az-Zukhruf", 89, 489, 495, "makkah"},
+	{44, "Ad-Dukhan", 59, 496, 498, "makkah"},
+	{45, "Al-Jathiyah", 37, 499, 502, "makkah"},
+	{46, "Al-Ahqaf", 35, 502, 506, "makkah"},
+	{47, "Muhammad", 38, 507, 510, "madinah"},
+	{48, "Al-Fath", 29, 511, 515, "madinah"},
+	{49, "Al-Hujurat", 18, 515, 517, "madinah"},
+	{50, "Qaf", 45, 518, 520, "makkah"},
+	{51, "Adh-Dhariyat", 60, 520, 523, "makkah"},
+	{52, "At-Tur", 49, 523, 525, "makkah"},
+	{53, "An-Najm", 62, 526, 528, "makkah"},
+	{54, "Al-Qamar", 55, 528, 531, "makkah"},
+	{55, "Ar-Rahman", 78, 531, 534, "madinah"},
+	{56, "Al-Waqi'ah", 96, 534, 537, "makkah"},
+	{57, "Al-Hadid", 29, 537, 541, "madinah"},
+	{58, "Al-Mujadila", 22, 542, 545, "madinah"},
+	{59, "Al-Hashr", 24, 545, 548, "madinah"},
+	{60, "Al-Mumtahanah", 13, 549, 551, "madinah"},
+	{61, "As-Saf", 14, 551, 552, "madinah"},
+	{62, "Al-Jumu'ah", 11, 553, 554, "madinah"},
+	{63, "Al-Munafiqun", 11, 554, 555, "madinah"},
+	{64, "At-Taghabun", 18, 556, 557, "madinah"},
+	{65, "At-Talaq", 12, 558, 559, "madinah"},
+	{66, "At-Tahrim", 12, 560, 561, "madinah"},
+	{67, "Al-Mulk", 30, 562, 564, "makkah"},
+	{68, "Al-Qalam", 52, 564, 566, "makkah"},
+	{69, "Al-Haqqah", 52, 566, 568, "makkah"},
+	{70, "Al-Ma'arij", 44, 568, 570, "makkah"},
+	{71, "Nuh", 28, 570, 571, "makkah"},
+	{72, "Al-Jinn", 28, 572, 573, "makkah"},
+	{73, "Al-Muzzammil", 20, 574, 575, "makkah"},
+	{74, "Al-Muddaththir", 56, 575, 577, "makkah"},
+	{75, "Al-Qiyamah", 40, 577, 578, "makkah"},
+	{76, "Al-Insan", 31, 578, 580, "madinah"},
+	{77, "Al-Mursalat", 50, 580, 581, "makkah"},
+	{78, "An-Naba", 40, 582, 583, "makkah"},
+	{79, "An-Nazi'at", 46, 583, 584, "makkah"},
+	{80, "'Abasa", 42, 585, 585, "makkah"},
+	{81, "At-Takwir", 29, 586, 586, "makkah"},
+	{82, "Al-Infitar", 19, 587, 587, "makkah"},
+	{83, "Al-Mutaffifin", 36, 587, 589, "makkah"},
+	{84, "Al-Inshiqaq", 25, 589, 589, "makkah"},
+	{85, "Al-Buruj", 22, 590, 590, "makkah"},
+	{86, "At-Tariq", 17, 591, 591, "makkah"},
+	{87, "Al-A'la", 19, 591, 592, "makkah"},
+	{88, "Al-Ghashiyah", 26, 592, 592, "makkah"},
+	{89, "Al-Fajr", 30, 593, 594, "makkah"},
+	{90, "Al-Balad", 20, 594, 594, "makkah"},
+	{91, "Ash-Shams", 15, 595, 595, "makkah"},
+	{92, "Al-Layl", 21, 595, 596, "makkah"},
+	{93, "Ad-Duhaa", 11, 596, 596, "makkah"},
+	{94, "Ash-Sharh", 8, 596, 596, "makkah"},
+	{95, "At-Tin", 8, 597, 597, "makkah"},
+	{96, "Al-'Alaq", 19, 597, 597, "makkah"},
+	{97, "Al-Qadr", 5, 598, 598, "makkah"},
+	{98, "Al-Bayyinah", 8, 598, 599, "madinah"},
+	{99, "Az-Zalzalah", 8, 599, 599, "madinah"},
+	{100, "Al-'Adiyat", 11, 599, 600, "makkah"},
+	{101, "Al-Qari'ah", 11, 600, 600, "makkah"},
+	{102, "At-Takathur", 8, 600, 600, "makkah"},
+	{103, "Al-'Asr", 3, 601, 601, "makkah"},
+	{104, "Al-Humazah", 9, 601, 601, "makkah"},
+	{105, "Al-Fil", 5, 601, 601, "makkah"},
+	{106, "Quraysh", 4, 602, 602, "makkah"},
+	{107, "Al-Ma'un", 7, 602, 602, "makkah"},
+	{108, "Al-Kawthar", 3, 602, 602, "makkah"},
+	{109, "Al-Kafirun", 6, 603, 603, "makkah"},
+	{110, "An-Nasr", 3, 603, 603, "madinah"},
+	{111, "Al-Masad", 5, 603, 603, "makkah"},
+	{112, "Al-Ikhlas", 4, 604, 604, "makkah"},
+	{113, "Al-Falaq", 5, 604, 604, "makkah"},
+	{114, "An-Nas", 6, 604, 604, "makkah"},
+}
+
+var arabicNames = map[int]string{
+	1:   "الفاتحة",
+	112: "الإخلاص",
+	113: "الفلق",
+	114: "الناس",
+}
+
+type verse struct {
+	text        string // Uthmani
+	translation string
+}
+
+// verses holds the fixture chapters. Their verses are all on one page of
+// one juz: page 1 of juz 1, and page 604 of juz 30.
+var verses = map[int][]verse{
+	1: {
+		{"بِسْمِ ٱللَّهِ ٱلرَّحْمَٰنِ ٱلرَّحِيمِ", "In the name of Allah, the Entirely Merciful, the Especially Merciful."},
+		{"ٱلْحَمْدُ لِلَّهِ رَبِّ ٱلْعَٰلَمِينَ", "[All] praise is [due] to Allah, Lord of the worlds -"},
+		{"ٱلرَّحْمَٰنِ ٱلرَّحِيمِ", "The Entirely Merciful, the Especially Merciful,"},
+		{"مَٰلِكِ يَوْمِ ٱلدِّينِ", "Sovereign of the Day of Recompense."},
+		{"إِيَّاكَ نَعْبُدُ وَإِيَّاكَ نَسْتَعِينُ", "It is You we worship and You we ask for help."},
+		{"ٱهْدِنَا ٱلصِّرَٰطَ ٱلْمُسْتَقِيمَ", "Guide us to the straight path -"},
+		{"صِرَٰطَ ٱلَّذِينَ أَنْعَمْتَ عَلَيْهِمْ غَيْرِ ٱلْمَغْضُوبِ عَلَيْهِمْ وَلَا ٱلضَّآلِّينَ", "The path of those upon whom You have bestowed favor, not of those who have evoked [Your] anger or of those who are astray."},
+	},
+	112: {
+		{"قُلْ هُوَ ٱللَّهُ أَحَدٌ", "Say, \"He is Allah, [who is] One,"},
+		{"ٱللَّهُ ٱلصَّمَدُ", "Allah, the Eternal Refuge."},
+		{"لَمْ يَلِدْ وَلَمْ يُولَدْ", "He neither begets nor is born,"},
+		{"وَلَمْ يَكُن لَّهُۥ كُفُوًا أَحَدٌۢ", "Nor is there to Him any equivalent.\""},
+	},
+	113: {
+		{"قُلْ أَعُوذُ بِرَبِّ ٱلْفَلَقِ", "Say, \"I seek refuge in the Lord of daybreak"},
+		{"مِن شَرِّ مَا خَلَقَ", "From the evil of that which He created"},
+		{"وَمِن شَرِّ غَاسِقٍ إِذَا وَقَبَ", "And from the evil of darkness when it settles"},
+		{"وَمِن شَرِّ ٱلنَّفَّٰثَٰتِ فِى ٱلْعُقَدِ", "And from the evil of the blowers in knots"},
+		{"وَمِن شَرِّ حَاسِدٍ إِذَا حَسَدَ", "And from the evil of an envier when he envies.\""},
+	},
+	114: {
+		{"قُلْ أَعُوذُ بِرَبِّ ٱلنَّاسِ", "Say, \"I seek refuge in the Lord of mankind,"},
+		{"مَلِكِ ٱلنَّاسِ", "The Sovereign of mankind."},
+		{"إِلَٰهِ ٱلنَّاسِ", "The God of mankind,"},
+		{"مِن شَرِّ ٱلْوَسْوَاسِ ٱلْخَنَّاسِ", "From the evil of the retreating whisperer -"},
+		{"ٱلَّذِى يُوَسْوِسُ فِى صُدُورِ ٱلنَّاسِ", "Who whispers [evil] into the breasts of mankind -"},
+		{"مِنَ ٱلْجِنَّةِ وَٱلنَّاسِ", "From among the jinn and mankind.\""},
+	},
+}
@@ -0,0 +1,226 @@
+// Package quranapitest provides a fake quran.com API serving fixture data,
+// for testing code built on quranapi without network access or a prebuilt
+// quran.db.
+package quranapitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alilmtech/quranapi"
+)
+
+// Server is a fake upstream API serving routes of the v3 API only: a
+// summary of every chapter, the verses of Chapters with the translation
+// TranslationID, and the list of translations. Other chapters' verses are
+// not found.
+type Server struct {
+	*httptest.Server
+	requests atomic.Int64
+}
+
+// NewServer starts a Server. Close it when done.
+func NewServer() *Server {
+	s := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /chapters", s.handleChapters)
+	mux.HandleFunc("GET /chapters/{id}", s.handleChapter)
+	mux.HandleFunc("GET /chapters/{id}/verses", s.handleVerses)
+	mux.HandleFunc("GET /chapters/{id}/verses/{verse}", s.handleVerse)
+	mux.HandleFunc("GET /options/translations", s.handleTranslations)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		mux.ServeHTTP(w, r)
+	}))
+	return s
+}
+
+// Requests returns the number of requests served so far, to check what a
+// test read from the cache.
+func (s *Server) Requests() int64 {
+	return s.requests.Load()
+}
+
+// NewTestService returns a service reading from a Server, which is closed
+// when the test ends, and an in-memory store. It fetches TranslationID;
+// opts are applied after the defaults.
+func NewTestService(t testing.TB, opts ...quranapi.Option) *quranapi.QuranService {
+	t.Helper()
+	srv := NewServer()
+	t.Cleanup(srv.Close)
+
+	opts = append([]quranapi.Option{
+		quranapi.WithBaseURL(srv.URL),
+		quranapi.WithTranslations(TranslationID),
+	}, opts...)
+	q, err := quranapi.NewQuranService(srv.Client(), opts...)
+	if err != nil {
+		t.Fatalf("quranapitest: %v", err)
+	}
+	return q
+}
+
+func chapterSummary(s summary) quranapi.ChapterSummary {
+	return quranapi.ChapterSummary{
+		ID:                  s.id,
+		Number:              s.id,
+		BismallahPre:        s.id != 1 && s.id != 9,
+		RevelationPlace:     s.place,
+		NameTransliteration: s.name,
+		NameArabic:          arabicNames[s.id],
+		NameSimple:          s.name,
+		VerseCount:          s.verses,
+		Pages:               [2]int{s.start, s.end},
+	}
+}
+
+// fixtureVerse returns verse n of chapter, with the translation when
+// withTranslation is set.
+func fixtureVerse(chapter, n int, withTranslation bool) quranapi.Verse {
+	id := n
+	for _, s := range summaries[:chapter-1] {
+		id += s.verses
+	}
+	page, juz, hizb := 604, 30, 60
+	if chapter == 1 {
+		page, juz, hizb = 1, 1, 1
+	}
+
+	fv := verses[chapter][n-1]
+	key := quranapi.NewVerseKey(chapter, n)
+	v := quranapi.Verse{
+		ID:          id,
+		VerseNumber: n,
+		ChapterID:   chapter,
+		VerseKey:    key,
+		TextMadani:  fv.text,
+		JuzNumber:   juz,
+		HizbNumber:  hizb,
+		RubNumber:   hizb * 4,
+		PageNumber:  page,
+	}
+	if chapter == 1 {
+		v.RubNumber = 1
+	}
+	if withTranslation {
+		v.Translations = []quranapi.Translation{{
+			ID:           id,
+			LanguageName: "english",
+			Text:         fv.translation,
+			ResourceName: "Saheeh International",
+			ResourceID:   TranslationID,
+		}}
+	}
+	for i, text := range strings.Fields(fv.text) {
+		v.Words = append(v.Words, quranapi.Word{
+			Position:   i + 1,
+			TextMadani: text,
+			VerseKey:   key,
+			PageNumber: page,
+			CharType:   "word",
+		})
+	}
+	v.Words = append(v.Words, quranapi.Word{
+		Position:   len(v.Words) + 1,
+		TextMadani: arabicNumber(n),
+		VerseKey:   key,
+		PageNumber: page,
+		CharType:   "end",
+	})
+	return v
+}
+
+func arabicNumber(n int) string {
+	var b strings.Builder
+	for _, d := range strconv.Itoa(n) {
+		b.WriteRune('٠' + d - '0')
+	}
+	return b.String()
+}
+
+func (s *Server) handleChapters(w http.ResponseWriter, r *http.Request) {
+	out := make([]quranapi.ChapterSummary, len(summaries))
+	for i, s := range summaries {
+		out[i] = chapterSummary(s)
+	}
+	writeJSON(w, map[string]any{"chapters": out})
+}
+
+// pathChapter returns the chapter in the request path, writing a 404 and
+// returning 0 when there is none, or when fixture is set and it has no
+// fixture verses.
+func pathChapter(w http.ResponseWriter, r *http.Request, fixture bool) int {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 || id > len(summaries) || fixture && verses[id] == nil {
+		http.NotFound(w, r)
+		return 0
+	}
+	return id
+}
+
+func (s *Server) handleChapter(w http.ResponseWriter, r *http.Request) {
+	id := pathChapter(w, r, false)
+	if id == 0 {
+		return
+	}
+	writeJSON(w, map[string]any{"chapter": chapterSummary(summaries[id-1])})
+}
+
+func (s *Server) handleVerses(w http.ResponseWriter, r *http.Request) {
+	id := pathChapter(w, r, true)
+	if id == 0 {
+		return
+	}
+	query := r.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	withTranslation := requested(query.Get("translations"), TranslationID)
+
+	out := []quranapi.Verse{}
+	for n := offset + 1; n <= len(verses[id]) && len(out) < limit; n++ {
+		out = append(out, fixtureVerse(id, n, withTranslation))
+	}
+	writeJSON(w, map[string]any{"verses": out})
+}
+
+func (s *Server) handleVerse(w http.ResponseWriter, r *http.Request) {
+	id := pathChapter(w, r, true)
+	if id == 0 {
+		return
+	}
+	n, err := strconv.Atoi(r.PathValue("verse"))
+	if err != nil || n < 1 || n > len(verses[id]) {
+		http.NotFound(w, r)
+		return
+	}
+	v := fixtureVerse(id, n, requested(r.URL.Query().Get("translations"), TranslationID))
+	v.MediaContents = []quranapi.MediaContent{}
+	writeJSON(w, map[string]any{"verse": v})
+}
+
+func (s *Server) handleTranslations(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]any{"translations": []map[string]any{{
+		"id":            TranslationID,
+		"name":          "Saheeh International",
+		"language_name": "english",
+	}}})
+}
+
+// requested reports whether the comma separated ids include id.
+func requested(ids string, id int) bool {
+	return slices.Contains(strings.Split(ids, ","), strconv.Itoa(id))
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,80 @@
+package quranapitest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alilmtech/quranapi"
+	"github.com/alilmtech/quranapi/quranapitest"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// TestSyncGolden syncs the fixture chapters from a recorded cassette and
+// compares what is stored with golden files, catching changes to how the
+// service decodes and stores responses. The cassette holds the responses
+// of the fixture Server, not of the live API, so it says nothing of
+// changes upstream.
+func TestSyncGolden(t *testing.T) {
+	q := quranapitest.NewReplayService(t, "testdata/cassettes/sync.json")
+	ctx := context.Background()
+
+	report, err := q.Prefetch(ctx, quranapi.PrefetchSpec{Chapters: quranapitest.Chapters}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Failed) > 0 {
+		t.Fatalf("prefetch failed: %v", report.Failed)
+	}
+
+	summaries, err := q.ChaptersSummary(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 114 {
+		t.Errorf("got %d chapter summaries, want 114", len(summaries))
+	}
+	for _, id := range quranapitest.Chapters {
+		chapter, err := q.GetChapter(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden(t, fmt.Sprintf("chapter_%d.json", id), map[string]any{
+			"summary": summaries[id-1],
+			"chapter": chapter,
+		})
+	}
+}
+
+func golden(t *testing.T, name string, v any) {
+	t.Helper()
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v; run the test with -update to create it", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file; run the test with -update and review the diff", name)
+	}
+}
@@ -0,0 +1,291 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// ErrReadOnly is returned for writes of user data, such as bookmarks and
+// annotations, by a service created WithReadOnly.
+var ErrReadOnly = errors.New("store is read-only")
+
+// ErrCompactUnsupported is returned by QuranService.Compact for stores other
+// than bbolt.
+var ErrCompactUnsupported = errors.New("store does not support compaction")
+
+// cacheNamespaces hold what the service derives from upstream. A read-only
+// service keeps their writes in its memory caches instead.
+var cacheNamespaces = map[string]bool{
+	bucketChapters:          true,
+	bucketChecksums:         true,
+	bucketScripts:           true,
+	bucketMedia:             true,
+	bucketStats:             true,
+	bucketSearch:            true,
+	bucketSimilar:           true,
+	bucketTranslationHashes: true,
+	bucketTopics:            true,
+	bucketVerses:            true,
+	bucketPageIndex:         true,
+	bucketJuzIndex:          true,
+	bucketWordAlignments:    true,
+	bucketAudio:             true,
+	bucketAsbab:             true,
+}
+
+// WithReadOnly never writes to the store, so that several server processes
+// can share a database opened with StoreConfig.ReadOnly while a separate
+// sync process updates its own copy and publishes it with Compact. Text
+// missing from the store is still fetched upstream, but only cached in
+// memory; writes of user data fail with ErrReadOnly.
+func WithReadOnly() Option {
+	return func(q *QuranService) {
+		q.readOnly = true
+	}
+}
+
+// readOnlyWrite is what a read-only service's write to namespace returns:
+// nil when the write may be skipped, or ErrReadOnly.
+func readOnlyWrite(namespace string) error {
+	if cacheNamespaces[namespace] {
+		return nil
+	}
+	return ErrReadOnly
+}
+
+// compacter is implemented by stores that can write a compacted copy of
+// themselves.
+type compacter interface {
+	CompactTo(ctx context.Context, path string) error
+}
+
+// Compact writes a compacted copy of the store to path, replacing any file
+// there atomically so that servers reading it never see a partial
+// database. A sync process publishes its database this way; servers pick up
+// the new file with ReloadStore.
+func (q *QuranService) Compact(ctx context.Context, path string) error {
+	c, ok := q.store.(compacter)
+	if !ok {
+		return ErrCompactUnsupported
+	}
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	_, span := q.startSpan(ctx, "store.Compact")
+	err := c.CompactTo(ctx, tmp)
+	endSpan(span, err)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReloadableStore is a Store that can be reopened in place, to switch a
+// running server to a database file replaced since it was opened. OpenStore
+// returns one for read-only configs.
+type ReloadableStore struct {
+	cfg StoreConfig
+
+	mu    sync.RWMutex
+	store Store
+}
+
+func NewReloadableStore(cfg StoreConfig) (*ReloadableStore, error) {
+	s, err := openStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ReloadableStore{cfg: cfg, store: s}, nil
+}
+
+// Reload opens the configured store again and closes the previous one once
+// operations in progress on it are done.
+func (r *ReloadableStore) Reload() error {
+	s, err := openStore(r.cfg)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	old := r.store
+	r.store = s
+	r.mu.Unlock()
+	return old.Close()
+}
+
+func (r *ReloadableStore) current() (Store, func()) {
+	r.mu.RLock()
+	return r.store, r.mu.RUnlock
+}
+
+func (r *ReloadableStore) Get(ctx context.Context, namespace, key string) ([]byte, error) {
+	s, done := r.current()
+	defer done()
+	return s.Get(ctx, namespace, key)
+}
+
+func (r *ReloadableStore) Put(ctx context.Context, namespace, key string, value []byte) error {
+	s, done := r.current()
+	defer done()
+	return s.Put(ctx, namespace, key, value)
+}
+
+func (r *ReloadableStore) PutBatch(ctx context.Context, namespace string, values map[string][]byte) error {
+	s, done := r.current()
+	defer done()
+	if b, ok := s.(batchPutter); ok {
+		return b.PutBatch(ctx, namespace, values)
+	}
+	for key, value := range values {
+		if err := s.Put(ctx, namespace, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ReloadableStore) Delete(ctx context.Context, namespace, key string) error {
+	s, done := r.current()
+	defer done()
+	return s.Delete(ctx, namespace, key)
+}
+
+func (r *ReloadableStore) Iterate(ctx context.Context, namespace string, fn func(key string, value []byte) error) error {
+	s, done := r.current()
+	defer done()
+	return s.Iterate(ctx, namespace, fn)
+}
+
+func (r *ReloadableStore) Snapshot(ctx context.Context, namespaces ...string) (Store, error) {
+	s, done := r.current()
+	defer done()
+	snap, ok := s.(snapshotter)
+	if !ok {
+		return nil, ErrSnapshotUnsupported
+	}
+	return snap.Snapshot(ctx, namespaces...)
+}
+
+func (r *ReloadableStore) CompactTo(ctx context.Context, path string) error {
+	s, done := r.current()
+	defer done()
+	c, ok := s.(compacter)
+	if !ok {
+		return ErrCompactUnsupported
+	}
+	return c.CompactTo(ctx, path)
+}
+
+func (r *ReloadableStore) WriteBackup(ctx context.Context, w io.Writer) (int64, error) {
+	s, done := r.current()
+	defer done()
+	b, ok := s.(backuper)
+	if !ok {
+		return 0, ErrBackupUnsupported
+	}
+	return b.WriteBackup(ctx, w)
+}
+
+func (r *ReloadableStore) RestoreBackup(ctx context.Context, path string) error {
+	s, done := r.current()
+	defer done()
+	b, ok := s.(backuper)
+	if !ok {
+		return ErrBackupUnsupported
+	}
+	return b.RestoreBackup(ctx, path)
+}
+
+func (r *ReloadableStore) DBStats(ctx context.Context) (DBStats, error) {
+	s, done := r.current()
+	defer done()
+	st, ok := s.(dbStatser)
+	if !ok {
+		return DBStats{}, ErrDBStatsUnsupported
+	}
+	return st.DBStats(ctx)
+}
+
+func (r *ReloadableStore) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.store.Close()
+}
+
+// ReloadStore reopens a ReloadableStore and drops everything cached from
+// the previous file.
+func (q *QuranService) ReloadStore(ctx context.Context) error {
+	r, ok := q.store.(*ReloadableStore)
+	if !ok {
+		return errors.New("store is not reloadable")
+	}
+	if err := r.Reload(); err != nil {
+		return err
+	}
+	q.dropCaches()
+	q.log(ctx).Info("store reloaded", "path", r.cfg.Path)
+	return nil
+}
+
+// dropCaches empties the memory caches after the store's contents were
+// replaced underneath them.
+func (q *QuranService) dropCaches() {
+	q.chapterCache.removeFunc(func(string) bool { return true })
+	q.verseCache.removeFunc(func(string) bool { return true })
+	q.search.mu.Lock()
+	q.search.loaded = false
+	q.search.chapters = make(map[int]chapterPostings)
+	q.search.counts = make(map[int]chapterCounts)
+	q.search.mu.Unlock()
+}
+
+// reloadOnHangup reloads the store on every SIGHUP until ctx is done.
+func (q *QuranService) reloadOnHangup(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err := q.ReloadStore(ctx); err != nil {
+				q.log(ctx).Error("reload store", "err", err)
+			}
+		}
+	}
+}
+
+func runCompact(ctx context.Context, q *QuranService, cfg StoreConfig, args []string) error {
+	fs := flag.NewFlagSet("compact", flag.ContinueOnError)
+	to := fs.String("to", "", "file to write the compacted database to (default: replace the database)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	before, err := q.DBStats(ctx)
+	if err != nil {
+		return err
+	}
+	// compacting in place renames the new file over the open one; this
+	// process keeps the old file until it exits, right after
+	path := *to
+	if path == "" {
+		path = cfg.Path
+	}
+	if err := q.Compact(ctx, path); err != nil {
+		return err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("compacted %s to %s\n", formatBytes(before.Size), formatBytes(fi.Size()))
+	return nil
+}
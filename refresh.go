@@ -0,0 +1,211 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"time"
+)
+
+const bucketLeases = "leases"
+
+const (
+	// refreshLease bounds how long a replica that died mid refresh keeps
+	// the others from refreshing.
+	refreshLease = 30 * time.Minute
+	maxJitter    = 5 * time.Minute
+)
+
+// WithAutoRefresh revalidates the stored chapters every interval; see
+// WithRefreshSchedule.
+func WithAutoRefresh(interval time.Duration) Option {
+	return WithRefreshSchedule(Every(interval))
+}
+
+// WithRefreshSchedule revalidates the stored chapters against upstream on
+// a schedule once RunAutoRefresh is started, so that corrected
+// translations reach readers while they keep being served from the cache.
+// Runs are jittered, and replicas sharing a store take a lease on it so
+// that only one of them refreshes at a time.
+func WithRefreshSchedule(s Schedule) Option {
+	return func(q *QuranService) {
+		q.refreshSchedule = s
+	}
+}
+
+// RefreshReport counts the chapters a refresh revalidated.
+type RefreshReport struct {
+	Checked int
+	Updated int
+	Failed  int
+}
+
+// Refresh refetches every stored chapter from upstream and replaces those
+// whose content changed. Chapters keep being served from the cache while
+// they are fetched.
+func (q *QuranService) Refresh(ctx context.Context) (RefreshReport, error) {
+	var (
+		report RefreshReport
+		stored = make(map[int]string)
+	)
+	err := q.store.Iterate(ctx, bucketChecksums, func(key string, value []byte) error {
+		if id, err := strconv.Atoi(key); err == nil {
+			stored[id] = string(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	for id := 1; id <= 114; id++ {
+		checksum, ok := stored[id]
+		if !ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		report.Checked++
+
+		chapter, err := q.getChapter(ctx, id)
+		if err != nil {
+			q.log(ctx).Warn("refresh chapter", "chapter", id, "err", err)
+			report.Failed++
+			continue
+		}
+		q.transliterate(&chapter)
+		q.profile.strip(chapter.Verses)
+		if chapter.Checksum() == checksum {
+			continue
+		}
+		if err := q.setChapterDB(ctx, chapter); err != nil {
+			q.log(ctx).Warn("refresh chapter", "chapter", id, "err", err)
+			report.Failed++
+			continue
+		}
+		// the memory caches reload the new text from the store
+		q.uncacheChapter(id)
+		report.Updated++
+	}
+	return report, nil
+}
+
+// RunAutoRefresh refreshes on the service's schedule until ctx is done. It
+// returns at once for services created without one, and for read-only
+// services, which leave refreshing to the process writing the store.
+func (q *QuranService) RunAutoRefresh(ctx context.Context) {
+	if q.refreshSchedule == nil || q.readOnly {
+		return
+	}
+	owner := hostname() + "/" + randomID()
+	for {
+		now := time.Now()
+		next := q.refreshSchedule.Next(now)
+		if next.IsZero() {
+			return
+		}
+		wait := next.Sub(now) + refreshJitter(q.refreshSchedule, next)
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+
+		ok, err := q.acquireLease(ctx, "refresh", owner, refreshLease)
+		if err != nil {
+			q.log(ctx).Warn("refresh lease", "err", err)
+			continue
+		}
+		if !ok {
+			q.log(ctx).Debug("refresh skipped, another replica holds the lease")
+			continue
+		}
+		report, err := q.Refresh(ctx)
+		if err != nil {
+			q.log(ctx).Warn("refresh", "err", err)
+		}
+		q.log(ctx).Info("refreshed chapters", "checked", report.Checked, "updated", report.Updated, "failed", report.Failed)
+		if err := q.releaseLease(ctx, "refresh", owner); err != nil {
+			q.log(ctx).Warn("release refresh lease", "err", err)
+		}
+	}
+}
+
+// refreshJitter spreads replicas' runs over a tenth of the interval, so
+// that they don't all hit upstream at once.
+func refreshJitter(s Schedule, next time.Time) time.Duration {
+	spread := min(s.Next(next).Sub(next)/10, maxJitter)
+	if spread <= 0 {
+		return 0
+	}
+	return rand.N(spread)
+}
+
+func hostname() string {
+	h, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return h
+}
+
+// leaser is implemented by stores shared between replicas that can take
+// a lease atomically.
+type leaser interface {
+	AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	ReleaseLease(ctx context.Context, name, owner string) error
+}
+
+type lease struct {
+	Owner   string
+	Expires time.Time
+}
+
+// acquireLease takes the named lease for owner, reporting false while
+// another owner holds it. Stores that can't lease atomically fall back to
+// a read then write, which is enough for stores a single process opens.
+func (q *QuranService) acquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	if l, ok := q.store.(leaser); ok {
+		return l.AcquireLease(ctx, name, owner, ttl)
+	}
+	var cur lease
+	err := q.getValue(ctx, bucketLeases, name, &cur)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return false, err
+	}
+	if err == nil && cur.Owner != owner && time.Now().Before(cur.Expires) {
+		return false, nil
+	}
+	return true, q.putValue(ctx, bucketLeases, name, lease{Owner: owner, Expires: time.Now().Add(ttl)})
+}
+
+func (q *QuranService) releaseLease(ctx context.Context, name, owner string) error {
+	if l, ok := q.store.(leaser); ok {
+		return l.ReleaseLease(ctx, name, owner)
+	}
+	var cur lease
+	err := q.getValue(ctx, bucketLeases, name, &cur)
+	if errors.Is(err, ErrKeyNotFound) || err == nil && cur.Owner != owner {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return q.deleteValue(ctx, bucketLeases, name)
+}
+
+func runRefresh(ctx context.Context, q *QuranService, args []string) error {
+	report, err := q.Refresh(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("checked %d, updated %d, failed %d\n", report.Checked, report.Updated, report.Failed)
+	return nil
+}
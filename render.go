@@ -0,0 +1,285 @@
+package quranapi
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RenderFormat is a human readable output format of RenderVerses.
+type RenderFormat string
+
+const (
+	RenderText     RenderFormat = "text"
+	RenderMarkdown RenderFormat = "markdown"
+	RenderHTML     RenderFormat = "html"
+)
+
+// ParseRenderFormat returns the format named s, accepting "txt" and "md"
+// for text and Markdown.
+func ParseRenderFormat(s string) (RenderFormat, error) {
+	switch strings.ToLower(s) {
+	case "", "text", "txt":
+		return RenderText, nil
+	case "markdown", "md":
+		return RenderMarkdown, nil
+	case "html":
+		return RenderHTML, nil
+	}
+	return "", fmt.Errorf("unsupported render format %q: want text, markdown or html", s)
+}
+
+// ContentType returns the media type of the format.
+func (f RenderFormat) ContentType() string {
+	switch f {
+	case RenderMarkdown:
+		return "text/markdown; charset=utf-8"
+	case RenderHTML:
+		return "text/html; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+type RenderOptions struct {
+	Format RenderFormat
+	// Transliteration adds each verse in Latin script after its Arabic,
+	// transliterating the Arabic of verses stored without one.
+	Transliteration bool
+	// Translations selects the translation resources shown, in order;
+	// nil shows every translation of the verses.
+	Translations []int
+	// Accessible renders plain text for screen readers, whatever the
+	// Format: each verse is announced by name, as "Surah Al-Baqarah, verse
+	// 255", references in translations are spelled out the same way, and
+	// markup, footnotes and verse end markers are left out. The
+	// translations are read before the Arabic unless ArabicFirst is set.
+	Accessible  bool
+	ArabicFirst bool
+	// ChapterNames names the chapters in accessible output; chapters
+	// missing from it are announced by number.
+	ChapterNames map[int]string
+}
+
+// RenderVerses writes the verses with their Arabic, ended by the ۝ verse
+// marker, followed by their transliteration and translations. HTML output
+// is a fragment of one <article> per verse.
+func RenderVerses(w io.Writer, verses []Verse, opts RenderOptions) error {
+	format, err := ParseRenderFormat(string(opts.Format))
+	if err != nil {
+		return err
+	}
+	if opts.Accessible {
+		return renderAccessible(w, verses, opts)
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, v := range verses {
+		arabic := v.TextMadani + " " + verseEndMarker(v.VerseNumber)
+		translit := ""
+		if opts.Transliteration {
+			translit = v.Transliteration
+			if translit == "" {
+				translit = Transliterate(v.TextMadani)
+			}
+		}
+		translations := selectTranslations(v.Translations, opts.Translations)
+
+		switch format {
+		case RenderText:
+			if i > 0 {
+				bw.WriteString("\n")
+			}
+			fmt.Fprintf(bw, "%s\n%s\n", v.VerseKey, arabic)
+			if translit != "" {
+				fmt.Fprintf(bw, "%s\n", translit)
+			}
+			for _, tr := range translations {
+				bw.WriteString(html.UnescapeString(stripTags(tr.Text)))
+				if tr.ResourceName != "" {
+					fmt.Fprintf(bw, " (%s)", tr.ResourceName)
+				}
+				bw.WriteString("\n")
+			}
+		case RenderMarkdown:
+			if i > 0 {
+				bw.WriteString("\n")
+			}
+			fmt.Fprintf(bw, "### %s\n\n> %s\n", v.VerseKey, arabic)
+			if translit != "" {
+				fmt.Fprintf(bw, "\n*%s*\n", markdownEscape(translit))
+			}
+			for _, tr := range translations {
+				fmt.Fprintf(bw, "\n%s", markdownEscape(html.UnescapeString(stripTags(tr.Text))))
+				if tr.ResourceName != "" {
+					fmt.Fprintf(bw, " — %s", markdownEscape(tr.ResourceName))
+				}
+				bw.WriteString("\n")
+			}
+		case RenderHTML:
+			fmt.Fprintf(bw, "<article class=\"verse\" id=\"%s\">\n", html.EscapeString(strings.ReplaceAll(v.VerseKey.String(), ":", "-")))
+			fmt.Fprintf(bw, "<p class=\"arabic\" lang=\"ar\" dir=\"rtl\">%s</p>\n", html.EscapeString(arabic))
+			if translit != "" {
+				fmt.Fprintf(bw, "<p class=\"transliteration\">%s</p>\n", html.EscapeString(translit))
+			}
+			for _, tr := range translations {
+				fmt.Fprintf(bw, "<p class=\"translation\" data-resource=\"%d\">%s <cite>%s</cite></p>\n",
+					tr.ResourceID, html.EscapeString(html.UnescapeString(stripTags(tr.Text))), html.EscapeString(tr.ResourceName))
+			}
+			bw.WriteString("</article>\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// renderAccessible writes the verses as RenderOptions.Accessible describes.
+func renderAccessible(w io.Writer, verses []Verse, opts RenderOptions) error {
+	bw := bufio.NewWriter(w)
+	for i, v := range verses {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "%s.\n", spokenReference(v.ChapterID, v.VerseNumber, 0, opts.ChapterNames))
+		arabic := func() {
+			fmt.Fprintf(bw, "Arabic: %s\n", v.TextMadani)
+		}
+		if opts.ArabicFirst {
+			arabic()
+		}
+		for _, tr := range selectTranslations(v.Translations, opts.Translations) {
+			text := html.UnescapeString(stripTags(tr.Text))
+			text = expandReferences(strings.Join(strings.Fields(text), " "), opts.ChapterNames)
+			if tr.ResourceName != "" {
+				fmt.Fprintf(bw, "Translation, %s: %s\n", tr.ResourceName, text)
+			} else {
+				fmt.Fprintf(bw, "Translation: %s\n", text)
+			}
+		}
+		if !opts.ArabicFirst {
+			arabic()
+		}
+		if opts.Transliteration {
+			translit := v.Transliteration
+			if translit == "" {
+				translit = Transliterate(v.TextMadani)
+			}
+			fmt.Fprintf(bw, "Transliteration: %s\n", translit)
+		}
+	}
+	return bw.Flush()
+}
+
+// spokenReference spells out a verse, or the range of verses from verse to
+// to when to is after it, for reading aloud.
+func spokenReference(chapter, verse, to int, names map[int]string) string {
+	surah := "Surah " + strconv.Itoa(chapter)
+	if name := names[chapter]; name != "" {
+		surah = "Surah " + name
+	}
+	if to > verse {
+		return fmt.Sprintf("%s, verses %d to %d", surah, verse, to)
+	}
+	return fmt.Sprintf("%s, verse %d", surah, verse)
+}
+
+// referenceRE matches verse references such as 2:255 and 3:7-9.
+var referenceRE = regexp.MustCompile(`\b(\d{1,3}):(\d{1,3})(?:\s*[-–]\s*(\d{1,3}))?\b`)
+
+// expandReferences spells out the verse references in s, leaving alone
+// those naming no verse, such as times of day.
+func expandReferences(s string, names map[int]string) string {
+	return referenceRE.ReplaceAllStringFunc(s, func(ref string) string {
+		m := referenceRE.FindStringSubmatch(ref)
+		chapter, _ := strconv.Atoi(m[1])
+		verse, _ := strconv.Atoi(m[2])
+		to, _ := strconv.Atoi(m[3])
+		if chapter < 1 || chapter > ChapterCount || verse < 1 || verse > chapterVerseCounts[chapter-1] ||
+			m[3] != "" && (to <= verse || to > chapterVerseCounts[chapter-1]) {
+			return ref
+		}
+		return spokenReference(chapter, verse, to, names)
+	})
+}
+
+// chapterNames returns the simple names of the chapters by id.
+func (q *QuranService) chapterNames(ctx context.Context) (map[int]string, error) {
+	summaries, err := q.ChaptersSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(summaries))
+	for _, s := range summaries {
+		names[s.ID] = s.NameSimple
+	}
+	return names, nil
+}
+
+// selectTranslations returns the translations with the given resource ids,
+// in their order, or all of them when ids is nil.
+func selectTranslations(translations []Translation, ids []int) []Translation {
+	if ids == nil {
+		return translations
+	}
+	var out []Translation
+	for _, id := range ids {
+		i := slices.IndexFunc(translations, func(tr Translation) bool { return tr.ResourceID == id })
+		if i >= 0 {
+			out = append(out, translations[i])
+		}
+	}
+	return out
+}
+
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`)
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func runRender(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	format := fs.String("format", "text", "output format: text, markdown or html")
+	translit := fs.Bool("translit", false, "include the transliteration")
+	translations := fs.String("t", "", "comma separated translation resource ids to show (default: all)")
+	a11y := fs.Bool("a11y", false, "plain text for screen readers")
+	arabicFirst := fs.Bool("arabic-first", false, "with -a11y, read the Arabic before the translations")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: render [-format f] [-translit] [-t ids] [-a11y [-arabic-first]] <scope>")
+	}
+	opts := RenderOptions{Transliteration: *translit, Accessible: *a11y, ArabicFirst: *arabicFirst}
+	var err error
+	if opts.Format, err = ParseRenderFormat(*format); err != nil {
+		return err
+	}
+	if *translations != "" {
+		if opts.Translations, err = parseInts(*translations); err != nil {
+			return err
+		}
+	}
+
+	scope, err := q.ResolveScope(strings.Join(fs.Args(), " "))
+	if err != nil {
+		return err
+	}
+	verses, err := q.ScopeVerses(ctx, scope)
+	if err != nil {
+		return err
+	}
+	if opts.Accessible {
+		if opts.ChapterNames, err = q.chapterNames(ctx); err != nil {
+			return err
+		}
+	}
+	return RenderVerses(os.Stdout, verses, opts)
+}
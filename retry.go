@@ -0,0 +1,238 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const defaultRetryQueueSize = 32
+
+// WithCacheWriteRetry queues up to size chapters whose store write failed
+// and retries them in the background instead of refetching them upstream on
+// the next read. With a non-empty path the queue is saved there so pending
+// writes survive a restart.
+func WithCacheWriteRetry(size int, path string) Option {
+	return func(q *QuranService) {
+		q.retryQueue = newRetryQueue(size, path)
+	}
+}
+
+// CacheWriteStats counts chapter store writes that failed and were queued
+// for retry.
+type CacheWriteStats struct {
+	Pending int
+	Retried uint64 // writes that later succeeded
+	Dropped uint64 // writes evicted from a full queue
+	Failed  uint64 // total failed attempts, including retries
+}
+
+func (q *QuranService) CacheWriteStats() CacheWriteStats {
+	return q.retryQueue.stats()
+}
+
+type retryQueue struct {
+	mu      sync.Mutex
+	size    int
+	path    string
+	order   []int
+	pending map[int]Chapter
+
+	retried, dropped, failed uint64
+}
+
+func newRetryQueue(size int, path string) *retryQueue {
+	if size <= 0 {
+		size = defaultRetryQueueSize
+	}
+	return &retryQueue{size: size, path: path, pending: make(map[int]Chapter)}
+}
+
+// add queues chapter, evicting the oldest pending write when full.
+func (r *retryQueue) add(chapter Chapter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failed++
+	r.push(chapter)
+	return r.save()
+}
+
+// push queues chapter, dropping the oldest entry when the queue is full.
+// r.mu must be held.
+func (r *retryQueue) push(chapter Chapter) {
+	if _, ok := r.pending[chapter.ID]; !ok {
+		if len(r.order) >= r.size {
+			delete(r.pending, r.order[0])
+			r.order = r.order[1:]
+			r.dropped++
+		}
+		r.order = append(r.order, chapter.ID)
+	}
+	r.pending[chapter.ID] = chapter
+}
+
+func (r *retryQueue) snapshot() []Chapter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Chapter, 0, len(r.order))
+	for _, id := range r.order {
+		out = append(out, r.pending[id])
+	}
+	return out
+}
+
+func (r *retryQueue) done(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.pending[id]; !ok {
+		return nil
+	}
+	delete(r.pending, id)
+	for i, v := range r.order {
+		if v == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+	r.retried++
+	return r.save()
+}
+
+func (r *retryQueue) attemptFailed() {
+	r.mu.Lock()
+	r.failed++
+	r.mu.Unlock()
+}
+
+func (r *retryQueue) stats() CacheWriteStats {
+	if r == nil {
+		return CacheWriteStats{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return CacheWriteStats{
+		Pending: len(r.order),
+		Retried: r.retried,
+		Dropped: r.dropped,
+		Failed:  r.failed,
+	}
+}
+
+// save writes the pending chapters to r.path. r.mu must be held.
+func (r *retryQueue) save() error {
+	if r.path == "" {
+		return nil
+	}
+	pending := make([]Chapter, 0, len(r.order))
+	for _, id := range r.order {
+		pending = append(pending, r.pending[id])
+	}
+	buf, err := valueEncoder(pending)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(r.path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), r.path)
+}
+
+func (r *retryQueue) load() error {
+	if r.path == "" {
+		return nil
+	}
+	b, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var pending []Chapter
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&pending); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, chapter := range pending {
+		r.push(chapter)
+	}
+	return nil
+}
+
+// cacheChapter writes chapter to the store, queueing it for retry when the
+// write fails.
+func (q *QuranService) cacheChapter(ctx context.Context, chapter Chapter) {
+	err := q.setChapterDB(ctx, chapter)
+	if err == nil {
+		return
+	}
+	if q.retryQueue == nil {
+		q.log(ctx).Error("cache chapter", "chapter", chapter.ID, "err", err)
+		return
+	}
+	if qerr := q.retryQueue.add(chapter); qerr != nil {
+		q.log(ctx).Error("persist cache write queue", "err", qerr)
+	}
+	q.log(ctx).Error("cache chapter, queued for retry", "chapter", chapter.ID, "err", err,
+		"pending", q.retryQueue.stats().Pending)
+}
+
+// RetryCacheWrites retries every queued chapter write once and returns the
+// number still pending.
+func (q *QuranService) RetryCacheWrites(ctx context.Context) int {
+	if q.retryQueue == nil {
+		return 0
+	}
+	for _, chapter := range q.retryQueue.snapshot() {
+		if err := ctx.Err(); err != nil {
+			break
+		}
+		if err := q.setChapterDB(ctx, chapter); err != nil {
+			q.retryQueue.attemptFailed()
+			q.log(ctx).Warn("retry cache chapter", "chapter", chapter.ID, "err", err)
+			continue
+		}
+		if err := q.retryQueue.done(chapter.ID); err != nil {
+			q.log(ctx).Error("persist cache write queue", "err", err)
+		}
+		q.log(ctx).Info("cached chapter on retry", "chapter", chapter.ID)
+	}
+	return q.retryQueue.stats().Pending
+}
+
+// RunCacheWriteRetries calls RetryCacheWrites every interval until ctx is
+// done.
+func (q *QuranService) RunCacheWriteRetries(ctx context.Context, interval time.Duration) {
+	if q.retryQueue == nil {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			q.RetryCacheWrites(ctx)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package quranapi
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// The revelation places of ChapterSummary.RevelationPlace.
+const (
+	RevelationMakkah  = "makkah"
+	RevelationMadinah = "madinah"
+)
+
+// ParseRevelationPlace returns the revelation place named s, accepting
+// the common spellings: "makkah", "mecca" and "meccan", or "madinah",
+// "medina" and "medinan".
+func ParseRevelationPlace(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "makkah", "makka", "mecca", "meccan", "makki", "makkiyah":
+		return RevelationMakkah, nil
+	case "madinah", "madina", "medina", "medinan", "madani", "madaniyah":
+		return RevelationMadinah, nil
+	}
+	return "", fmt.Errorf("invalid revelation place %q: want makkah or madinah", s)
+}
+
+// revelationSequence lists the chapters in the order of revelation of the
+// Egyptian standard edition, which upstream's revelation_order follows.
+var revelationSequence = [ChapterCount]int{
+	96, 68, 73, 74, 1, 111, 81, 87, 92, 89, 93, 94, 103, 100, 108, 102, 107, 109, 105, 113,
+	114, 112, 53, 80, 97, 91, 85, 95, 106, 101, 75, 104, 77, 50, 90, 86, 54, 38, 7, 72,
+	36, 25, 35, 19, 20, 56, 26, 27, 28, 17, 10, 11, 12, 15, 6, 37, 31, 34, 39, 40,
+	41, 42, 43, 44, 45, 46, 51, 88, 18, 16, 71, 14, 21, 23, 32, 52, 67, 69, 70, 78,
+	79, 82, 84, 30, 29, 83, 2, 8, 3, 33, 60, 4, 99, 57, 47, 13, 55, 76, 65, 98,
+	59, 24, 22, 63, 58, 49, 66, 64, 61, 62, 48, 5, 9, 110,
+}
+
+// revelationOrder returns the position of chapter id in the order of
+// revelation.
+func revelationOrder(id int) int {
+	return slices.Index(revelationSequence[:], id) + 1
+}
+
+// ChaptersByRevelationOrder returns the chapter summaries in the order the
+// chapters were revealed, rather than mushaf order.
+func (q *QuranService) ChaptersByRevelationOrder(ctx context.Context) ([]ChapterSummary, error) {
+	summaries, err := q.ChaptersSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := slices.Clone(summaries)
+	for i := range out {
+		if out[i].RevelationOrder == 0 {
+			out[i].RevelationOrder = revelationOrder(out[i].ID)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b ChapterSummary) int {
+		return a.RevelationOrder - b.RevelationOrder
+	})
+	return out, nil
+}
+
+// GetChapterByRevelationOrder returns the nth chapter revealed, from 1 to
+// 114.
+func (q *QuranService) GetChapterByRevelationOrder(ctx context.Context, n int) (Chapter, error) {
+	if n < 1 || n > ChapterCount {
+		return Chapter{}, fmt.Errorf("%w: revelation order %d is outside 1-%d", ErrChapterNotFound, n, ChapterCount)
+	}
+	return q.GetChapter(ctx, revelationSequence[n-1])
+}
+
+// ChaptersInRevelationOrder iterates over the chapters in the order they
+// were revealed, fetching each as GetChapter does. Iteration stops after
+// the first error.
+func (q *QuranService) ChaptersInRevelationOrder(ctx context.Context, opts ...ChapterOption) iter.Seq2[Chapter, error] {
+	return func(yield func(Chapter, error) bool) {
+		for _, id := range revelationSequence {
+			chapter, err := q.GetChapter(ctx, id, opts...)
+			if !yield(chapter, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// ChaptersByRevelationPlace returns the summaries of the chapters revealed
+// in place, "makkah" or "madinah", in mushaf order.
+func (q *QuranService) ChaptersByRevelationPlace(ctx context.Context, place string) ([]ChapterSummary, error) {
+	place, err := ParseRevelationPlace(place)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := q.ChaptersSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []ChapterSummary
+	for _, s := range summaries {
+		if s.RevelationPlace == place {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+// VersesRevealedIn iterates over the verses revealed in place, "makkah" or
+// "madinah", in mushaf order. Upstream records the revelation place of
+// chapters only, so verses take their chapter's, including the few that
+// some scholars hold were revealed elsewhere. Iteration stops after the
+// first error.
+func (q *QuranService) VersesRevealedIn(ctx context.Context, place string) iter.Seq2[Verse, error] {
+	return func(yield func(Verse, error) bool) {
+		chapters, err := q.ChaptersByRevelationPlace(ctx, place)
+		if err != nil {
+			yield(Verse{}, err)
+			return
+		}
+		for _, s := range chapters {
+			chapter, err := q.GetChapter(ctx, s.ID)
+			if err != nil {
+				yield(Verse{}, err)
+				return
+			}
+			for _, v := range chapter.Verses {
+				if !yield(v, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type ScopeKind int
+
+const (
+	ScopeChapter ScopeKind = iota
+	ScopeJuz
+	ScopePage
+	ScopeHizb
+	ScopeRub
+)
+
+func (k ScopeKind) String() string {
+	switch k {
+	case ScopeChapter:
+		return "chapter"
+	case ScopeJuz:
+		return "juz"
+	case ScopePage:
+		return "page"
+	case ScopeHizb:
+		return "hizb"
+	case ScopeRub:
+		return "rub"
+	default:
+		return fmt.Sprintf("ScopeKind(%d)", int(k))
+	}
+}
+
+// Scope selects a span of verses: a chapter (optionally narrowed to a verse
+// range), a juz, a hizb (half a juz), a rub' (a quarter of a hizb) or a
+// mushaf page.
+type Scope struct {
+	Kind   ScopeKind
+	Number int
+	// FromVerse and ToVerse narrow a chapter scope; zero leaves that end
+	// open.
+	FromVerse int
+	ToVerse   int
+}
+
+func ChapterScope(chapter int) Scope {
+	return Scope{Kind: ScopeChapter, Number: chapter}
+}
+
+func VerseRangeScope(chapter, from, to int) Scope {
+	return Scope{Kind: ScopeChapter, Number: chapter, FromVerse: from, ToVerse: to}
+}
+
+func JuzScope(juz int) Scope {
+	return Scope{Kind: ScopeJuz, Number: juz}
+}
+
+func PageScope(page int) Scope {
+	return Scope{Kind: ScopePage, Number: page}
+}
+
+func HizbScope(hizb int) Scope {
+	return Scope{Kind: ScopeHizb, Number: hizb}
+}
+
+func RubScope(rub int) Scope {
+	return Scope{Kind: ScopeRub, Number: rub}
+}
+
+// juz returns the juz a hizb or rub' scope is part of.
+func (s Scope) juz() int {
+	if s.Kind == ScopeRub {
+		return (s.Number + 7) / 8
+	}
+	return (s.Number + 1) / 2
+}
+
+func (s Scope) String() string {
+	switch {
+	case s.Kind != ScopeChapter:
+		return fmt.Sprintf("%s %d", s.Kind, s.Number)
+	case s.FromVerse == 0 && s.ToVerse == 0:
+		return fmt.Sprintf("chapter %d", s.Number)
+	case s.ToVerse == 0:
+		return fmt.Sprintf("%d:%d-", s.Number, s.FromVerse)
+	default:
+		return fmt.Sprintf("%d:%d-%d", s.Number, s.FromVerse, s.ToVerse)
+	}
+}
+
+// ParseScope parses the forms written by Scope.String: "chapter 2" or "2",
+// a verse range "2:1-5" or "2:255", "juz 30", "hizb 60", "rub 240" and
+// "page 1". A colon may replace the space after the kind.
+func ParseScope(str string) (Scope, error) {
+	str = strings.TrimSpace(str)
+	for _, kind := range []ScopeKind{ScopeChapter, ScopeJuz, ScopePage, ScopeHizb, ScopeRub} {
+		name := kind.String()
+		rest, ok := strings.CutPrefix(str, name)
+		if !ok || rest == "" || (rest[0] != ' ' && rest[0] != ':') {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(rest[1:]))
+		if err != nil {
+			return Scope{}, fmt.Errorf("invalid scope %q", str)
+		}
+		return Scope{Kind: kind, Number: n}, nil
+	}
+
+	chapter, verses, ok := strings.Cut(str, ":")
+	n, err := strconv.Atoi(chapter)
+	if err != nil {
+		return Scope{}, fmt.Errorf("invalid scope %q", str)
+	}
+	s := ChapterScope(n)
+	if !ok {
+		return s, nil
+	}
+
+	from, to, isRange := strings.Cut(verses, "-")
+	if s.FromVerse, err = strconv.Atoi(from); err != nil {
+		return Scope{}, fmt.Errorf("invalid scope %q", str)
+	}
+	switch {
+	case !isRange:
+		s.ToVerse = s.FromVerse
+	case to != "":
+		if s.ToVerse, err = strconv.Atoi(to); err != nil {
+			return Scope{}, fmt.Errorf("invalid scope %q", str)
+		}
+	}
+	return s, nil
+}
+
+// BareNumberPolicy decides what a bare number such as "36" names, since it
+// could be a chapter, a juz or a page.
+type BareNumberPolicy string
+
+const (
+	BareNumberChapter BareNumberPolicy = "chapter"
+	BareNumberJuz     BareNumberPolicy = "juz"
+	BareNumberPage    BareNumberPolicy = "page"
+	// BareNumberAsk resolves a number valid as only one kind of scope and
+	// returns an *AmbiguousScopeError listing the others.
+	BareNumberAsk BareNumberPolicy = "ask"
+)
+
+func (p BareNumberPolicy) Validate() error {
+	switch p {
+	case "", BareNumberChapter, BareNumberJuz, BareNumberPage, BareNumberAsk:
+		return nil
+	}
+	return fmt.Errorf("invalid bare number policy %q: want chapter, juz, page or ask", string(p))
+}
+
+// AmbiguousScopeError is returned for a bare number under BareNumberAsk
+// when it names more than one existing scope. Front ends offer the
+// candidates to the user; each one's String parses unambiguously.
+type AmbiguousScopeError struct {
+	Input      string
+	Candidates []Scope
+}
+
+func (e *AmbiguousScopeError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, s := range e.Candidates {
+		names[i] = strconv.Quote(s.String())
+	}
+	return fmt.Sprintf("%q is ambiguous: did you mean %s?", e.Input, strings.Join(names, ", "))
+}
+
+// WithBareNumberPolicy sets how ResolveScope reads a bare number. The
+// default is BareNumberChapter, as ParseScope does.
+func WithBareNumberPolicy(p BareNumberPolicy) Option {
+	return func(q *QuranService) {
+		q.bareNumbers = p
+	}
+}
+
+// ResolveScope parses and validates str like ParseScope, reading a bare
+// number according to the service's BareNumberPolicy.
+func (q *QuranService) ResolveScope(str string) (Scope, error) {
+	return ParseScopePolicy(str, q.bareNumbers)
+}
+
+// ParseScopePolicy parses and validates str like ParseScope, reading a
+// bare number according to policy.
+func ParseScopePolicy(str string, policy BareNumberPolicy) (Scope, error) {
+	if err := policy.Validate(); err != nil {
+		return Scope{}, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(str))
+	if err != nil {
+		s, err := ParseScope(str)
+		if err != nil {
+			return Scope{}, err
+		}
+		return s, s.Validate()
+	}
+
+	var s Scope
+	switch policy {
+	case "", BareNumberChapter:
+		s = ChapterScope(n)
+	case BareNumberJuz:
+		s = JuzScope(n)
+	case BareNumberPage:
+		s = PageScope(n)
+	case BareNumberAsk:
+		var candidates []Scope
+		for _, c := range []Scope{ChapterScope(n), JuzScope(n), PageScope(n)} {
+			if c.Validate() == nil {
+				candidates = append(candidates, c)
+			}
+		}
+		switch len(candidates) {
+		case 0:
+			return Scope{}, fmt.Errorf("%d is not a chapter, juz or page", n)
+		case 1:
+			return candidates[0], nil
+		}
+		return Scope{}, &AmbiguousScopeError{Input: str, Candidates: candidates}
+	}
+	return s, s.Validate()
+}
+
+// Validate checks that the scope names an existing chapter, verse range,
+// juz, hizb, rub' or page.
+func (s Scope) Validate() error {
+	switch s.Kind {
+	case ScopeJuz:
+		if s.Number < 1 || s.Number > 30 {
+			return fmt.Errorf("juz %d is outside 1-30", s.Number)
+		}
+		return nil
+	case ScopeHizb:
+		if s.Number < 1 || s.Number > 60 {
+			return fmt.Errorf("hizb %d is outside 1-60", s.Number)
+		}
+		return nil
+	case ScopeRub:
+		if s.Number < 1 || s.Number > 240 {
+			return fmt.Errorf("rub %d is outside 1-240", s.Number)
+		}
+		return nil
+	case ScopePage:
+		return validPage(s.Number)
+	}
+
+	if err := ValidateChapter(s.Number); err != nil {
+		return err
+	}
+	if s.FromVerse != 0 {
+		if err := ValidateVerse(s.Number, s.FromVerse); err != nil {
+			return err
+		}
+	}
+	if s.ToVerse != 0 {
+		if err := ValidateVerse(s.Number, s.ToVerse); err != nil {
+			return err
+		}
+		if s.ToVerse < s.FromVerse {
+			return fmt.Errorf("invalid verse range %s", s)
+		}
+	}
+	return nil
+}
+
+func (s Scope) containsVerse(v Verse) bool {
+	switch s.Kind {
+	case ScopeJuz:
+		return v.JuzNumber == s.Number
+	case ScopePage:
+		return v.PageNumber == s.Number
+	case ScopeHizb:
+		return v.HizbNumber == s.Number
+	case ScopeRub:
+		return v.RubNumber == s.Number
+	default:
+		return v.ChapterID == s.Number &&
+			(s.FromVerse == 0 || v.VerseNumber >= s.FromVerse) &&
+			(s.ToVerse == 0 || v.VerseNumber <= s.ToVerse)
+	}
+}
+
+// ScopeVerses returns the verses in scope in mushaf order.
+func (q *QuranService) ScopeVerses(ctx context.Context, scope Scope) ([]Verse, error) {
+	if scope.Kind == ScopeChapter {
+		chapter, err := q.GetChapter(ctx, scope.Number)
+		if err != nil {
+			return nil, err
+		}
+		return filterVerses(chapter.Verses, scope), nil
+	}
+	if scope.Kind == ScopeHizb || scope.Kind == ScopeRub {
+		// hizbs and quarters aren't indexed: they are read from their juz
+		verses, err := q.ScopeVerses(ctx, JuzScope(scope.juz()))
+		if err != nil {
+			return nil, err
+		}
+		return filterVerses(verses, scope), nil
+	}
+
+	summaries, err := q.ChaptersSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var chapters []int
+	if scope.Kind == ScopeJuz {
+		chapters = juzChapters(scope.Number)
+	} else {
+		for _, summary := range summaries {
+			if scope.Number >= summary.startPage() && scope.Number <= summary.endPage() {
+				chapters = append(chapters, summary.ID)
+			}
+		}
+	}
+	verses, err := q.indexedScopeVerses(ctx, scope, chapters)
+	if err == nil {
+		return verses, nil
+	}
+	indexMissing := errors.Is(err, ErrCacheMiss)
+	if !indexMissing {
+		q.log(ctx).Warn("scope index", "scope", scope.String(), "err", err)
+	}
+
+	var out []Verse
+	for _, summary := range summaries {
+		if scope.Kind == ScopePage && (scope.Number < summary.startPage() || scope.Number > summary.endPage()) {
+			continue
+		}
+
+		chapter, err := q.GetChapter(ctx, summary.ID)
+		if err != nil {
+			return nil, err
+		}
+		if indexMissing && slices.Contains(chapters, summary.ID) {
+			q.backfillVerseIndex(ctx, chapter)
+		}
+
+		verses := filterVerses(chapter.Verses, scope)
+		if len(verses) == 0 && len(out) > 0 {
+			break // scopes are contiguous, so we are past its end
+		}
+		out = append(out, verses...)
+	}
+	return out, nil
+}
+
+func filterVerses(verses []Verse, scope Scope) []Verse {
+	var out []Verse
+	for _, v := range verses {
+		if scope.containsVerse(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
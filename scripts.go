@@ -0,0 +1,184 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+const bucketScripts = "scripts"
+
+// Script is an edition of the Arabic text.
+type Script string
+
+const (
+	ScriptUthmani Script = "uthmani"
+	ScriptIndopak Script = "indopak"
+	// ScriptSimple is the Uthmani text without Quranic annotation signs.
+	ScriptSimple Script = "simple"
+	ScriptImlaei Script = "imlaei"
+	// ScriptUthmaniTajweed is the Uthmani text as HTML, with each tajweed
+	// rule wrapped in <tajweed class="rule"> for color-coded rendering.
+	ScriptUthmaniTajweed Script = "uthmani_tajweed"
+)
+
+var (
+	// ErrUnknownScript is returned for scripts other than the Script
+	// constants.
+	ErrUnknownScript = errors.New("unknown script")
+	// ErrScriptUnavailable is returned for editions the upstream API
+	// doesn't serve, such as ScriptImlaei from APIv3.
+	ErrScriptUnavailable = errors.New("script not served by the upstream API")
+)
+
+// fetched reports whether the script is fetched separately from the
+// chapter, rather than being part of every verse.
+func (s Script) fetched() bool {
+	return slices.Contains(fetchedScripts, s)
+}
+
+func (s Script) Validate() error {
+	switch s {
+	case ScriptUthmani, ScriptIndopak, ScriptSimple, ScriptImlaei, ScriptUthmaniTajweed:
+		return nil
+	}
+	return fmt.Errorf("%w %q", ErrUnknownScript, string(s))
+}
+
+// Text returns the verse in the given script, or "" if that edition was
+// not requested.
+func (v Verse) Text(s Script) string {
+	switch s {
+	case ScriptIndopak:
+		return v.TextIndopak
+	case ScriptSimple:
+		return v.TextSimple
+	case ScriptImlaei:
+		return v.TextImlaei
+	case ScriptUthmaniTajweed:
+		return v.TextUthmaniTajweed
+	default:
+		return v.TextMadani
+	}
+}
+
+func (v *Verse) setText(s Script, text string) {
+	switch s {
+	case ScriptImlaei:
+		v.TextImlaei = text
+	case ScriptUthmaniTajweed:
+		v.TextUthmaniTajweed = text
+	}
+}
+
+type chapterOptions struct {
+	scripts    []Script
+	numbering  BasmalahNumbering
+	companions bool
+}
+
+// ChapterOption adjusts a single GetChapter call.
+type ChapterOption func(*chapterOptions)
+
+// WithScript includes the given editions of the text. The Uthmani,
+// Indopak and simple texts are always present; other editions are fetched
+// the first time they are requested and stored alongside the chapter, from
+// APIv4 only.
+func WithScript(scripts ...Script) ChapterOption {
+	return func(o *chapterOptions) {
+		o.scripts = append(o.scripts, scripts...)
+	}
+}
+
+func (q *QuranService) applyChapterOptions(ctx context.Context, chapter Chapter, opts []ChapterOption) (Chapter, error) {
+	if len(opts) == 0 {
+		return chapter, nil
+	}
+	var o chapterOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// the verses are shared with the memory cache, which keeps the base
+	// chapter only
+	verses := append([]Verse(nil), chapter.Verses...)
+	for _, s := range o.scripts {
+		if err := s.Validate(); err != nil {
+			return Chapter{}, err
+		}
+		if !s.fetched() {
+			continue
+		}
+		texts, err := q.scriptEdition(ctx, s, chapter.ID)
+		if err != nil {
+			return Chapter{}, err
+		}
+		for i := range verses {
+			verses[i].setText(s, texts[verses[i].VerseNumber])
+		}
+	}
+	if o.companions {
+		verses = q.attachCompanions(ctx, verses)
+	}
+	if o.numbering != "" {
+		var err error
+		if verses, err = q.renumber(ctx, chapter, verses, o.numbering); err != nil {
+			return Chapter{}, err
+		}
+	}
+	chapter.Verses = verses
+	return chapter, nil
+}
+
+// fetchedScripts are the editions stored per chapter by scriptEdition.
+var fetchedScripts = []Script{ScriptImlaei, ScriptUthmaniTajweed}
+
+func scriptKey(s Script, chapter int) string {
+	return string(s) + "/" + strconv.Itoa(chapter)
+}
+
+// scriptEdition returns the chapter's text in script s by verse number,
+// from the store or upstream.
+func (q *QuranService) scriptEdition(ctx context.Context, s Script, chapter int) (map[int]string, error) {
+	key := scriptKey(s, chapter)
+	var texts map[int]string
+	err := q.getValue(ctx, bucketScripts, key, &texts)
+	q.cacheEvent(ctx, CacheLayerStore, "script/"+key, err == nil)
+	if err == nil {
+		return texts, nil
+	}
+	if !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+
+	if q.apiVersion != APIv4 {
+		return nil, fmt.Errorf("%w: %s needs the v4 API", ErrScriptUnavailable, s)
+	}
+
+	var resp struct {
+		Verses []Verse `json:"verses"`
+	}
+	path := "/quran/verses/" + string(s)
+	req := q.httpClient.Get(path).QueryParam("chapter_number", strconv.Itoa(chapter))
+	ctx, span := q.startSpan(ctx, "GET "+path)
+	err = q.fetch(ctx, path, req, &resp)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	texts = make(map[int]string, len(resp.Verses))
+	for _, v := range resp.Verses {
+		_, verse, err := v.VerseKey.Split()
+		if err != nil {
+			return nil, fmt.Errorf("%s edition of chapter %d: %w", s, chapter, err)
+		}
+		texts[verse] = v.Text(s)
+	}
+	if err := q.putValue(ctx, bucketScripts, scriptKey(s, chapter), texts); err != nil {
+		q.log(ctx).Error("cache script edition", "script", s, "chapter", chapter, "err", err)
+	}
+	return texts, nil
+}
@@ -0,0 +1,560 @@
+package quranapi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const bucketSearch = "search"
+
+// searchIndexVersion changes whenever tokenization or the index's layout
+// does, so that an index built by an older release is rebuilt rather than
+// queried.
+const searchIndexVersion = "2"
+
+const keySearchVersion = "version"
+
+// SearchHit is a verse matching every term of a query.
+type SearchHit struct {
+	VerseKey VerseKey      `json:"verse_key"`
+	Chapter  int           `json:"chapter"`
+	Verse    int           `json:"verse"`
+	Matches  []SearchMatch `json:"matches"`
+}
+
+// SearchMatch locates query terms in one text of a hit: the Arabic text,
+// or a translation when Translation is set.
+type SearchMatch struct {
+	Translation int    `json:"translation,omitempty"`
+	Text        string `json:"text"`
+	// Offsets are the byte ranges of the matched words in Text, including
+	// their diacritics.
+	Offsets [][2]int `json:"offsets"`
+	// Snippet is the words around the first match with every match
+	// wrapped in the SearchOptions highlight markers.
+	Snippet string `json:"snippet"`
+}
+
+type SearchOptions struct {
+	// Limit caps the hits returned; zero returns every match.
+	Limit int
+	// Cursor resumes after the last hit of a previous page, from
+	// SearchResults.NextCursor.
+	Cursor string
+	// HighlightPre and HighlightPost wrap matches in snippets. They
+	// default to <mark> and </mark>.
+	HighlightPre, HighlightPost string
+	// RevelationPlace, if set, keeps the verses of the chapters revealed
+	// there, as ChaptersByRevelationPlace.
+	RevelationPlace string
+	// Backend overrides the service's search backend, set by
+	// WithSearchBackend, for one query.
+	Backend SearchBackendKind
+}
+
+type SearchResults struct {
+	Hits []SearchHit `json:"hits"`
+	// NextCursor is set when more hits follow.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ErrInvalidCursor is returned for search cursors not issued by Search.
+var ErrInvalidCursor = errors.New("invalid search cursor")
+
+// snippetRadius is the number of words kept on each side of the first
+// match in a snippet.
+const snippetRadius = 8
+
+// chapterPostings maps each term in a chapter to the verses containing it.
+type chapterPostings map[string][]int
+
+// searchIndex is an inverted index over the Arabic text and translations
+// of the stored chapters, with the term counts of each chapter. It is kept
+// in memory and persisted per chapter in the search namespace, so
+// refreshing a chapter rewrites a single pair of entries.
+type searchIndex struct {
+	mu       sync.RWMutex
+	loaded   bool
+	chapters map[int]chapterPostings
+	counts   map[int]chapterCounts
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{chapters: make(map[int]chapterPostings), counts: make(map[int]chapterCounts)}
+}
+
+func buildPostings(chapter Chapter) chapterPostings {
+	postings := make(chapterPostings)
+	add := func(text string, verse int) {
+		for _, term := range tokenize(text) {
+			verses := postings[term]
+			if n := len(verses); n == 0 || verses[n-1] != verse {
+				postings[term] = append(verses, verse)
+			}
+		}
+	}
+	for _, v := range chapter.Verses {
+		add(v.TextMadani, v.VerseNumber)
+		for _, tr := range v.Translations {
+			add(stripTags(tr.Text), v.VerseNumber)
+		}
+	}
+	return postings
+}
+
+func searchKey(chapterID int) string {
+	return "chapter/" + strconv.Itoa(chapterID)
+}
+
+func countsKey(chapterID int) string {
+	return "counts/" + strconv.Itoa(chapterID)
+}
+
+// loadSearchIndex reads the persisted index, rebuilding it from the stored
+// chapters when it is missing or was built by another index version.
+func (q *QuranService) loadSearchIndex(ctx context.Context) error {
+	if err := q.requireFeature(FeatureSearch); err != nil {
+		return err
+	}
+	q.search.mu.RLock()
+	loaded := q.search.loaded
+	q.search.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	var version string
+	err := q.getValue(ctx, bucketSearch, keySearchVersion, &version)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+	if version != searchIndexVersion {
+		return q.RebuildSearchIndex(ctx)
+	}
+
+	chapters := make(map[int]chapterPostings)
+	counts := make(map[int]chapterCounts)
+	err = q.store.Iterate(ctx, bucketSearch, func(key string, value []byte) error {
+		kind, id, ok := strings.Cut(key, "/")
+		if !ok {
+			return nil
+		}
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil
+		}
+		switch kind {
+		case "chapter":
+			var postings chapterPostings
+			if err := valueDecode(value, &postings); err != nil {
+				return err
+			}
+			chapters[n] = postings
+		case "counts":
+			var c chapterCounts
+			if err := valueDecode(value, &c); err != nil {
+				return err
+			}
+			counts[n] = c
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	q.search.mu.Lock()
+	defer q.search.mu.Unlock()
+	if !q.search.loaded {
+		// chapters indexed while loading are newer than the stored copy
+		for id, postings := range q.search.chapters {
+			chapters[id] = postings
+			counts[id] = q.search.counts[id]
+		}
+		q.search.chapters = chapters
+		q.search.counts = counts
+		q.search.loaded = true
+	}
+	return nil
+}
+
+// RebuildSearchIndex indexes every stored chapter from scratch, in the
+// built-in index and the Bleve index when it is open.
+func (q *QuranService) RebuildSearchIndex(ctx context.Context) error {
+	if err := q.requireFeature(FeatureSearch); err != nil {
+		return err
+	}
+	var stale []string
+	err := q.store.Iterate(ctx, bucketSearch, func(key string, value []byte) error {
+		stale = append(stale, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range stale {
+		if err := q.deleteValue(ctx, bucketSearch, key); err != nil {
+			return err
+		}
+	}
+
+	chapters := make(map[int]chapterPostings)
+	counts := make(map[int]chapterCounts)
+	err = q.store.Iterate(ctx, bucketChapters, func(key string, value []byte) error {
+		if key == keyChaptersSummary {
+			return nil
+		}
+		var chapter Chapter
+		if err := valueDecode(value, &chapter); err != nil {
+			return err
+		}
+		chapters[chapter.ID] = buildPostings(chapter)
+		counts[chapter.ID] = buildCounts(chapter)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for id, postings := range chapters {
+		if err := q.putValue(ctx, bucketSearch, searchKey(id), postings); err != nil {
+			return err
+		}
+		if err := q.putValue(ctx, bucketSearch, countsKey(id), counts[id]); err != nil {
+			return err
+		}
+	}
+	if err := q.putValue(ctx, bucketSearch, keySearchVersion, searchIndexVersion); err != nil {
+		return err
+	}
+
+	q.search.mu.Lock()
+	q.search.chapters = chapters
+	q.search.counts = counts
+	q.search.loaded = true
+	q.search.mu.Unlock()
+	for kind, b := range q.searchBackends {
+		if kind == BackendBuiltin {
+			continue
+		}
+		if err := q.reindex(ctx, b); err != nil {
+			return fmt.Errorf("search backend %s: %w", kind, err)
+		}
+	}
+	return nil
+}
+
+// builtinSearch is the SearchBackend of the built-in index.
+type builtinSearch struct {
+	q *QuranService
+}
+
+func (b builtinSearch) Index(ctx context.Context, chapter Chapter) error {
+	q := b.q
+	postings, counts := buildPostings(chapter), buildCounts(chapter)
+	if err := q.putValue(ctx, bucketSearch, searchKey(chapter.ID), postings); err != nil {
+		return err
+	}
+	if err := q.putValue(ctx, bucketSearch, countsKey(chapter.ID), counts); err != nil {
+		return err
+	}
+	q.search.mu.Lock()
+	q.search.chapters[chapter.ID] = postings
+	q.search.counts[chapter.ID] = counts
+	q.search.mu.Unlock()
+	return nil
+}
+
+func (b builtinSearch) Delete(ctx context.Context, id int) error {
+	q := b.q
+	q.search.mu.Lock()
+	delete(q.search.chapters, id)
+	delete(q.search.counts, id)
+	q.search.mu.Unlock()
+	if err := q.deleteValue(ctx, bucketSearch, countsKey(id)); err != nil {
+		return err
+	}
+	return q.deleteValue(ctx, bucketSearch, searchKey(id))
+}
+
+func (b builtinSearch) Query(ctx context.Context, query SearchQuery) ([]SearchHit, bool, error) {
+	q := b.q
+	terms := tokenize(query.Text)
+	if len(terms) == 0 {
+		return nil, false, fmt.Errorf("empty search query %q", query.Text)
+	}
+	if err := q.loadSearchIndex(ctx); err != nil {
+		return nil, false, err
+	}
+	first := 0
+	if query.After != "" {
+		first = query.After.Chapter()
+	}
+
+	q.search.mu.RLock()
+	defer q.search.mu.RUnlock()
+	ids := make([]int, 0, len(q.search.chapters))
+	for id := range q.search.chapters {
+		if id >= first && (query.Chapters == nil || slices.Contains(query.Chapters, id)) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	var hits []SearchHit
+	for _, id := range ids {
+		for _, verse := range q.search.chapters[id].match(terms) {
+			if !query.after(id, verse) {
+				continue
+			}
+			if query.Limit > 0 && len(hits) == query.Limit {
+				return hits, true, nil
+			}
+			hits = append(hits, SearchHit{VerseKey: NewVerseKey(id, verse), Chapter: id, Verse: verse})
+		}
+	}
+	return hits, false, nil
+}
+
+// Search returns verses, in mushaf order, whose Arabic text or
+// translations contain every word of query, with where each text matched.
+// Arabic is matched without diacritics. The search backend, set by
+// WithSearchBackend or SearchOptions.Backend, answers the query; the
+// Bleve backend also takes phrases, prefixes and fuzzy words.
+func (q *QuranService) Search(ctx context.Context, query string, opts SearchOptions) (SearchResults, error) {
+	var results SearchResults
+	if err := q.requireFeature(FeatureSearch); err != nil {
+		return results, err
+	}
+	backend, err := q.searchBackend(opts.Backend)
+	if err != nil {
+		return results, err
+	}
+	after, err := decodeCursor(opts.Cursor)
+	if err != nil {
+		return results, err
+	}
+	if opts.HighlightPre == "" && opts.HighlightPost == "" {
+		opts.HighlightPre, opts.HighlightPost = "<mark>", "</mark>"
+	}
+	sq := SearchQuery{
+		Text:          query,
+		Limit:         opts.Limit,
+		HighlightPre:  opts.HighlightPre,
+		HighlightPost: opts.HighlightPost,
+	}
+	if after != [2]int{} {
+		sq.After = NewVerseKey(after[0], after[1])
+	}
+	if opts.RevelationPlace != "" {
+		chapters, err := q.ChaptersByRevelationPlace(ctx, opts.RevelationPlace)
+		if err != nil {
+			return results, err
+		}
+		sq.Chapters = make([]int, 0, len(chapters))
+		for _, c := range chapters {
+			sq.Chapters = append(sq.Chapters, c.ID)
+		}
+	}
+
+	hits, more, err := backend.Query(ctx, sq)
+	if err != nil {
+		return results, err
+	}
+	if err := q.locateMatches(ctx, hits, queryTerms(query), opts); err != nil {
+		return results, err
+	}
+	results.Hits = hits
+	if more && len(hits) > 0 {
+		last := hits[len(hits)-1]
+		results.NextCursor = encodeCursor(last.Chapter, last.Verse)
+	}
+
+	q.hooks.Search(ctx, query, len(hits))
+	return results, nil
+}
+
+// locateMatches fills in the matches of each hit without any from its
+// stored chapter.
+func (q *QuranService) locateMatches(ctx context.Context, hits []SearchHit, terms []string, opts SearchOptions) error {
+	want := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		want[t] = true
+	}
+
+	var chapter Chapter
+	for i := range hits {
+		hit := &hits[i]
+		if len(hit.Matches) > 0 {
+			continue
+		}
+		if chapter.ID != hit.Chapter {
+			var err error
+			if chapter, err = q.GetChapter(ctx, hit.Chapter); err != nil {
+				return err
+			}
+		}
+		if hit.Verse > len(chapter.Verses) {
+			continue // the index is ahead of or behind the stored chapter
+		}
+
+		v := chapter.Verses[hit.Verse-1]
+		if m, ok := matchText(v.TextMadani, want, opts); ok {
+			hit.Matches = append(hit.Matches, m)
+		}
+		for _, tr := range v.Translations {
+			if m, ok := matchText(stripTags(tr.Text), want, opts); ok {
+				m.Translation = tr.ResourceID
+				hit.Matches = append(hit.Matches, m)
+			}
+		}
+	}
+	return nil
+}
+
+// matchText finds the words of text in want and builds its snippet.
+func matchText(text string, want map[string]bool, opts SearchOptions) (SearchMatch, bool) {
+	spans := tokenSpans(text)
+	first := -1
+	m := SearchMatch{Text: text}
+	matched := make([]bool, len(spans))
+	for i, s := range spans {
+		if want[s.term] {
+			if first < 0 {
+				first = i
+			}
+			matched[i] = true
+			m.Offsets = append(m.Offsets, [2]int{s.start, s.end})
+		}
+	}
+	if first < 0 {
+		return m, false
+	}
+
+	from, to := max(first-snippetRadius, 0), min(first+snippetRadius, len(spans)-1)
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("… ")
+	}
+	pos := spans[from].start
+	for i := from; i <= to; i++ {
+		s := spans[i]
+		b.WriteString(text[pos:s.start])
+		if matched[i] {
+			b.WriteString(opts.HighlightPre + text[s.start:s.end] + opts.HighlightPost)
+		} else {
+			b.WriteString(text[s.start:s.end])
+		}
+		pos = s.end
+	}
+	if to < len(spans)-1 {
+		b.WriteString(" …")
+	} else {
+		b.WriteString(text[pos:])
+	}
+	m.Snippet = b.String()
+	return m, true
+}
+
+// encodeCursor makes an opaque cursor resuming after the given verse.
+func encodeCursor(chapter, verse int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(verseKey(chapter, verse)))
+}
+
+func decodeCursor(cursor string) ([2]int, error) {
+	if cursor == "" {
+		return [2]int{}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return [2]int{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	chapter, verse, err := ValidateVerseKey(string(b))
+	if err != nil {
+		return [2]int{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return [2]int{chapter, verse}, nil
+}
+
+// match returns the verses containing every term.
+func (p chapterPostings) match(terms []string) []int {
+	out := p[terms[0]]
+	for _, term := range terms[1:] {
+		if len(out) == 0 {
+			return nil
+		}
+		out = intersect(out, p[term])
+	}
+	return out
+}
+
+// intersect returns the values in both sorted slices.
+func intersect(a, b []int) []int {
+	var out []int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+func runSearch(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	rebuild := fs.Bool("rebuild", false, "rebuild the search index")
+	limit := fs.Int("limit", 0, "maximum hits, 0 for all")
+	cursor := fs.String("cursor", "", "resume from a previous page")
+	place := fs.String("place", "", "only chapters revealed in makkah or madinah")
+	backend := fs.String("backend", "", "search backend: builtin, bleve or es (default: configured)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := SearchBackendKind(*backend).Validate(); err != nil {
+		return err
+	}
+	if *rebuild {
+		if SearchBackendKind(*backend) == BackendElasticsearch {
+			return q.RebuildElasticsearchIndex(ctx)
+		}
+		return q.RebuildSearchIndex(ctx)
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: search [-backend b] [-limit n] [-cursor c] [-place p] <words> | search [-backend b] -rebuild")
+	}
+
+	results, err := q.Search(ctx, strings.Join(fs.Args(), " "), SearchOptions{
+		Limit:           *limit,
+		Cursor:          *cursor,
+		HighlightPre:    "\x1b[1m",
+		HighlightPost:   "\x1b[0m",
+		RevelationPlace: *place,
+		Backend:         SearchBackendKind(*backend),
+	})
+	if err != nil {
+		return err
+	}
+	for _, hit := range results.Hits {
+		fmt.Println(hit.VerseKey)
+		for _, m := range hit.Matches {
+			fmt.Printf("\t%s\n", m.Snippet)
+		}
+	}
+	if results.NextCursor != "" {
+		fmt.Printf("more: -cursor %s\n", results.NextCursor)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// SearchBackend indexes the stored chapters and answers queries over them.
+// The service keeps every configured backend in sync with the store as
+// chapters are stored and deleted, and Search asks the one selected.
+type SearchBackend interface {
+	// Index replaces the entries of the verses of chapter.
+	Index(ctx context.Context, chapter Chapter) error
+	// Delete drops the entries of the verses of a chapter.
+	Delete(ctx context.Context, chapter int) error
+	// Query returns the hits of q in mushaf order, at most q.Limit when
+	// it is set, and whether more hits follow. Hits may leave Matches
+	// empty for Search to locate the query words in the stored verses.
+	Query(ctx context.Context, q SearchQuery) (hits []SearchHit, more bool, err error)
+}
+
+// SearchQuery is a query for a SearchBackend.
+type SearchQuery struct {
+	Text string
+	// After is the last hit of the previous page, or empty for the first.
+	After VerseKey
+	// Limit caps the hits returned; zero returns every match.
+	Limit int
+	// Chapters, if not nil, restricts hits to these chapters.
+	Chapters []int
+	// HighlightPre and HighlightPost wrap matches in snippets.
+	HighlightPre, HighlightPost string
+}
+
+// after reports whether the verse comes after q.After.
+func (q SearchQuery) after(chapter, verse int) bool {
+	if q.After == "" {
+		return true
+	}
+	c, v, err := q.After.Split()
+	return err != nil || chapter > c || chapter == c && verse > v
+}
+
+// SearchBackendKind names a SearchBackend.
+type SearchBackendKind string
+
+const (
+	// BackendBuiltin is the inverted index kept in the store.
+	BackendBuiltin SearchBackendKind = "builtin"
+	// BackendBleve is an embedded Bleve index on disk, supporting phrase,
+	// fuzzy and prefix queries. It needs a build with -tags bleve.
+	BackendBleve SearchBackendKind = "bleve"
+	// BackendElasticsearch delegates to the index configured with
+	// WithElasticsearch.
+	BackendElasticsearch SearchBackendKind = "es"
+	// BackendCustom is the backend set by WithCustomSearchBackend.
+	BackendCustom SearchBackendKind = "custom"
+)
+
+func (k SearchBackendKind) Validate() error {
+	switch k {
+	case "", BackendBuiltin, BackendBleve, BackendElasticsearch, BackendCustom:
+		return nil
+	}
+	return fmt.Errorf("invalid search backend %q: want builtin, bleve or es", k)
+}
+
+// WithSearchBackend sets the backend answering Search when SearchOptions
+// leaves Backend empty. It defaults to BackendBuiltin, or BackendCustom
+// with WithCustomSearchBackend. The built-in index is kept up to date
+// whichever is set, so that switching back needs no rebuild.
+func WithSearchBackend(kind SearchBackendKind) Option {
+	return func(q *QuranService) {
+		q.searchKind = kind
+	}
+}
+
+// WithCustomSearchBackend makes b the search backend, as BackendCustom.
+func WithCustomSearchBackend(b SearchBackend) Option {
+	return func(q *QuranService) {
+		q.searchBackends[BackendCustom] = b
+		if q.searchKind == "" {
+			q.searchKind = BackendCustom
+		}
+	}
+}
+
+// openSearchBackends opens the backends configured by the options besides
+// the built-in one.
+func (q *QuranService) openSearchBackends() error {
+	if q.searchKind == "" {
+		q.searchKind = BackendBuiltin
+	}
+	if err := q.searchKind.Validate(); err != nil {
+		return err
+	}
+	if q.elastic != nil {
+		q.searchBackends[BackendElasticsearch] = q.elastic
+	}
+	if q.searchKind == BackendBleve {
+		b, err := q.openBleve()
+		if err != nil {
+			return err
+		}
+		q.searchBackends[BackendBleve] = b
+	}
+	if q.searchBackends[q.searchKind] == nil {
+		return fmt.Errorf("search backend %s is not configured", q.searchKind)
+	}
+	return nil
+}
+
+// searchBackend returns the backend of kind, or the default one for an
+// empty kind.
+func (q *QuranService) searchBackend(kind SearchBackendKind) (SearchBackend, error) {
+	if kind == "" {
+		kind = q.searchKind
+	}
+	if err := kind.Validate(); err != nil {
+		return nil, err
+	}
+	b := q.searchBackends[kind]
+	if b == nil {
+		return nil, fmt.Errorf("search backend %s is not configured", kind)
+	}
+	return b, nil
+}
+
+// indexChapter replaces the entries of chapter in every search backend.
+// Failures of the built-in index fail the store write; those of the
+// others are logged, and RebuildSearchIndex catches them up.
+func (q *QuranService) indexChapter(ctx context.Context, chapter Chapter) error {
+	if !q.FeatureEnabled(FeatureSearch) {
+		return nil
+	}
+	for kind, b := range q.searchBackends {
+		err := b.Index(ctx, chapter)
+		if err != nil && kind == BackendBuiltin {
+			return err
+		}
+		if err != nil {
+			q.log(ctx).Warn("search index", "backend", kind, "chapter", chapter.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+func (q *QuranService) unindexChapter(ctx context.Context, id int) error {
+	for kind, b := range q.searchBackends {
+		err := b.Delete(ctx, id)
+		if err != nil && kind == BackendBuiltin {
+			return err
+		}
+		if err != nil {
+			q.log(ctx).Warn("search unindex", "backend", kind, "chapter", id, "err", err)
+		}
+	}
+	return nil
+}
+
+// Close waits for the webhook deliveries of events already emitted, and
+// releases the files held open by the search backends. The store is closed
+// by its owner.
+func (q *QuranService) Close() error {
+	q.stopWebhooks()
+	var errs []error
+	for _, b := range q.searchBackends {
+		if c, ok := b.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reindex indexes every stored chapter in b and drops the chapters no
+// longer stored.
+func (q *QuranService) reindex(ctx context.Context, b SearchBackend) error {
+	for id := 1; id <= ChapterCount; id++ {
+		chapter, err := q.getChapterDB(ctx, id)
+		switch {
+		case errors.Is(err, ErrCacheMiss):
+			err = b.Delete(ctx, id)
+		case err == nil:
+			err = b.Index(ctx, chapter)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pageVerses returns the hits at verses, sorted into mushaf order, that
+// the query keeps.
+func pageVerses(verses [][2]int, query SearchQuery) ([]SearchHit, bool) {
+	slices.SortFunc(verses, func(a, b [2]int) int {
+		if a[0] != b[0] {
+			return a[0] - b[0]
+		}
+		return a[1] - b[1]
+	})
+	var hits []SearchHit
+	for _, v := range verses {
+		if !query.after(v[0], v[1]) || query.Chapters != nil && !slices.Contains(query.Chapters, v[0]) {
+			continue
+		}
+		if query.Limit > 0 && len(hits) == query.Limit {
+			return hits, true
+		}
+		hits = append(hits, SearchHit{VerseKey: NewVerseKey(v[0], v[1]), Chapter: v[0], Verse: v[1]})
+	}
+	return hits, false
+}
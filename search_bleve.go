@@ -0,0 +1,173 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// defaultBlevePath is where WithSearchBackend(BackendBleve) keeps the index
+// without WithBleveIndex.
+const defaultBlevePath = "quran.db.bleve"
+
+// errBleveUnavailable is returned for BackendBleve by builds without the
+// bleve tag.
+var errBleveUnavailable = errors.New("search backend bleve: built without -tags bleve")
+
+// bleveIndex is the Bleve index of the stored chapters, implemented in
+// builds with the bleve tag.
+type bleveIndex interface {
+	index(chapter Chapter) error
+	delete(chapter int) error
+	// query returns the verses matching every clause, in any order.
+	query(ctx context.Context, clauses []queryClause) ([]VerseKey, error)
+	close() error
+}
+
+// WithBleveIndex sets the directory of the Bleve index, which defaults to
+// quran.db.bleve. The index is created on first use and kept in sync with
+// the store as chapters are stored and deleted.
+func WithBleveIndex(path string) Option {
+	return func(q *QuranService) {
+		q.blevePath = path
+	}
+}
+
+// bleveBackend is the SearchBackend of a Bleve index.
+type bleveBackend struct {
+	idx bleveIndex
+}
+
+// openBleve opens the Bleve index at the path set by WithBleveIndex.
+func (q *QuranService) openBleve() (*bleveBackend, error) {
+	path := q.blevePath
+	if path == "" {
+		path = defaultBlevePath
+	}
+	idx, err := openBleveIndex(path)
+	if err != nil {
+		return nil, err
+	}
+	return &bleveBackend{idx: idx}, nil
+}
+
+func (b *bleveBackend) Index(_ context.Context, chapter Chapter) error {
+	return b.idx.index(chapter)
+}
+
+func (b *bleveBackend) Delete(_ context.Context, chapter int) error {
+	return b.idx.delete(chapter)
+}
+
+// Query matches phrases, prefixes and fuzzy words as parseQuery reads
+// them, leaving the matches of the hits to Search.
+func (b *bleveBackend) Query(ctx context.Context, query SearchQuery) ([]SearchHit, bool, error) {
+	clauses, err := parseQuery(query.Text)
+	if err != nil {
+		return nil, false, err
+	}
+	keys, err := b.idx.query(ctx, clauses)
+	if err != nil {
+		return nil, false, err
+	}
+	verses := make([][2]int, len(keys))
+	for i, key := range keys {
+		chapter, verse, err := key.Split()
+		if err != nil {
+			return nil, false, fmt.Errorf("bleve index: %w", err)
+		}
+		verses[i] = [2]int{chapter, verse}
+	}
+	hits, more := pageVerses(verses, query)
+	return hits, more, nil
+}
+
+func (b *bleveBackend) Close() error {
+	return b.idx.close()
+}
+
+// queryKind is how a clause of a query matches words.
+type queryKind int
+
+const (
+	queryWord queryKind = iota
+	// queryPhrase matches the words of a "quoted phrase" in order.
+	queryPhrase
+	// queryPrefix matches words starting with a word ending in *.
+	queryPrefix
+	// queryFuzzy matches words within an edit or two, given as ~ or ~2,
+	// of a word ending in ~.
+	queryFuzzy
+)
+
+// queryClause is a clause of a query, every one of which a verse must
+// match.
+type queryClause struct {
+	kind      queryKind
+	text      string
+	fuzziness int
+}
+
+// parseQuery splits a query into clauses: quoted phrases, word* prefixes,
+// word~ fuzzy words and plain words.
+func parseQuery(query string) ([]queryClause, error) {
+	var clauses []queryClause
+	for rest := strings.TrimSpace(query); rest != ""; rest = strings.TrimLeftFunc(rest, unicode.IsSpace) {
+		if phrase, ok := strings.CutPrefix(rest, `"`); ok {
+			end := strings.IndexByte(phrase, '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated phrase in %q", query)
+			}
+			if text := strings.TrimSpace(phrase[:end]); text != "" {
+				clauses = append(clauses, queryClause{kind: queryPhrase, text: text})
+			}
+			rest = phrase[end+1:]
+			continue
+		}
+
+		word := rest
+		if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+			word, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+		c := queryClause{kind: queryWord, text: word}
+		if text, ok := strings.CutSuffix(word, "*"); ok {
+			c = queryClause{kind: queryPrefix, text: text}
+		} else if i := strings.LastIndexByte(word, '~'); i > 0 {
+			c = queryClause{kind: queryFuzzy, text: word[:i], fuzziness: 1}
+			if n := word[i+1:]; n != "" {
+				f, err := strconv.Atoi(n)
+				if err != nil || f < 1 || f > 2 {
+					return nil, fmt.Errorf("invalid fuzziness %q: want ~, ~1 or ~2", word)
+				}
+				c.fuzziness = f
+			}
+		}
+		if len(tokenize(c.text)) > 0 {
+			clauses = append(clauses, c)
+		}
+	}
+	if len(clauses) == 0 {
+		return nil, fmt.Errorf("empty search query %q", query)
+	}
+	return clauses, nil
+}
+
+// queryTerms returns the words of query, as tokenize does, for locating
+// matches. Prefixes and fuzzy words are located only where they match
+// exactly.
+func queryTerms(query string) []string {
+	clauses, err := parseQuery(query)
+	if err != nil {
+		return tokenize(query)
+	}
+	var terms []string
+	for _, c := range clauses {
+		terms = append(terms, tokenize(c.text)...)
+	}
+	return terms
+}
@@ -0,0 +1,225 @@
+//go:build bleve
+
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/analysis/lang/ar"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/analysis/lang/ckb"
+	"github.com/blevesearch/bleve/v2/analysis/lang/da"
+	"github.com/blevesearch/bleve/v2/analysis/lang/de"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/analysis/lang/es"
+	"github.com/blevesearch/bleve/v2/analysis/lang/fa"
+	"github.com/blevesearch/bleve/v2/analysis/lang/fi"
+	"github.com/blevesearch/bleve/v2/analysis/lang/fr"
+	"github.com/blevesearch/bleve/v2/analysis/lang/hi"
+	"github.com/blevesearch/bleve/v2/analysis/lang/hu"
+	"github.com/blevesearch/bleve/v2/analysis/lang/it"
+	"github.com/blevesearch/bleve/v2/analysis/lang/nl"
+	"github.com/blevesearch/bleve/v2/analysis/lang/no"
+	"github.com/blevesearch/bleve/v2/analysis/lang/pt"
+	"github.com/blevesearch/bleve/v2/analysis/lang/ro"
+	"github.com/blevesearch/bleve/v2/analysis/lang/ru"
+	"github.com/blevesearch/bleve/v2/analysis/lang/sv"
+	"github.com/blevesearch/bleve/v2/analysis/lang/tr"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// bleveArabic analyzes the Arabic text, normalized by tokenize before it
+// is indexed, into its words as they are.
+const bleveArabic = "quran_ar"
+
+// bleveLanguages maps upstream language names to Bleve analyzers. The
+// translations in other languages use the standard analyzer.
+var bleveLanguages = map[string]string{
+	"chinese":    cjk.AnalyzerName,
+	"danish":     da.AnalyzerName,
+	"dutch":      nl.AnalyzerName,
+	"english":    en.AnalyzerName,
+	"finnish":    fi.AnalyzerName,
+	"french":     fr.AnalyzerName,
+	"german":     de.AnalyzerName,
+	"hindi":      hi.AnalyzerName,
+	"hungarian":  hu.AnalyzerName,
+	"italian":    it.AnalyzerName,
+	"japanese":   cjk.AnalyzerName,
+	"korean":     cjk.AnalyzerName,
+	"kurdish":    ckb.AnalyzerName,
+	"norwegian":  no.AnalyzerName,
+	"persian":    fa.AnalyzerName,
+	"portuguese": pt.AnalyzerName,
+	"romanian":   ro.AnalyzerName,
+	"russian":    ru.AnalyzerName,
+	"spanish":    es.AnalyzerName,
+	"swedish":    sv.AnalyzerName,
+	"turkish":    tr.AnalyzerName,
+}
+
+// bleveTranslationField is the field holding the translations of a verse
+// in language.
+func bleveTranslationField(language string) string {
+	return "translation_" + strings.ReplaceAll(strings.ToLower(strings.TrimSpace(language)), " ", "_")
+}
+
+type bleveStore struct {
+	idx bleve.Index
+}
+
+func openBleveIndex(path string) (bleveIndex, error) {
+	idx, err := bleve.Open(path)
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		var m mapping.IndexMapping
+		if m, err = bleveMapping(); err == nil {
+			idx, err = bleve.New(path, m)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bleve index %s: %w", path, err)
+	}
+	return &bleveStore{idx: idx}, nil
+}
+
+// bleveMapping indexes a verse's Arabic twice: word for word in text, for
+// phrases, prefixes and fuzzy words, and stemmed by the Arabic analyzer in
+// stemmed. Translations are analyzed by the analyzer of their language.
+func bleveMapping() (mapping.IndexMapping, error) {
+	m := bleve.NewIndexMapping()
+	err := m.AddCustomAnalyzer(bleveArabic, map[string]any{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.DefaultAnalyzer = standard.Name
+
+	doc := bleve.NewDocumentMapping()
+	field := func(analyzer string) *mapping.FieldMapping {
+		f := bleve.NewTextFieldMapping()
+		f.Analyzer = analyzer
+		f.Store = false
+		f.IncludeInAll = false
+		return f
+	}
+	doc.AddFieldMappingsAt("text", field(bleveArabic))
+	doc.AddFieldMappingsAt("stemmed", field(ar.AnalyzerName))
+	for language, analyzer := range bleveLanguages {
+		doc.AddFieldMappingsAt(bleveTranslationField(language), field(analyzer))
+	}
+	m.DefaultMapping = doc
+	return m, nil
+}
+
+func (b *bleveStore) index(chapter Chapter) error {
+	batch := b.idx.NewBatch()
+	for _, v := range chapter.Verses {
+		text := strings.Join(tokenize(v.TextMadani), " ")
+		doc := map[string]string{"text": text, "stemmed": text}
+		for _, tr := range v.Translations {
+			f := bleveTranslationField(tr.LanguageName)
+			if doc[f] != "" {
+				doc[f] += "\n"
+			}
+			doc[f] += stripTags(tr.Text)
+		}
+		if err := batch.Index(v.VerseKey.String(), doc); err != nil {
+			return err
+		}
+	}
+	// drop verses beyond those of chapter, left by an earlier version
+	for n := len(chapter.Verses) + 1; n <= chapterVerseCounts[chapter.ID-1]; n++ {
+		batch.Delete(verseKey(chapter.ID, n))
+	}
+	return b.idx.Batch(batch)
+}
+
+func (b *bleveStore) delete(chapter int) error {
+	batch := b.idx.NewBatch()
+	for n := 1; n <= chapterVerseCounts[chapter-1]; n++ {
+		batch.Delete(verseKey(chapter, n))
+	}
+	return b.idx.Batch(batch)
+}
+
+func (b *bleveStore) query(ctx context.Context, clauses []queryClause) ([]VerseKey, error) {
+	fields, err := b.idx.Fields()
+	if err != nil {
+		return nil, err
+	}
+	var translations []string
+	for _, f := range fields {
+		if strings.HasPrefix(f, "translation_") {
+			translations = append(translations, f)
+		}
+	}
+
+	conjuncts := make([]query.Query, 0, len(clauses))
+	for _, c := range clauses {
+		arabic := strings.Join(tokenize(c.text), " ")
+		var disjuncts []query.Query
+		switch c.kind {
+		case queryWord:
+			disjuncts = append(disjuncts, bleveMatch(arabic, "text"), bleveMatch(c.text, "stemmed"))
+			for _, f := range translations {
+				disjuncts = append(disjuncts, bleveMatch(c.text, f))
+			}
+		case queryPhrase:
+			q := bleve.NewMatchPhraseQuery(arabic)
+			q.SetField("text")
+			disjuncts = append(disjuncts, q)
+			for _, f := range translations {
+				q := bleve.NewMatchPhraseQuery(c.text)
+				q.SetField(f)
+				disjuncts = append(disjuncts, q)
+			}
+		case queryPrefix:
+			for _, f := range append([]string{"text"}, translations...) {
+				q := bleve.NewPrefixQuery(arabic)
+				q.SetField(f)
+				disjuncts = append(disjuncts, q)
+			}
+		case queryFuzzy:
+			for _, f := range append([]string{"text"}, translations...) {
+				q := bleve.NewFuzzyQuery(arabic)
+				q.SetFuzziness(c.fuzziness)
+				q.SetField(f)
+				disjuncts = append(disjuncts, q)
+			}
+		}
+		conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(disjuncts...))
+	}
+
+	req := bleve.NewSearchRequestOptions(bleve.NewConjunctionQuery(conjuncts...), VerseCount, 0, false)
+	res, err := b.idx.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]VerseKey, len(res.Hits))
+	for i, hit := range res.Hits {
+		keys[i] = VerseKey(hit.ID)
+	}
+	return keys, nil
+}
+
+func bleveMatch(text, field string) query.Query {
+	q := bleve.NewMatchQuery(text)
+	q.SetField(field)
+	return q
+}
+
+func (b *bleveStore) close() error {
+	return b.idx.Close()
+}
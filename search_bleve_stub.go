@@ -0,0 +1,7 @@
+//go:build !bleve
+
+package quranapi
+
+func openBleveIndex(string) (bleveIndex, error) {
+	return nil, errBleveUnavailable
+}
@@ -0,0 +1,413 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// ElasticsearchConfig points the service at an Elasticsearch or OpenSearch
+// index, for deployments searching at a scale the built-in index is not
+// meant for.
+type ElasticsearchConfig struct {
+	URL string `yaml:"url" toml:"url"`
+	// Index defaults to "quran".
+	Index string `yaml:"index" toml:"index"`
+	// APIKey is sent as an ApiKey authorization; otherwise Username and
+	// Password, when set, are sent as basic auth.
+	APIKey   string `yaml:"api_key" toml:"api_key"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+// elasticBatch is the most hits requested per search request when paging
+// through every hit.
+const elasticBatch = 1000
+
+// elasticIndexSettings analyzes the Arabic text with the diacritics and
+// Quranic annotation signs stripped, as normalizeArabic does, and with the
+// Arabic normalization of alef, ya and ta marbuta, keeping every word; the
+// text.stemmed subfield also drops stop words and stems. Translations are
+// indexed per resource as translation_<id>, folded to ASCII.
+var elasticIndexSettings = json.RawMessage(`{
+  "settings": {
+    "analysis": {
+      "char_filter": {
+        "quran_marks": {
+          "type": "pattern_replace",
+          "pattern": "[\\u0610-\\u061A\\u064B-\\u065F\\u0670\\u06D6-\\u06ED\\u0640]",
+          "replacement": ""
+        }
+      },
+      "analyzer": {
+        "quran_arabic": {
+          "type": "custom",
+          "char_filter": ["quran_marks"],
+          "tokenizer": "standard",
+          "filter": ["lowercase", "decimal_digit", "arabic_normalization"]
+        },
+        "quran_arabic_stemmed": {
+          "type": "custom",
+          "char_filter": ["quran_marks"],
+          "tokenizer": "standard",
+          "filter": ["lowercase", "decimal_digit", "arabic_stop", "arabic_normalization", "arabic_stemmer"]
+        },
+        "quran_translation": {
+          "type": "custom",
+          "char_filter": ["html_strip"],
+          "tokenizer": "standard",
+          "filter": ["lowercase", "asciifolding"]
+        }
+      },
+      "filter": {
+        "arabic_stop": {"type": "stop", "stopwords": "_arabic_"},
+        "arabic_stemmer": {"type": "stemmer", "language": "arabic"}
+      }
+    }
+  },
+  "mappings": {
+    "dynamic_templates": [
+      {
+        "translations": {
+          "match": "translation_*",
+          "mapping": {"type": "text", "analyzer": "quran_translation"}
+        }
+      }
+    ],
+    "properties": {
+      "verse_key": {"type": "keyword"},
+      "chapter": {"type": "integer"},
+      "verse": {"type": "integer"},
+      "juz": {"type": "integer"},
+      "page": {"type": "integer"},
+      "revelation_place": {"type": "keyword"},
+      "text": {
+        "type": "text",
+        "analyzer": "quran_arabic",
+        "fields": {
+          "stemmed": {"type": "text", "analyzer": "quran_arabic_stemmed"}
+        }
+      }
+    }
+  }
+}`)
+
+// elasticIndex is a client of the index configured with WithElasticsearch.
+type elasticIndex struct {
+	cfg  ElasticsearchConfig
+	doer Doer
+	// ready is set once the index is known to exist.
+	ready atomic.Bool
+}
+
+// WithElasticsearch pushes the verses and translations of every chapter
+// stored into the index in cfg, and lets Search delegate queries to it
+// with BackendElasticsearch. Pushing is best effort: failures are logged,
+// and RebuildElasticsearchIndex pushes every stored chapter again.
+// Requests use the service's Doer.
+func WithElasticsearch(cfg ElasticsearchConfig) Option {
+	return func(q *QuranService) {
+		if cfg.Index == "" {
+			cfg.Index = "quran"
+		}
+		q.elastic = &elasticIndex{cfg: cfg}
+	}
+}
+
+// do sends a request to path under the index, decoding a JSON response
+// into out when it is not nil. A 404 is returned as ErrKeyNotFound.
+func (e *elasticIndex) do(ctx context.Context, method, path, contentType string, body []byte, out any) error {
+	u := strings.TrimSuffix(e.cfg.URL, "/") + "/" + url.PathEscape(e.cfg.Index) + path
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case e.cfg.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.cfg.APIKey)
+	case e.cfg.Username != "":
+		req.SetBasicAuth(e.cfg.Username, e.cfg.Password)
+	}
+
+	resp, err := e.doer.Do(req)
+	if err != nil {
+		return fmt.Errorf("elasticsearch: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("elasticsearch %s %s: %w", method, path, ErrKeyNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("elasticsearch %s %s: status %d: %s", method, path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (e *elasticIndex) doJSON(ctx context.Context, method, path string, body, out any) error {
+	var b []byte
+	if body != nil {
+		var err error
+		if b, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	return e.do(ctx, method, path, "application/json", b, out)
+}
+
+// ensure creates the index unless it exists.
+func (e *elasticIndex) ensure(ctx context.Context) error {
+	if e.ready.Load() {
+		return nil
+	}
+	err := e.do(ctx, http.MethodHead, "", "", nil, nil)
+	if errors.Is(err, ErrKeyNotFound) {
+		err = e.do(ctx, http.MethodPut, "", "application/json", elasticIndexSettings, nil)
+	}
+	if err != nil {
+		return err
+	}
+	e.ready.Store(true)
+	return nil
+}
+
+// recreate deletes the index, with everything in it, and creates it again.
+func (e *elasticIndex) recreate(ctx context.Context) error {
+	if err := e.do(ctx, http.MethodDelete, "", "", nil, nil); err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+	if err := e.do(ctx, http.MethodPut, "", "application/json", elasticIndexSettings, nil); err != nil {
+		return err
+	}
+	e.ready.Store(true)
+	return nil
+}
+
+// elasticDoc is the document of a verse.
+type elasticDoc map[string]any
+
+func elasticDocs(chapter Chapter) []elasticDoc {
+	place, _ := ParseRevelationPlace(chapter.RevelationPlace)
+	docs := make([]elasticDoc, 0, len(chapter.Verses))
+	for _, v := range chapter.Verses {
+		doc := elasticDoc{
+			"verse_key": v.VerseKey,
+			"chapter":   chapter.ID,
+			"verse":     v.VerseNumber,
+			"juz":       v.JuzNumber,
+			"page":      v.PageNumber,
+			"text":      v.TextMadani,
+		}
+		if place != "" {
+			doc["revelation_place"] = place
+		}
+		for _, tr := range v.Translations {
+			doc["translation_"+strconv.Itoa(tr.ResourceID)] = stripTags(tr.Text)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// indexChapter replaces the documents of the verses of chapter with one
+// bulk request.
+func (e *elasticIndex) indexChapter(ctx context.Context, chapter Chapter) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	for _, doc := range elasticDocs(chapter) {
+		action := map[string]any{"index": map[string]any{"_id": doc["verse_key"]}}
+		if err := enc.Encode(action); err != nil {
+			return err
+		}
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string          `json:"_id"`
+			Error json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := e.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", buf.Bytes(), &resp); err != nil {
+		return err
+	}
+	if resp.Errors {
+		for _, item := range resp.Items {
+			for _, r := range item {
+				if len(r.Error) > 0 {
+					return fmt.Errorf("elasticsearch: index %s: %s", r.ID, r.Error)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Index creates the index on first use and pushes the verses of chapter.
+func (e *elasticIndex) Index(ctx context.Context, chapter Chapter) error {
+	if err := e.ensure(ctx); err != nil {
+		return err
+	}
+	return e.indexChapter(ctx, chapter)
+}
+
+func (e *elasticIndex) Delete(ctx context.Context, id int) error {
+	body := map[string]any{"query": map[string]any{"term": map[string]any{"chapter": id}}}
+	err := e.doJSON(ctx, http.MethodPost, "/_delete_by_query", body, nil)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil
+	}
+	return err
+}
+
+// elasticQuery returns the body of a search request for query, in mushaf
+// order after the verse after, for size hits.
+func elasticQuery(query SearchQuery, after VerseKey, size int) map[string]any {
+	boolQuery := map[string]any{
+		"must": map[string]any{
+			"multi_match": map[string]any{
+				"query":    query.Text,
+				"type":     "most_fields",
+				"operator": "and",
+				"fields":   []string{"text", "text.stemmed", "translation_*"},
+			},
+		},
+	}
+	if query.Chapters != nil {
+		boolQuery["filter"] = []any{
+			map[string]any{"terms": map[string]any{"chapter": query.Chapters}},
+		}
+	}
+	body := map[string]any{
+		"size":    size,
+		"_source": []string{"verse_key", "chapter", "verse", "text", "translation_*"},
+		"query":   map[string]any{"bool": boolQuery},
+		"sort": []any{
+			map[string]any{"chapter": "asc"},
+			map[string]any{"verse": "asc"},
+		},
+		"highlight": map[string]any{
+			"pre_tags":            []string{query.HighlightPre},
+			"post_tags":           []string{query.HighlightPost},
+			"number_of_fragments": 1,
+			"fragment_size":       150,
+			"require_field_match": false,
+			"fields": map[string]any{
+				"text":          map[string]any{},
+				"translation_*": map[string]any{},
+			},
+		},
+	}
+	if chapter, verse, err := after.Split(); err == nil {
+		body["search_after"] = []int{chapter, verse}
+	}
+	return body
+}
+
+type elasticHit struct {
+	Source    map[string]any      `json:"_source"`
+	Highlight map[string][]string `json:"highlight"`
+	Sort      []int               `json:"sort"`
+}
+
+// searchHit converts a hit of elasticQuery, with a match per highlighted
+// text. Elasticsearch reports no offsets, so the matches have none.
+func (h elasticHit) searchHit() (SearchHit, error) {
+	if len(h.Sort) != 2 {
+		return SearchHit{}, fmt.Errorf("elasticsearch: hit sorted by %v", h.Sort)
+	}
+	hit := SearchHit{VerseKey: NewVerseKey(h.Sort[0], h.Sort[1]), Chapter: h.Sort[0], Verse: h.Sort[1]}
+	text := func(field string) string {
+		s, _ := h.Source[field].(string)
+		return s
+	}
+	if fragments := h.Highlight["text"]; len(fragments) > 0 {
+		hit.Matches = append(hit.Matches, SearchMatch{Text: text("text"), Snippet: fragments[0]})
+	}
+	var ids []int
+	for field := range h.Highlight {
+		if id, ok := strings.CutPrefix(field, "translation_"); ok {
+			if n, err := strconv.Atoi(id); err == nil {
+				ids = append(ids, n)
+			}
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		field := "translation_" + strconv.Itoa(id)
+		if fragments := h.Highlight[field]; len(fragments) > 0 {
+			hit.Matches = append(hit.Matches, SearchMatch{Translation: id, Text: text(field), Snippet: fragments[0]})
+		}
+	}
+	return hit, nil
+}
+
+// Query asks the index for the verses whose Arabic or one of whose
+// translations holds every word of the query, paging through every hit
+// when the query has no limit.
+func (e *elasticIndex) Query(ctx context.Context, query SearchQuery) ([]SearchHit, bool, error) {
+	var hits []SearchHit
+	after := query.After
+	for {
+		size := elasticBatch
+		if query.Limit > 0 {
+			size = min(query.Limit-len(hits)+1, elasticBatch)
+		}
+		var resp struct {
+			Hits struct {
+				Hits []elasticHit `json:"hits"`
+			} `json:"hits"`
+		}
+		if err := e.doJSON(ctx, http.MethodPost, "/_search", elasticQuery(query, after, size), &resp); err != nil {
+			return nil, false, err
+		}
+		for _, h := range resp.Hits.Hits {
+			if query.Limit > 0 && len(hits) == query.Limit {
+				return hits, true, nil
+			}
+			hit, err := h.searchHit()
+			if err != nil {
+				return nil, false, err
+			}
+			hits = append(hits, hit)
+			after = hit.VerseKey
+		}
+		if len(resp.Hits.Hits) < size {
+			return hits, false, nil
+		}
+	}
+}
+
+// RebuildElasticsearchIndex recreates the index configured with
+// WithElasticsearch and pushes every stored chapter into it.
+func (q *QuranService) RebuildElasticsearchIndex(ctx context.Context) error {
+	if q.elastic == nil {
+		return errors.New("elasticsearch is not configured")
+	}
+	if err := q.elastic.recreate(ctx); err != nil {
+		return err
+	}
+	if err := q.reindex(ctx, q.elastic); err != nil {
+		return err
+	}
+	return q.elastic.do(ctx, http.MethodPost, "/_refresh", "", nil, nil)
+}
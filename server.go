@@ -0,0 +1,778 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewServer returns the HTTP API for q.
+func NewServer(q *QuranService) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pages/{n}", withCaching(q.handlePage))
+	mux.HandleFunc("GET /pages/{n}/glyphs", withCaching(q.handlePageGlyphs))
+	mux.HandleFunc("GET /pages/{n}/layout", withCaching(q.handlePageLayout))
+	mux.HandleFunc("GET /verses/{key}/media", q.handleVerseMedia)
+	mux.HandleFunc("GET /verses/{key}/words", withCaching(q.handleVerseWords))
+	mux.HandleFunc("GET /verses/{key}/translations/{id}/audio", q.handleTranslationAudio)
+	mux.HandleFunc("GET /verses/{key}/asbab", withCaching(q.handleAsbabNuzul))
+	mux.HandleFunc("GET /verses/{key}/compare", q.handleCompareTranslations)
+	mux.HandleFunc("GET /verses/{key}/diff", withCaching(q.handleVerseDiff))
+	mux.HandleFunc("GET /chapters/{chapter}/diff", withCaching(q.handleChapterDiff))
+	mux.HandleFunc("GET /verse-of-the-day", q.handleVerseOfTheDay)
+	mux.HandleFunc("GET /verse-of-the-day.ics", q.handleVerseOfTheDayICal)
+	mux.HandleFunc("GET /suggested-reading", q.handleSuggestedReading)
+	mux.HandleFunc("GET /reading-occasions", q.handleReadingOccasions)
+	mux.HandleFunc("GET /annotations", q.handleExportAnnotations)
+	mux.HandleFunc("POST /annotations", q.handleImportAnnotations)
+	mux.HandleFunc("GET /annotations/schema", handleAnnotationSchema)
+	mux.HandleFunc("GET /verses/{key}/notes", q.handleVerseNotes)
+	mux.HandleFunc("POST /verses/{key}/notes", q.handleAddNote)
+	mux.HandleFunc("GET /notes", q.handleNotes)
+	mux.HandleFunc("DELETE /notes/{id}", q.handleDeleteNote)
+	mux.HandleFunc("GET /userdata", q.handleExportUserData)
+	mux.HandleFunc("POST /userdata", q.handleImportUserData)
+	mux.HandleFunc("GET /collections", q.handleCollections)
+	mux.HandleFunc("GET /collections/{name}", q.handleCollection)
+	mux.HandleFunc("GET /stats", q.handleStats)
+	mux.HandleFunc("GET /verses/{key}/stats", withCaching(q.handleVerseStats))
+	mux.HandleFunc("GET /verses/{key}/pauses", withCaching(q.handleVersePauseMarks))
+	mux.HandleFunc("GET /search", q.handleSearch)
+	mux.HandleFunc("GET /concordance", q.handleConcordance)
+	mux.HandleFunc("GET /frequencies", q.handleFrequencies)
+	mux.HandleFunc("GET /resolve", q.handleResolve)
+	mux.HandleFunc("GET /features", q.handleFeatures)
+	mux.HandleFunc("GET /openapi.json", handleOpenAPI)
+	mux.HandleFunc("GET /docs", handleDocs)
+	mux.HandleFunc("GET /juz/{n}", withCaching(q.handleSlug))
+	mux.HandleFunc("GET /page/{n}", withCaching(q.handleSlug))
+	mux.HandleFunc("GET /hizb/{n}", withCaching(q.handleSlug))
+	mux.HandleFunc("GET /rub/{n}", withCaching(q.handleSlug))
+	mux.HandleFunc("GET /{chapter}", withCaching(q.handleSlug))
+	mux.HandleFunc("GET /{chapter}/{verses}", withCaching(q.handleSlug))
+	return withRequestID(mux)
+}
+
+// withRequestID tags each request with the X-Request-ID header, or a new
+// ID if the client sent none.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			id = NewRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), id)))
+	})
+}
+
+type pageResponse struct {
+	Page       int         `json:"page"`
+	Prev       int         `json:"prev,omitempty"`
+	Next       int         `json:"next,omitempty"`
+	Verses     verseList   `json:"verses"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+func (q *QuranService) handlePage(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		http.Error(w, "invalid page number", http.StatusBadRequest)
+		return
+	}
+	vq, err := parseVerseQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	verses, err := q.Page(r.Context(), n)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+
+	resp := pageResponse{Page: n}
+	resp.Verses, resp.Pagination = vq.apply(verses)
+	if r.URL.Query().Get("companions") == "true" {
+		resp.Verses.verses = q.attachCompanions(r.Context(), resp.Verses.verses)
+	}
+	resp.Prev, _ = PrevPage(n)
+	resp.Next, _ = NextPage(n)
+	writeJSON(w, resp)
+}
+
+// relativePath returns target, a path on the server, relative to the
+// request path from, so that links still resolve when the server is
+// mounted under a prefix with http.StripPrefix.
+func relativePath(from, target string) string {
+	depth := strings.Count(from, "/") - 1
+	if depth <= 0 {
+		// The leading "." keeps a slug like 2:255 from reading as a scheme.
+		return "." + target
+	}
+	return strings.Repeat("../", depth) + strings.TrimPrefix(target, "/")
+}
+
+type scopeResponse struct {
+	Slug       string      `json:"slug"`
+	Scope      string      `json:"scope"`
+	Verses     verseList   `json:"verses"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// handleSlug serves the passage named by a permalink slug, redirecting
+// other spellings of it to the canonical one.
+func (q *QuranService) handleSlug(w http.ResponseWriter, r *http.Request) {
+	scope, err := ParseSlug(r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if slug := scope.Slug(); r.URL.Path != slug {
+		// http.Redirect would resolve a relative location against the
+		// stripped path, so it is set directly.
+		location := relativePath(r.URL.Path, slug)
+		if r.URL.RawQuery != "" {
+			location += "?" + r.URL.RawQuery
+		}
+		w.Header().Set("Location", location)
+		w.WriteHeader(http.StatusMovedPermanently)
+		return
+	}
+	vq, err := parseVerseQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	render, err := parseRenderQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	verses, err := q.ScopeVerses(r.Context(), scope)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Link", "<"+relativePath(r.URL.Path, scope.Slug())+`>; rel="canonical"`)
+	resp := scopeResponse{Slug: scope.Slug(), Scope: scope.String()}
+	resp.Verses, resp.Pagination = vq.apply(verses)
+	if r.URL.Query().Get("companions") == "true" {
+		resp.Verses.verses = q.attachCompanions(r.Context(), resp.Verses.verses)
+	}
+	if render != nil {
+		if render.Accessible {
+			if render.ChapterNames, err = q.chapterNames(r.Context()); err != nil {
+				q.writeError(w, r, err)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", render.Format.ContentType())
+		RenderVerses(w, resp.Verses.verses, *render)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+// parseRenderQuery reads the format, translit, translations and a11y
+// parameters of a scope rendered for reading rather than as JSON. It
+// returns nil without a format or a11y.
+func parseRenderQuery(v url.Values) (*RenderOptions, error) {
+	a11y := v.Get("a11y") == "1" || v.Get("a11y") == "true"
+	if !a11y && (v.Get("format") == "" || v.Get("format") == "json") {
+		return nil, nil
+	}
+	// accessible output is plain text whatever the format
+	format, err := ParseRenderFormat(v.Get("format"))
+	if a11y {
+		format, err = RenderText, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	opts := &RenderOptions{
+		Format:          format,
+		Transliteration: v.Get("translit") == "true",
+		Accessible:      a11y,
+		ArabicFirst:     v.Get("arabic_first") == "true",
+	}
+	if s := v.Get("translations"); s != "" {
+		if opts.Translations, err = parseInts(s); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+type resolvedScope struct {
+	Scope string `json:"scope,omitempty"`
+	Slug  string `json:"slug,omitempty"`
+}
+
+type resolveResponse struct {
+	resolvedScope
+	Candidates []resolvedScope `json:"candidates,omitempty"`
+}
+
+// handleResolve reads a scope typed by a user, such as "36" or "2:255",
+// under the service's bare number policy. An ambiguous input is answered
+// with 300 Multiple Choices listing the scopes it could mean.
+func (q *QuranService) handleResolve(w http.ResponseWriter, r *http.Request) {
+	scope, err := q.ResolveScope(r.URL.Query().Get("q"))
+	var ambiguous *AmbiguousScopeError
+	if errors.As(err, &ambiguous) {
+		var resp resolveResponse
+		for _, c := range ambiguous.Candidates {
+			resp.Candidates = append(resp.Candidates, resolvedScope{Scope: c.String(), Slug: c.Slug()})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMultipleChoices)
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, resolveResponse{resolvedScope: resolvedScope{Scope: scope.String(), Slug: scope.Slug()}})
+}
+
+func (q *QuranService) handlePageGlyphs(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		http.Error(w, "invalid page number", http.StatusBadRequest)
+		return
+	}
+	set := GlyphSet(r.URL.Query().Get("set"))
+	if set == "" {
+		set = GlyphSetV1
+	}
+	if err := set.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	glyphs, err := q.GetPageGlyphs(r.Context(), n, set)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, glyphs)
+}
+
+func (q *QuranService) handlePageLayout(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.PathValue("n"))
+	if err != nil {
+		http.Error(w, "invalid page number", http.StatusBadRequest)
+		return
+	}
+	layout, err := q.GetPageLayout(r.Context(), n)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, layout)
+}
+
+func (q *QuranService) handleVerseMedia(w http.ResponseWriter, r *http.Request) {
+	media, err := q.GetVerseMedia(r.Context(), r.PathValue("key"))
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, media)
+}
+
+func (q *QuranService) handleVerseWords(w http.ResponseWriter, r *http.Request) {
+	language := r.URL.Query().Get("language")
+	if language == "" {
+		language = "en"
+	}
+	if !languageCodeRE.MatchString(language) {
+		http.Error(w, "language must be an ISO 639 code such as en", http.StatusBadRequest)
+		return
+	}
+	alignment, err := q.WordAlignment(r.Context(), r.PathValue("key"), language)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, alignment)
+}
+
+func (q *QuranService) handleCollections(w http.ResponseWriter, r *http.Request) {
+	collections, err := q.ListCollections(r.Context())
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, collections)
+}
+
+type collectionResponse struct {
+	Collection
+	Verses     verseList   `json:"verses"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+func (q *QuranService) handleCollection(w http.ResponseWriter, r *http.Request) {
+	vq, err := parseVerseQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c, err := q.GetCollection(r.Context(), r.PathValue("name"))
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	verses, err := q.CollectionVerses(r.Context(), c.Name)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	resp := collectionResponse{Collection: c}
+	resp.Verses, resp.Pagination = vq.apply(verses)
+	writeJSON(w, resp)
+}
+
+func (q *QuranService) handleAsbabNuzul(w http.ResponseWriter, r *http.Request) {
+	occasions, err := q.GetAsbabNuzul(r.Context(), r.PathValue("key"))
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, occasions)
+}
+
+func (q *QuranService) handleTranslationAudio(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid translation id", http.StatusBadRequest)
+		return
+	}
+	path, err := q.TranslationAudio(r.Context(), r.PathValue("key"), id)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	http.ServeFile(w, r, path)
+}
+
+func (q *QuranService) handleSearch(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	opts := SearchOptions{Limit: 20, Cursor: params.Get("cursor")}
+	if l := params.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > 100 {
+			http.Error(w, "limit must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		opts.Limit = n
+	}
+	if p := params.Get("place"); p != "" {
+		place, err := ParseRevelationPlace(p)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts.RevelationPlace = place
+	}
+	results, err := q.Search(r.Context(), params.Get("q"), opts)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, results)
+}
+
+func (q *QuranService) handleConcordance(w http.ResponseWriter, r *http.Request) {
+	lines, err := q.Concordance(r.Context(), r.URL.Query().Get("q"))
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, lines)
+}
+
+// handleFrequencies counts the Arabic, or the translation given as
+// ?translation, of every chapter or those given as ?chapter=1,2.
+func (q *QuranService) handleFrequencies(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	opts := FrequencyOptions{Limit: 100}
+	var err error
+	for name, v := range map[string]*int{"translation": &opts.Translation, "limit": &opts.Limit, "min": &opts.MinCount} {
+		if s := params.Get(name); s != "" {
+			if *v, err = strconv.Atoi(s); err != nil || *v < 0 {
+				http.Error(w, "invalid "+name, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if c := params.Get("chapter"); c != "" {
+		if opts.Chapters, err = parseInts(c); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	freqs, err := q.TokenFrequencies(r.Context(), opts)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, freqs)
+}
+
+func (q *QuranService) handleFeatures(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, q.FeatureMatrix())
+}
+
+func (q *QuranService) handleStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := q.Stats(r.Context())
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+func (q *QuranService) handleVerseStats(w http.ResponseWriter, r *http.Request) {
+	c, err := q.VerseCounts(r.Context(), r.PathValue("key"))
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, c)
+}
+
+func (q *QuranService) handleVersePauseMarks(w http.ResponseWriter, r *http.Request) {
+	marks, err := q.VersePauseMarks(r.Context(), r.PathValue("key"))
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, marks)
+}
+
+func (q *QuranService) handleVerseOfTheDay(w http.ResponseWriter, r *http.Request) {
+	date := time.Now()
+	if d := r.URL.Query().Get("date"); d != "" {
+		var err error
+		if date, err = time.Parse(time.DateOnly, d); err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+	}
+	p, err := q.votdPayload(r.Context(), date)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, p)
+}
+
+func (q *QuranService) handleSuggestedReading(w http.ResponseWriter, r *http.Request) {
+	date := time.Now()
+	if d := r.URL.Query().Get("date"); d != "" {
+		var err error
+		if date, err = time.Parse(time.DateOnly, d); err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+	}
+	s, err := q.SuggestedReading(r.Context(), date)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, s)
+}
+
+func (q *QuranService) handleReadingOccasions(w http.ResponseWriter, r *http.Request) {
+	occasions, err := q.ReadingOccasions(r.Context())
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, occasions)
+}
+
+func (q *QuranService) handleVerseOfTheDayICal(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := q.WriteVerseOfTheDayICal(r.Context(), &buf, time.Now(), 30); err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+func (q *QuranService) handleExportAnnotations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	params := r.URL.Query()
+	if err := q.ExportAnnotations(r.Context(), w, params.Get("title"), params.Get("author")); err != nil {
+		q.writeError(w, r, err)
+	}
+}
+
+const maxAnnotationPack = 10 << 20
+
+func (q *QuranService) handleImportAnnotations(w http.ResponseWriter, r *http.Request) {
+	report, err := q.ImportAnnotations(r.Context(), http.MaxBytesReader(w, r.Body, maxAnnotationPack))
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, report)
+}
+
+// handleCompareTranslations compares the translations given as ?t=20,131,
+// or those stored with the verse.
+func (q *QuranService) handleCompareTranslations(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseInts(r.URL.Query().Get("t"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c, err := q.CompareTranslations(r.Context(), r.PathValue("key"), ids...)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, c)
+}
+
+// diffEditions returns the editions given as ?a and ?b, which default to
+// the Uthmani and Indopak texts.
+func diffEditions(r *http.Request) (a, b Script) {
+	a, b = Script(r.URL.Query().Get("a")), Script(r.URL.Query().Get("b"))
+	if a == "" {
+		a = ScriptUthmani
+	}
+	if b == "" {
+		b = ScriptIndopak
+	}
+	return a, b
+}
+
+func (q *QuranService) handleVerseDiff(w http.ResponseWriter, r *http.Request) {
+	a, b := diffEditions(r)
+	d, err := q.DiffEditions(r.Context(), r.PathValue("key"), a, b)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, d)
+}
+
+func (q *QuranService) handleChapterDiff(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(r.PathValue("chapter"))
+	if err != nil {
+		http.Error(w, "invalid chapter", http.StatusBadRequest)
+		return
+	}
+	a, b := diffEditions(r)
+	diffs, err := q.DiffChapterEditions(r.Context(), n, a, b)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, diffs)
+}
+
+// handleNotes lists the notes matching ?verse, ?tag (repeated for notes
+// with every tag) and ?q, as JSON or, with ?format=markdown, as Markdown.
+func (q *QuranService) handleNotes(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	filter := NoteFilter{VerseKey: params.Get("verse"), Tags: params["tag"], Text: params.Get("q")}
+	if params.Get("format") == NotesMarkdown {
+		var buf bytes.Buffer
+		if err := q.ExportNotes(r.Context(), &buf, filter, NotesMarkdown); err != nil {
+			q.writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write(buf.Bytes())
+		return
+	}
+	notes, err := q.ListNotes(r.Context(), filter)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, notes)
+}
+
+func (q *QuranService) handleVerseNotes(w http.ResponseWriter, r *http.Request) {
+	notes, err := q.ListNotes(r.Context(), NoteFilter{VerseKey: r.PathValue("key"), Tags: r.URL.Query()["tag"]})
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, notes)
+}
+
+const maxNote = 1 << 20
+
+func (q *QuranService) handleAddNote(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Text string   `json:"text"`
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxNote)).Decode(&req); err != nil {
+		http.Error(w, "invalid note: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Text == "" {
+		http.Error(w, "note has no text", http.StatusBadRequest)
+		return
+	}
+	n, err := q.AddNote(r.Context(), r.PathValue("key"), req.Text, req.Tags...)
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/notes/"+n.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(n)
+}
+
+func (q *QuranService) handleDeleteNote(w http.ResponseWriter, r *http.Request) {
+	if err := q.DeleteNote(r.Context(), r.PathValue("id")); err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (q *QuranService) handleExportUserData(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := q.ExportUserData(r.Context(), &buf); err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="quranapi-userdata.json"`)
+	w.Write(buf.Bytes())
+}
+
+func (q *QuranService) handleImportUserData(w http.ResponseWriter, r *http.Request) {
+	report, err := q.ImportUserData(r.Context(), http.MaxBytesReader(w, r.Body, maxAnnotationPack))
+	if err != nil {
+		q.writeError(w, r, err)
+		return
+	}
+	writeJSON(w, report)
+}
+
+func handleAnnotationSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(AnnotationPackSchema)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError maps err onto a status code using the sentinel errors.
+func (q *QuranService) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrChapterNotFound), errors.Is(err, ErrVerseNotFound), errors.Is(err, ErrPageNotFound),
+		errors.Is(err, ErrTranslationNotFound), errors.Is(err, ErrCollectionNotFound),
+		errors.Is(err, ErrNoteNotFound), errors.Is(err, ErrReadingOccasionNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrInvalidVerseKey), errors.Is(err, ErrInvalidAnnotationPack),
+		errors.Is(err, ErrInvalidUserDataArchive), errors.Is(err, ErrUnknownScript),
+		errors.Is(err, ErrInvalidCursor), errors.Is(err, ErrInvalidBackup):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrFeatureDisabled), errors.Is(err, ErrBackupUnsupported),
+		errors.Is(err, ErrWordsNotStored), errors.Is(err, ErrNoTTS), errors.Is(err, ErrNoAsbabDataset),
+		errors.Is(err, ErrScriptUnavailable):
+		status = http.StatusNotImplemented
+	case errors.Is(err, ErrReadOnly), errors.Is(err, ErrCuratedCollection), errors.Is(err, ErrCuratedReadingOccasion):
+		status = http.StatusForbidden
+	case errors.Is(err, ErrUpstreamUnavailable):
+		status = http.StatusBadGateway
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	}
+	if status >= http.StatusInternalServerError {
+		q.log(r.Context()).Error("request failed", "path", r.URL.Path, "err", err)
+	}
+	http.Error(w, err.Error(), status)
+}
+
+func runServe(ctx context.Context, q *QuranService, cfg ServerConfig, backup BackupConfig, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	port := fs.Int("port", cfg.Port, "port to listen on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	handler := NewServer(q)
+	if cfg.Sync {
+		handler = withSync(q, handler)
+	}
+	var auth *Authenticator
+	if cfg.Auth.Enabled() {
+		auth = NewAuthenticator(q, cfg.Auth)
+		handler = auth.Wrap(handler)
+		go auth.RunFlush(ctx, time.Minute)
+	}
+	handler = withAdmin(q, cfg.AdminKey, handler)
+	handler = withCORS(cfg.CORS, handler)
+
+	srv := &http.Server{
+		Addr:    net.JoinHostPort("", strconv.Itoa(*port)),
+		Handler: handler,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go q.RunCacheWriteRetries(ctx, time.Minute)
+	go q.RunAutoRefresh(ctx)
+	go q.RunTrashPurge(ctx, time.Hour)
+	if backup.Dir != "" && backup.Interval > 0 {
+		go q.RunUserDataBackups(ctx, backup)
+	}
+	if _, ok := q.store.(*ReloadableStore); ok {
+		go q.reloadOnHangup(ctx)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		q.log(ctx).Info("listening", "addr", srv.Addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	if auth != nil {
+		if ferr := auth.Flush(shutdownCtx); ferr != nil {
+			q.log(ctx).Warn("flush api usage", "err", ferr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,326 @@
+package quranapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const bucketAPIUsage = "api_usage"
+
+// AuthConfig protects the server with API keys, JSON web tokens, or both.
+// The server is open when neither is configured.
+type AuthConfig struct {
+	// APIKeys are accepted as "Authorization: Bearer <key>" or X-API-Key.
+	APIKeys []string `yaml:"api_keys" toml:"api_keys"`
+	// JWTSecret accepts HS256 tokens signed with it, counted by subject.
+	JWTSecret string `yaml:"jwt_secret" toml:"jwt_secret"`
+	// RateLimit is the requests per minute allowed each key or subject; 0
+	// is unlimited.
+	RateLimit int `yaml:"rate_limit" toml:"rate_limit"`
+	// PerUserData keeps separate bookmarks, notes, plans and other user
+	// data for each key or subject, so that one server can serve a family
+	// or a small community. Otherwise every client shares the same data.
+	PerUserData bool `yaml:"per_user_data" toml:"per_user_data"`
+}
+
+func (c AuthConfig) Enabled() bool {
+	return len(c.APIKeys) > 0 || c.JWTSecret != ""
+}
+
+var errUnauthorized = errors.New("missing or invalid credentials")
+
+// publicPaths are served without credentials so that clients can learn
+// how to authenticate.
+var publicPaths = map[string]bool{"/openapi.json": true, "/docs": true}
+
+// KeyUsage counts the requests made with an API key, identified by a
+// prefix of its SHA-256 so that keys themselves are never stored, or with
+// the tokens of a JWT subject.
+type KeyUsage struct {
+	Key      string    `json:"key"`
+	Requests int64     `json:"requests"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+type apiUsage struct {
+	Requests int64
+	LastUsed time.Time
+}
+
+// Authenticator checks the credentials of each request, rate limits them
+// per key and counts their usage. Counts are kept in memory and added to
+// the store by Flush.
+type Authenticator struct {
+	q      *QuranService
+	cfg    AuthConfig
+	keys   map[string]string // hex SHA-256 of the key to its identity
+	secret []byte
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	pending map[string]apiUsage
+}
+
+func NewAuthenticator(q *QuranService, cfg AuthConfig) *Authenticator {
+	a := &Authenticator{
+		q:       q,
+		cfg:     cfg,
+		keys:    make(map[string]string),
+		secret:  []byte(cfg.JWTSecret),
+		buckets: make(map[string]*tokenBucket),
+		pending: make(map[string]apiUsage),
+	}
+	for _, key := range cfg.APIKeys {
+		sum := sha256.Sum256([]byte(key))
+		a.keys[hex.EncodeToString(sum[:])] = "key:" + hex.EncodeToString(sum[:6])
+	}
+	return a
+}
+
+// Wrap rejects requests to next without valid credentials with 401, and
+// those over their key's rate limit with 429.
+func (a *Authenticator) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, err := a.identify(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="quranapi"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if wait, ok := a.allow(id, time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		if a.cfg.PerUserData {
+			r = r.WithContext(ContextWithUser(r.Context(), id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *Authenticator) identify(r *http.Request) (string, error) {
+	token := r.Header.Get("X-API-Key")
+	if token == "" {
+		auth := r.Header.Get("Authorization")
+		if scheme, rest, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(rest)
+		}
+	}
+	if token == "" {
+		return "", errUnauthorized
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	if id, ok := a.keys[hex.EncodeToString(sum[:])]; ok {
+		return id, nil
+	}
+	if len(a.secret) > 0 && strings.Count(token, ".") == 2 {
+		sub, err := verifyJWT(token, a.secret, time.Now())
+		if err != nil {
+			return "", err
+		}
+		return "jwt:" + sub, nil
+	}
+	return "", errUnauthorized
+}
+
+// allow counts a request by id, or reports how long it must wait when it
+// is over the rate limit.
+func (a *Authenticator) allow(id string, now time.Time) (time.Duration, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cfg.RateLimit > 0 {
+		b, ok := a.buckets[id]
+		if !ok {
+			b = &tokenBucket{tokens: float64(a.cfg.RateLimit), updated: now}
+			a.buckets[id] = b
+		}
+		if wait := b.take(a.cfg.RateLimit, now); wait > 0 {
+			return wait, false
+		}
+	}
+
+	u := a.pending[id]
+	u.Requests++
+	u.LastUsed = now
+	a.pending[id] = u
+	return 0, true
+}
+
+// tokenBucket allows bursts of up to a minute's worth of requests, refilled
+// at the per-minute rate.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func (b *tokenBucket) take(perMinute int, now time.Time) time.Duration {
+	rate := float64(perMinute) / float64(time.Minute)
+	b.tokens = math.Min(float64(perMinute), b.tokens+float64(now.Sub(b.updated))*rate)
+	b.updated = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / rate)
+	}
+	b.tokens--
+	return 0
+}
+
+// Flush adds the usage counted since the last flush to the store. Usage
+// that fails to be stored is kept for the next flush.
+func (a *Authenticator) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = make(map[string]apiUsage)
+	a.mu.Unlock()
+
+	var errs []error
+	for id, u := range pending {
+		if err := a.flushUsage(ctx, id, u); err != nil {
+			a.requeue(id, u)
+			errs = append(errs, fmt.Errorf("api usage %s: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (a *Authenticator) flushUsage(ctx context.Context, id string, u apiUsage) error {
+	var stored apiUsage
+	err := a.q.getValue(ctx, bucketAPIUsage, id, &stored)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+	stored.Requests += u.Requests
+	if u.LastUsed.After(stored.LastUsed) {
+		stored.LastUsed = u.LastUsed
+	}
+	return a.q.putValue(ctx, bucketAPIUsage, id, stored)
+}
+
+func (a *Authenticator) requeue(id string, u apiUsage) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p := a.pending[id]
+	p.Requests += u.Requests
+	if u.LastUsed.After(p.LastUsed) {
+		p.LastUsed = u.LastUsed
+	}
+	a.pending[id] = p
+}
+
+// RunFlush flushes usage every interval until ctx is done.
+func (a *Authenticator) RunFlush(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := a.Flush(ctx); err != nil {
+				a.q.log(ctx).Warn("flush api usage", "err", err)
+			}
+		}
+	}
+}
+
+// APIUsage lists the stored usage of every key and JWT subject, busiest
+// first.
+func (q *QuranService) APIUsage(ctx context.Context) ([]KeyUsage, error) {
+	var out []KeyUsage
+	err := q.store.Iterate(ctx, bucketAPIUsage, func(key string, value []byte) error {
+		var u apiUsage
+		if err := valueDecode(value, &u); err != nil {
+			return fmt.Errorf("api usage %s: %w", key, err)
+		}
+		out = append(out, KeyUsage{Key: key, Requests: u.Requests, LastUsed: u.LastUsed})
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Requests != out[j].Requests {
+			return out[i].Requests > out[j].Requests
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out, nil
+}
+
+// verifyJWT checks an HS256 JSON web token and returns its subject.
+func verifyJWT(token string, secret []byte, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errUnauthorized
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return "", errUnauthorized
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", errUnauthorized
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return "", errUnauthorized
+	}
+
+	var claims struct {
+		Sub string `json:"sub"`
+		Exp int64  `json:"exp"`
+		Nbf int64  `json:"nbf"`
+	}
+	if err := decodeJWTPart(parts[1], &claims); err != nil || claims.Sub == "" {
+		return "", errUnauthorized
+	}
+	if claims.Exp != 0 && now.Unix() >= claims.Exp {
+		return "", errors.New("token expired")
+	}
+	if claims.Nbf != 0 && now.Unix() < claims.Nbf {
+		return "", errors.New("token not yet valid")
+	}
+	return claims.Sub, nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func runAPIUsage(ctx context.Context, q *QuranService, args []string) error {
+	usage, err := q.APIUsage(ctx)
+	if err != nil {
+		return err
+	}
+	for _, u := range usage {
+		fmt.Printf("%s\t%d\t%s\n", u.Key, u.Requests, u.LastUsed.Format(time.RFC3339))
+	}
+	return nil
+}
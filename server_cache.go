@@ -0,0 +1,155 @@
+package quranapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// cacheMaxAge is how long clients may reuse a passage response without
+// revalidating it. Text and translations rarely change, and an ETag makes
+// revalidation after that cheap.
+const cacheMaxAge = 24 * time.Hour
+
+// minCompressSize is the smallest body worth compressing.
+const minCompressSize = 1024
+
+// cachedResponse buffers a handler's response so that it can be tagged and
+// compressed as a whole.
+type cachedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (c *cachedResponse) Header() http.Header {
+	return c.header
+}
+
+func (c *cachedResponse) WriteHeader(status int) {
+	if c.status == 0 {
+		c.status = status
+	}
+}
+
+func (c *cachedResponse) Write(b []byte) (int, error) {
+	c.WriteHeader(http.StatusOK)
+	return c.body.Write(b)
+}
+
+// withCaching serves successful responses of next with an ETag and
+// Cache-Control, answering a matching If-None-Match with 304 Not Modified,
+// and compresses them with brotli or gzip when the client accepts it.
+func withCaching(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &cachedResponse{header: make(http.Header)}
+		next(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		h := w.Header()
+		for k, v := range rec.header {
+			h[k] = v
+		}
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		// weak, as the tag covers the content in every encoding
+		sum := sha256.Sum256(rec.body.Bytes())
+		etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+		h.Set("ETag", etag)
+		h.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(cacheMaxAge.Seconds())))
+		h.Add("Vary", "Accept-Encoding")
+		if etagMatch(r.Header.Get("If-None-Match"), etag) {
+			h.Del("Content-Type")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		body := rec.body.Bytes()
+		if enc := acceptedEncoding(r.Header.Get("Accept-Encoding")); enc != "" && len(body) >= minCompressSize {
+			compressed, err := compress(enc, body)
+			if err == nil {
+				body = compressed
+				h.Set("Content-Encoding", enc)
+			}
+		}
+		h.Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			w.Write(body)
+		}
+	}
+}
+
+// etagMatch reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 requires for that header.
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedEncoding picks brotli over gzip from an Accept-Encoding header,
+// or returns "" to send the body as is.
+func acceptedEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(v, 64); err == nil && q == 0 {
+				accepted[name] = false
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+
+	for _, enc := range []string{"br", "gzip"} {
+		if ok, listed := accepted[enc]; ok || !listed && accepted["*"] {
+			return enc
+		}
+	}
+	return ""
+}
+
+func compress(enc string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var zw io.WriteCloser
+	switch enc {
+	case "br":
+		zw = brotli.NewWriterLevel(&buf, brotli.DefaultCompression)
+	default:
+		zw = gzip.NewWriter(&buf)
+	}
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
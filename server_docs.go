@@ -0,0 +1,94 @@
+package quranapi
+
+import (
+	_ "embed"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPISpec is the OpenAPI 3 document describing the server's endpoints,
+// served at /openapi.json.
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(OpenAPISpec)
+}
+
+// docsPage loads Swagger UI from a CDN, so that it needn't be vendored. The
+// spec URL is relative so that it resolves under a mount prefix.
+const docsPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>quranapi</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>SwaggerUIBundle({url: "openapi.json", dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+
+func handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(docsPage))
+}
+
+// CORSConfig lets browser apps on other origins call the server.
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed, or "*" for any. CORS
+	// headers are not sent when it is empty.
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins"`
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration `yaml:"max_age" toml:"max_age"`
+}
+
+const (
+	corsAllowHeaders  = "Authorization, Content-Type, If-None-Match, X-API-Key, X-Request-ID"
+	corsExposeHeaders = "ETag, Link, Retry-After, X-Request-ID"
+)
+
+// withCORS adds CORS headers for allowed origins and answers preflight
+// requests itself, ahead of authentication, which browsers don't send
+// credentials to.
+func withCORS(cfg CORSConfig, next http.Handler) http.Handler {
+	anyOrigin := false
+	allowed := make(map[string]bool)
+	for _, o := range cfg.AllowedOrigins {
+		if o == "*" {
+			anyOrigin = true
+		}
+		allowed[strings.TrimSuffix(o, "/")] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !anyOrigin && !allowed[origin] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h.Set("Access-Control-Allow-Methods", "GET, HEAD, POST, DELETE, OPTIONS")
+		h.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+		if cfg.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,146 @@
+package quranapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 300
+)
+
+// verseFields are the JSON names of the Verse fields a ?fields= parameter
+// selects from.
+var verseFields = func() map[string]bool {
+	fields := make(map[string]bool)
+	t := reflect.TypeOf(Verse{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}()
+
+// Pagination describes the slice of a passage in a paginated response.
+type Pagination struct {
+	Page         int `json:"page"`
+	PerPage      int `json:"per_page"`
+	TotalPages   int `json:"total_pages"`
+	TotalRecords int `json:"total_records"`
+}
+
+// verseQuery is how a client asked for the verses of a passage: ?page= and
+// ?per_page= to page through it, ?fields= to receive only some fields of
+// each verse.
+type verseQuery struct {
+	fields  map[string]bool
+	page    int
+	perPage int
+}
+
+func parseVerseQuery(v url.Values) (verseQuery, error) {
+	var q verseQuery
+	if s := v.Get("fields"); s != "" {
+		q.fields = map[string]bool{"verse_key": true}
+		for _, name := range strings.Split(s, ",") {
+			name = strings.TrimSpace(name)
+			if !verseFields[name] {
+				return verseQuery{}, fmt.Errorf("unknown field %q", name)
+			}
+			q.fields[name] = true
+		}
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"page", &q.page}, {"per_page", &q.perPage}} {
+		s := v.Get(p.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return verseQuery{}, fmt.Errorf("invalid %s %q", p.name, s)
+		}
+		*p.dst = n
+	}
+	if q.page > 0 && q.perPage == 0 {
+		q.perPage = defaultPerPage
+	}
+	if q.perPage > maxPerPage {
+		return verseQuery{}, fmt.Errorf("per_page is at most %d", maxPerPage)
+	}
+	if q.perPage > 0 && q.page == 0 {
+		q.page = 1
+	}
+	return q, nil
+}
+
+// apply pages through verses, returning the Pagination only when the
+// client asked for a page.
+func (q verseQuery) apply(verses []Verse) (verseList, *Pagination) {
+	list := verseList{fields: q.fields}
+	if q.perPage == 0 {
+		list.verses = verses
+		return list, nil
+	}
+
+	p := &Pagination{
+		Page:         q.page,
+		PerPage:      q.perPage,
+		TotalPages:   (len(verses) + q.perPage - 1) / q.perPage,
+		TotalRecords: len(verses),
+	}
+	// pages past the end are empty; comparing before multiplying keeps a
+	// huge ?page= from overflowing
+	start := len(verses)
+	if q.page-1 < p.TotalPages {
+		start = (q.page - 1) * q.perPage
+	}
+	end := min(start+q.perPage, len(verses))
+	list.verses = verses[start:end]
+	return list, p
+}
+
+// verseList encodes verses with only the selected fields, or whole when
+// none were selected.
+type verseList struct {
+	verses []Verse
+	fields map[string]bool
+}
+
+func (l verseList) MarshalJSON() ([]byte, error) {
+	if l.verses == nil {
+		l.verses = []Verse{}
+	}
+	if l.fields == nil {
+		return json.Marshal(l.verses)
+	}
+
+	sparse := make([]map[string]json.RawMessage, len(l.verses))
+	for i, v := range l.verses {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(b, &all); err != nil {
+			return nil, err
+		}
+		sparse[i] = make(map[string]json.RawMessage, len(l.fields))
+		for name := range l.fields {
+			if value, ok := all[name]; ok {
+				sparse[i][name] = value
+			}
+		}
+	}
+	return json.Marshal(sparse)
+}
@@ -0,0 +1,163 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const bucketSimilar = "similar"
+
+// minSimilarity is the lowest score kept in the similarity index; lower
+// thresholds passed to SimilarVerses find nothing more.
+const minSimilarity = 0.5
+
+// SimilarVerse is a verse resembling another, scored by the Jaccard
+// overlap of their normalized words.
+type SimilarVerse struct {
+	VerseKey VerseKey `json:"verse_key"`
+	Score    float64  `json:"score"`
+}
+
+// verseWords returns the distinct normalized words of a verse.
+func verseWords(v Verse) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, w := range tokenize(v.TextMadani) {
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// similarPairs scores every pair of verses sharing words and returns, for
+// each verse index, the others scoring at least minSimilarity.
+func similarPairs(verses []Verse) map[int][]SimilarVerse {
+	words := make([][]string, len(verses))
+	postings := make(map[string][]int)
+	for i, v := range verses {
+		words[i] = verseWords(v)
+		for _, w := range words[i] {
+			postings[w] = append(postings[w], i)
+		}
+	}
+
+	out := make(map[int][]SimilarVerse)
+	shared := make([]int, len(verses))
+	for i := range verses {
+		var touched []int
+		for _, w := range words[i] {
+			for _, j := range postings[w] {
+				if j <= i {
+					continue
+				}
+				if shared[j] == 0 {
+					touched = append(touched, j)
+				}
+				shared[j]++
+			}
+		}
+		for _, j := range touched {
+			union := len(words[i]) + len(words[j]) - shared[j]
+			if score := float64(shared[j]) / float64(union); score >= minSimilarity {
+				out[i] = append(out[i], SimilarVerse{VerseKey: verses[j].VerseKey, Score: score})
+				out[j] = append(out[j], SimilarVerse{VerseKey: verses[i].VerseKey, Score: score})
+			}
+			shared[j] = 0
+		}
+	}
+	return out
+}
+
+// BuildSimilarityIndex scores every verse against all others and stores,
+// per chapter, the pairs scoring at least 0.5. All chapters are fetched if
+// not already stored.
+func (q *QuranService) BuildSimilarityIndex(ctx context.Context) error {
+	var verses []Verse
+	for id := 1; id <= ChapterCount; id++ {
+		chapter, err := q.GetChapter(ctx, id)
+		if err != nil {
+			return err
+		}
+		verses = append(verses, chapter.Verses...)
+	}
+
+	byChapter := make(map[int]map[int][]SimilarVerse, ChapterCount)
+	for i, similar := range similarPairs(verses) {
+		v := verses[i]
+		sort.Slice(similar, func(a, b int) bool {
+			return similar[a].Score > similar[b].Score
+		})
+		if byChapter[v.ChapterID] == nil {
+			byChapter[v.ChapterID] = make(map[int][]SimilarVerse)
+		}
+		byChapter[v.ChapterID][v.VerseNumber] = similar
+	}
+
+	for id := 1; id <= ChapterCount; id++ {
+		entry := byChapter[id]
+		if entry == nil {
+			entry = map[int][]SimilarVerse{}
+		}
+		if err := q.putValue(ctx, bucketSimilar, strconv.Itoa(id), entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SimilarVerses returns the verses whose wording overlaps the verse with
+// the given key by at least threshold, from 0.5 to 1, most similar first.
+// It returns ErrCacheMiss until BuildSimilarityIndex has run.
+func (q *QuranService) SimilarVerses(ctx context.Context, key string, threshold float64) ([]SimilarVerse, error) {
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry map[int][]SimilarVerse
+	err = q.getValue(ctx, bucketSimilar, strconv.Itoa(chapter), &entry)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, fmt.Errorf("similarity index not built: %w", ErrCacheMiss)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out []SimilarVerse
+	for _, s := range entry[verse] {
+		if s.Score >= threshold {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func runSimilar(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("similar", flag.ContinueOnError)
+	threshold := fs.Float64("threshold", 0.6, "minimum word overlap, from 0.5 to 1")
+	build := fs.Bool("build", false, "build the similarity index")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *build {
+		return q.BuildSimilarityIndex(ctx)
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: similar [-threshold n] <verse> | similar -build")
+	}
+
+	similar, err := q.SimilarVerses(ctx, fs.Arg(0), *threshold)
+	if err != nil {
+		return err
+	}
+	for _, s := range similar {
+		fmt.Printf("%s\t%.2f\n", s.VerseKey, s.Score)
+	}
+	return nil
+}
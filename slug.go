@@ -0,0 +1,108 @@
+package quranapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Slug returns the canonical URL path of the scope: "/2" for a whole
+// chapter, "/2/255" for a verse, "/2/255-257" for a range, "/juz/30",
+// "/hizb/60", "/rub/240" and "/page/604". Open ranges are closed at the chapter's last verse, and a
+// range spanning the whole chapter is the chapter slug.
+func (s Scope) Slug() string {
+	if s.Kind != ScopeChapter {
+		return "/" + s.Kind.String() + "/" + strconv.Itoa(s.Number)
+	}
+
+	last := 0
+	if ValidateChapter(s.Number) == nil {
+		last = chapterVerseCounts[s.Number-1]
+	}
+	from, to := max(s.FromVerse, 1), s.ToVerse
+	if to == 0 {
+		to = last
+	}
+	chapter := "/" + strconv.Itoa(s.Number)
+	switch {
+	case from == 1 && to == last:
+		return chapter
+	case from == to:
+		return chapter + "/" + strconv.Itoa(from)
+	default:
+		return chapter + "/" + strconv.Itoa(from) + "-" + strconv.Itoa(to)
+	}
+}
+
+// Permalink joins the scope's slug to the base URL of a server or static
+// site.
+func (s Scope) Permalink(base string) string {
+	return strings.TrimSuffix(base, "/") + s.Slug()
+}
+
+// slugKinds are the scope kinds whose slugs are named, as "/juz/30".
+var slugKinds = map[string]ScopeKind{
+	"juz":  ScopeJuz,
+	"page": ScopePage,
+	"hizb": ScopeHizb,
+	"rub":  ScopeRub,
+}
+
+// ParseSlug parses a URL path written by Scope.Slug, also accepting the
+// looser forms it redirects from: zero padded numbers, a colon between
+// chapter and verses ("/2:255") and a trailing slash. The scope is
+// validated.
+func ParseSlug(path string) (Scope, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 1 {
+		parts = strings.SplitN(parts[0], ":", 2)
+	}
+
+	num := func(s string) (int, error) {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid slug %q", path)
+		}
+		return n, nil
+	}
+
+	var s Scope
+	kind, named := slugKinds[parts[0]]
+	switch {
+	case len(parts) == 2 && named:
+		n, err := num(parts[1])
+		if err != nil {
+			return s, err
+		}
+		s = Scope{Kind: kind, Number: n}
+	case len(parts) == 1 || len(parts) == 2:
+		chapter, err := num(parts[0])
+		if err != nil {
+			return s, err
+		}
+		s = ChapterScope(chapter)
+		if len(parts) == 1 {
+			break
+		}
+		from, to, isRange := strings.Cut(parts[1], "-")
+		if s.FromVerse, err = num(from); err != nil {
+			return s, err
+		}
+		s.ToVerse = s.FromVerse
+		if isRange {
+			s.ToVerse = 0
+			if to != "" {
+				if s.ToVerse, err = num(to); err != nil {
+					return s, err
+				}
+			}
+		}
+	default:
+		return s, fmt.Errorf("invalid slug %q", path)
+	}
+
+	if err := s.Validate(); err != nil {
+		return s, err
+	}
+	return s, nil
+}
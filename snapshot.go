@@ -0,0 +1,97 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSnapshotUnsupported is returned by QuranService.Snapshot for stores
+// that cannot read several keys consistently, such as fs and redis.
+var ErrSnapshotUnsupported = errors.New("store does not support snapshots")
+
+// snapshotter is implemented by stores that can copy namespaces as of a
+// single point in time.
+type snapshotter interface {
+	Snapshot(ctx context.Context, namespaces ...string) (Store, error)
+}
+
+// snapshotNamespaces are what exports read: the text and the bookmarks
+// they may mark.
+var snapshotNamespaces = []string{bucketChapters, bucketChecksums, bucketScripts, bucketBookmarks}
+
+// Snapshot returns a service reading a private copy of the stored text,
+// taken in a single read transaction, so that a long export is not mixed
+// with chapters a concurrent sync rewrites halfway through. The copy is
+// held in memory and dropped with the returned service; chapters missing
+// from it are fetched from upstream but only cached in the copy.
+func (q *QuranService) Snapshot(ctx context.Context) (*QuranService, error) {
+	s, ok := q.store.(snapshotter)
+	if !ok {
+		return nil, ErrSnapshotUnsupported
+	}
+	_, span := q.startSpan(ctx, "store.Snapshot")
+	store, err := s.Snapshot(ctx, snapshotNamespaces...)
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return q.withPrivateStore(store), nil
+}
+
+// memoryCopy returns a service reading and writing a private in-memory
+// copy of the stored text, as Snapshot does, copied key by key from stores
+// that can't take a snapshot.
+func (q *QuranService) memoryCopy(ctx context.Context) (*QuranService, error) {
+	snap, err := q.Snapshot(ctx)
+	if !errors.Is(err, ErrSnapshotUnsupported) {
+		return snap, err
+	}
+	store, err := copyNamespaces(ctx, snapshotNamespaces, func(namespace string, fn func(key string, value []byte) error) error {
+		return q.store.Iterate(ctx, namespace, fn)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return q.withPrivateStore(store), nil
+}
+
+// withPrivateStore returns a copy of q backed by store, sharing none of
+// the state that follows q's store.
+func (q *QuranService) withPrivateStore(store Store) *QuranService {
+	snap := *q
+	snap.store = store
+	snap.chapterCache = nil // may already hold chapters newer than the copy
+	snap.verseCache = nil
+	snap.retryQueue = nil
+	snap.search = newSearchIndex()
+	// writes to the copy are not changes to the stored data
+	snap.events = newEventBus()
+	snap.webhooks = nil
+	return &snap
+}
+
+// exportSnapshot returns a snapshot for a long export to read, or q itself
+// with a warning when the store can't take one.
+func (q *QuranService) exportSnapshot(ctx context.Context) (*QuranService, error) {
+	snap, err := q.Snapshot(ctx)
+	if errors.Is(err, ErrSnapshotUnsupported) {
+		q.log(ctx).Warn("exporting without a snapshot; a concurrent sync may show up in the output", "err", err)
+		return q, nil
+	}
+	return snap, err
+}
+
+// copyNamespaces copies the namespaces read through iterate, which must see
+// a single transaction, into a new memory store.
+func copyNamespaces(ctx context.Context, namespaces []string, iterate func(namespace string, fn func(key string, value []byte) error) error) (Store, error) {
+	out := NewMemoryStore()
+	for _, ns := range namespaces {
+		err := iterate(ns, func(key string, value []byte) error {
+			return out.Put(ctx, ns, key, value)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,219 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// SoakOptions configures RunSoak.
+type SoakOptions struct {
+	Duration time.Duration
+	// Seed makes the operation sequence reproducible.
+	Seed uint64
+	// CheckInterval is how often store integrity and resource usage are
+	// checked. It defaults to one minute.
+	CheckInterval time.Duration
+	// RefreshInterval is the least time between refresh_chapter
+	// operations, which drop a chapter from the store and fetch it from
+	// upstream again. Zero leaves them out, so the run does not load
+	// upstream.
+	RefreshInterval time.Duration
+}
+
+// SoakReport counts the operations run by RunSoak and the problems found.
+type SoakReport struct {
+	Ops    map[string]int
+	Errors map[string]int
+	// Corrupt lists chapters whose stored text failed verification.
+	Corrupt []VerifyFailure
+	// Goroutines and HeapInuse are the baseline and last sampled values.
+	Goroutines [2]int
+	HeapInuse  [2]uint64
+}
+
+func (r SoakReport) OK() bool {
+	return len(r.Corrupt) == 0 && !r.leaking()
+}
+
+// leaking reports whether goroutines or heap use grew well past the
+// baseline taken after warm up.
+func (r SoakReport) leaking() bool {
+	return r.Goroutines[1] > r.Goroutines[0]+50 ||
+		(r.HeapInuse[0] > 0 && r.HeapInuse[1] > 4*r.HeapInuse[0])
+}
+
+// errSoakSkipped is returned by operations that didn't run, being rate
+// limited.
+var errSoakSkipped = errors.New("skipped")
+
+type soakOp struct {
+	name string
+	run  func(ctx context.Context, rng *rand.Rand) error
+}
+
+// RunSoak exercises the cache, refresh and server paths with random
+// operations until opts.Duration has passed or ctx is done, verifying the
+// store and sampling goroutines and heap use every check interval.
+// Operation errors are counted, not returned; corruption or a leak stops
+// the run with an error. The soak writes to q's store: run it on a private
+// copy, as the soak command does, to leave stored data alone.
+func (q *QuranService) RunSoak(ctx context.Context, opts SoakOptions) (SoakReport, error) {
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = time.Minute
+	}
+	report := SoakReport{Ops: make(map[string]int), Errors: make(map[string]int)}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return report, err
+	}
+	srv := &http.Server{Handler: NewServer(q), ReadHeaderTimeout: 10 * time.Second}
+	go srv.Serve(ln)
+	defer srv.Close()
+	base := "http://" + ln.Addr().String()
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	randomChapter := func(rng *rand.Rand) int { return rng.IntN(ChapterCount) + 1 }
+	ops := []soakOp{
+		{"get_chapter", func(ctx context.Context, rng *rand.Rand) error {
+			_, err := q.GetChapter(ctx, randomChapter(rng))
+			return err
+		}},
+		{"get_verse", func(ctx context.Context, rng *rand.Rand) error {
+			_, err := q.GetVerse(ctx, verseKey(verseAt(rng.IntN(VerseCount)+1)))
+			return err
+		}},
+		{"search", func(ctx context.Context, rng *rand.Rand) error {
+			query := []string{"الله", "mercy", "patience", "رب"}[rng.IntN(4)]
+			_, err := q.Search(ctx, query, SearchOptions{Limit: 20})
+			return err
+		}},
+		{"http_page", func(ctx context.Context, rng *rand.Rand) error {
+			url := base + "/pages/" + strconv.Itoa(rng.IntN(MushafPages)+1)
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("GET %s: status %d", url, resp.StatusCode)
+			}
+			return nil
+		}},
+	}
+
+	if opts.RefreshInterval > 0 {
+		var last time.Time
+		ops = append(ops, soakOp{"refresh_chapter", func(ctx context.Context, rng *rand.Rand) error {
+			if time.Since(last) < opts.RefreshInterval {
+				return errSoakSkipped
+			}
+			last = time.Now()
+			id := randomChapter(rng)
+			if err := q.deleteChapterDB(ctx, id); err != nil {
+				return err
+			}
+			_, err := q.GetChapter(ctx, id)
+			return err
+		}})
+	}
+
+	sample := func(i int) {
+		runtime.GC()
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		report.Goroutines[i] = runtime.NumGoroutine()
+		report.HeapInuse[i] = ms.HeapInuse
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x5eed))
+	deadline := time.Now().Add(opts.Duration)
+	nextCheck := time.Now().Add(opts.CheckInterval)
+	warm := false
+	for time.Now().Before(deadline) {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		op := ops[rng.IntN(len(ops))]
+		switch err := op.run(ctx, rng); {
+		case errors.Is(err, errSoakSkipped):
+		case err != nil && !errors.Is(err, context.Canceled):
+			report.Ops[op.name]++
+			report.Errors[op.name]++
+			q.log(ctx).Warn("soak operation failed", "op", op.name, "err", err)
+		default:
+			report.Ops[op.name]++
+		}
+
+		if time.Now().Before(nextCheck) {
+			continue
+		}
+		nextCheck = time.Now().Add(opts.CheckInterval)
+
+		verify, err := q.Verify(ctx)
+		if err != nil {
+			return report, err
+		}
+		report.Corrupt = append(report.Corrupt, verify.Failed...)
+		if !warm {
+			sample(0)
+			warm = true
+		}
+		sample(1)
+		q.log(ctx).Info("soak check", "ops", report.Ops, "errors", report.Errors,
+			"goroutines", report.Goroutines[1], "heap_inuse", report.HeapInuse[1])
+
+		if len(report.Corrupt) > 0 {
+			return report, fmt.Errorf("soak: %d corrupt chapters", len(report.Corrupt))
+		}
+		if report.leaking() {
+			return report, fmt.Errorf("soak: resource growth, goroutines %d -> %d, heap %d -> %d",
+				report.Goroutines[0], report.Goroutines[1], report.HeapInuse[0], report.HeapInuse[1])
+		}
+	}
+	return report, nil
+}
+
+func runSoak(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("soak", flag.ContinueOnError)
+	hours := fs.Float64("hours", 1, "how long to run")
+	seed := fs.Uint64("seed", uint64(time.Now().UnixNano()), "random seed")
+	interval := fs.Duration("check", time.Minute, "integrity and leak check interval")
+	refresh := fs.Duration("refresh", 0, "least time between refetching a chapter from upstream (0 never refetches)")
+	live := fs.Bool("live", false, "soak the configured store rather than a private in-memory copy of it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	target := q
+	if !*live {
+		var err error
+		if target, err = q.memoryCopy(ctx); err != nil {
+			return err
+		}
+	}
+	q.log(ctx).Info("soak started", "hours", *hours, "seed", *seed, "live", *live)
+	report, err := target.RunSoak(ctx, SoakOptions{
+		Duration:        time.Duration(*hours * float64(time.Hour)),
+		Seed:            *seed,
+		CheckInterval:   *interval,
+		RefreshInterval: *refresh,
+	})
+	for op, n := range report.Ops {
+		fmt.Printf("%s\t%d ops\t%d errors\n", op, n, report.Errors[op])
+	}
+	return err
+}
@@ -0,0 +1,225 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"unicode"
+)
+
+const bucketStats = "stats"
+
+// keyStats changes with the fields of Counts, so that summaries stored by
+// older releases are recounted rather than served without them.
+const keyStats = "summary/2"
+
+// Counts tallies the text of a group of verses, counted on the Uthmani
+// text by CountText.
+type Counts struct {
+	Verses     int `json:"verses"`
+	Words      int `json:"words"`
+	Letters    int `json:"letters"`
+	Diacritics int `json:"diacritics"`
+}
+
+func (c *Counts) add(o Counts) {
+	c.Verses += o.Verses
+	c.Words += o.Words
+	c.Letters += o.Letters
+	c.Diacritics += o.Diacritics
+}
+
+// isWaqfSign reports whether r is one of the small high pause marks, which
+// are combining marks but not diacritics.
+func isWaqfSign(r rune) bool {
+	return r >= '\u06D6' && r <= '\u06DC'
+}
+
+// CountText counts the words, letters and diacritics of Arabic text.
+// Counting bytes or runes gives wrong answers for text with tashkeel, as
+// every vowel mark is a rune of its own: letters here are base letters
+// only, with the alef variants counted once, and diacritics are the marks
+// on them, including the small waw and ya of the Uthmani script. Pause
+// marks, verse ends and other annotation signs are neither. Words are
+// those search matches. Verses is left zero.
+func CountText(text string) Counts {
+	var c Counts
+	for _, r := range text {
+		switch {
+		case isWaqfSign(r):
+		case unicode.Is(unicode.Mn, r), r == 'ۥ', r == 'ۦ':
+			c.Diacritics++
+		}
+	}
+	for _, w := range tokenize(text) {
+		c.Words++
+		for _, r := range w {
+			if unicode.IsLetter(r) {
+				c.Letters++
+			}
+		}
+	}
+	return c
+}
+
+// Stats summarizes the cached chapters. Chapters not in the store are left
+// out; Complete reports whether all 114 were counted.
+type Stats struct {
+	Chapters  int            `json:"chapters"`
+	Complete  bool           `json:"complete"`
+	Total     Counts         `json:"total"`
+	ByChapter map[int]Counts `json:"by_chapter"`
+	ByJuz     map[int]Counts `json:"by_juz"`
+	ByPage    map[int]Counts `json:"by_page"`
+	// ByRevelationPlace is keyed by the chapter's revelation place,
+	// "makkah" or "madinah".
+	ByRevelationPlace map[string]Counts `json:"by_revelation_place"`
+}
+
+func verseCounts(v Verse) Counts {
+	c := CountText(v.TextMadani)
+	c.Verses = 1
+	return c
+}
+
+// VerseCounts returns the counts of the verse with the given key.
+func (q *QuranService) VerseCounts(ctx context.Context, key string) (Counts, error) {
+	v, err := q.GetVerse(ctx, key)
+	if err != nil {
+		return Counts{}, err
+	}
+	return verseCounts(v), nil
+}
+
+func (s *Stats) addChapter(chapter Chapter) {
+	var total Counts
+	for _, v := range chapter.Verses {
+		c := verseCounts(v)
+		total.add(c)
+
+		juz := s.ByJuz[v.JuzNumber]
+		juz.add(c)
+		s.ByJuz[v.JuzNumber] = juz
+
+		page := s.ByPage[v.PageNumber]
+		page.add(c)
+		s.ByPage[v.PageNumber] = page
+	}
+
+	s.Chapters++
+	s.Total.add(total)
+	s.ByChapter[chapter.ID] = total
+	place := s.ByRevelationPlace[chapter.RevelationPlace]
+	place.add(total)
+	s.ByRevelationPlace[chapter.RevelationPlace] = place
+}
+
+// Stats returns verse, word and letter counts of the cached text. The
+// result is stored and reused until a chapter is written or removed; it
+// never fetches from upstream.
+func (q *QuranService) Stats(ctx context.Context) (Stats, error) {
+	var stats Stats
+	err := q.getValue(ctx, bucketStats, keyStats, &stats)
+	if err == nil {
+		return stats, nil
+	}
+	if !errors.Is(err, ErrKeyNotFound) {
+		return stats, err
+	}
+
+	stats = Stats{
+		ByChapter:         make(map[int]Counts),
+		ByJuz:             make(map[int]Counts),
+		ByPage:            make(map[int]Counts),
+		ByRevelationPlace: make(map[string]Counts),
+	}
+	err = q.store.Iterate(ctx, bucketChapters, func(key string, value []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := strconv.Atoi(key); err != nil {
+			return nil // not a chapter, e.g. the summaries entry
+		}
+
+		var chapter Chapter
+		if err := valueDecode(value, &chapter); err != nil {
+			return fmt.Errorf("chapter %s: %w", key, err)
+		}
+		stats.addChapter(chapter)
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+	stats.Complete = stats.Chapters == ChapterCount
+
+	if err := q.putValue(ctx, bucketStats, keyStats, stats); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// invalidateStats drops the stored summary after the cached text changed.
+func (q *QuranService) invalidateStats(ctx context.Context) error {
+	return q.deleteValue(ctx, bucketStats, keyStats)
+}
+
+func runStats(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	by := fs.String("by", "", "break down by chapter, juz, page or place")
+	verse := fs.String("verse", "", "count this verse only")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	printCounts := func(label string, c Counts) {
+		fmt.Printf("%s\t%d verses\t%d words\t%d letters\t%d diacritics\n", label, c.Verses, c.Words, c.Letters, c.Diacritics)
+	}
+	if *verse != "" {
+		c, err := q.VerseCounts(ctx, *verse)
+		if err != nil {
+			return err
+		}
+		printCounts(*verse, c)
+		return nil
+	}
+
+	stats, err := q.Stats(ctx)
+	if err != nil {
+		return err
+	}
+	printInts := func(m map[int]Counts, name string) {
+		keys := make([]int, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Ints(keys)
+		for _, k := range keys {
+			printCounts(name+" "+strconv.Itoa(k), m[k])
+		}
+	}
+
+	switch *by {
+	case "":
+		printCounts("total", stats.Total)
+	case "chapter":
+		printInts(stats.ByChapter, "chapter")
+	case "juz":
+		printInts(stats.ByJuz, "juz")
+	case "page":
+		printInts(stats.ByPage, "page")
+	case "place":
+		for _, place := range []string{"makkah", "madinah"} {
+			printCounts(place, stats.ByRevelationPlace[place])
+		}
+	default:
+		return errors.New("usage: stats [-by chapter|juz|page|place] [-verse key]")
+	}
+	if !stats.Complete {
+		fmt.Printf("only %d of %d chapters are cached; run sync for full counts\n", stats.Chapters, ChapterCount)
+	}
+	return nil
+}
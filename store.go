@@ -0,0 +1,183 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotFound is returned by a Store when the key is not present in the
+// namespace.
+var ErrKeyNotFound = errors.New("key not found")
+
+// Store is a namespaced key/value store backing the service cache. Values
+// returned from Get and passed to Iterate are owned by the caller.
+// Operations return ctx's error once it is done; Iterate checks it between
+// keys.
+type Store interface {
+	Get(ctx context.Context, namespace, key string) ([]byte, error)
+	Put(ctx context.Context, namespace, key string, value []byte) error
+	Delete(ctx context.Context, namespace, key string) error
+	Iterate(ctx context.Context, namespace string, fn func(key string, value []byte) error) error
+	Close() error
+}
+
+type StoreBackend string
+
+const (
+	BackendBolt   StoreBackend = "bolt"
+	BackendBBolt  StoreBackend = "bbolt"
+	BackendBadger StoreBackend = "badger"
+	BackendFS     StoreBackend = "fs"
+	BackendMemory StoreBackend = "memory"
+	BackendRedis  StoreBackend = "redis"
+)
+
+type StoreConfig struct {
+	Backend StoreBackend `yaml:"backend" toml:"backend"`
+	// Path is the database file for bolt/bbolt, the directory for badger
+	// and fs, and a redis:// URL for redis. It is ignored by the memory
+	// backend.
+	Path string `yaml:"path" toml:"path"`
+	// TTL expires cached values; only the redis backend supports it.
+	TTL time.Duration `yaml:"ttl" toml:"ttl"`
+	// SlowWrite is the bolt/bbolt write duration above which a warning is
+	// logged. It defaults to 250ms; a negative value disables the warning.
+	SlowWrite time.Duration `yaml:"slow_write" toml:"slow_write"`
+	// ReadOnly opens bolt/bbolt and badger databases read-only, letting
+	// several processes open the same file, leaves fs directories as they
+	// are, and makes the store reloadable; see WithReadOnly.
+	ReadOnly bool `yaml:"read_only" toml:"read_only"`
+}
+
+// OpenStore opens the store described by cfg. bbolt is the default backend;
+// it reads databases written by boltdb/bolt, so an existing quran.db keeps
+// working.
+func OpenStore(cfg StoreConfig) (Store, error) {
+	if cfg.ReadOnly {
+		return NewReloadableStore(cfg)
+	}
+	s, err := openStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if ts, ok := s.(timedStore); ok && cfg.SlowWrite != 0 {
+		ts.txTimings().setSlowWrite(cfg.SlowWrite)
+	}
+	return s, nil
+}
+
+func openStore(cfg StoreConfig) (Store, error) {
+	switch cfg.Backend {
+	case BackendBBolt, "":
+		return openBBoltStore(cfg.Path, cfg.ReadOnly)
+	case BackendBolt:
+		return openBoltStore(cfg.Path, cfg.ReadOnly)
+	case BackendBadger:
+		return openBadgerStore(cfg.Path, cfg.ReadOnly)
+	case BackendFS:
+		return openFSStore(cfg.Path, cfg.ReadOnly)
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendRedis:
+		return OpenRedisStore(cfg.Path, RedisStoreOptions{TTL: cfg.TTL})
+	default:
+		return nil, fmt.Errorf("unknown store backend: %q", cfg.Backend)
+	}
+}
+
+type memoryStore struct {
+	mu         sync.RWMutex
+	namespaces map[string]map[string][]byte
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{namespaces: make(map[string]map[string][]byte)}
+}
+
+func (m *memoryStore) Get(ctx context.Context, namespace, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	v, ok := m.namespaces[namespace][key]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return append([]byte(nil), v...), nil
+}
+
+func (m *memoryStore) Put(ctx context.Context, namespace, key string, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ns, ok := m.namespaces[namespace]
+	if !ok {
+		ns = make(map[string][]byte)
+		m.namespaces[namespace] = ns
+	}
+	ns[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, namespace, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.namespaces[namespace], key)
+	return nil
+}
+
+func (m *memoryStore) Snapshot(ctx context.Context, namespaces ...string) (Store, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return copyNamespaces(ctx, namespaces, func(namespace string, fn func(key string, value []byte) error) error {
+		for k, v := range m.namespaces[namespace] {
+			if err := fn(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *memoryStore) Iterate(ctx context.Context, namespace string, fn func(key string, value []byte) error) error {
+	m.mu.RLock()
+	ns := m.namespaces[namespace]
+	keys := make([]string, 0, len(ns))
+	for k := range ns {
+		keys = append(keys, k)
+	}
+	m.mu.RUnlock()
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v, err := m.Get(ctx, namespace, k)
+		if errors.Is(err, ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
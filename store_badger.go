@@ -0,0 +1,121 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+type badgerStore struct {
+	db *badger.DB
+}
+
+func OpenBadgerStore(dir string) (Store, error) {
+	return openBadgerStore(dir, false)
+}
+
+func openBadgerStore(dir string, readOnly bool) (Store, error) {
+	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(nil).WithReadOnly(readOnly))
+	if err != nil {
+		return nil, err
+	}
+	return &badgerStore{db: db}, nil
+}
+
+// badgerKey prefixes key with its namespace; the NUL separator cannot occur
+// in namespace names.
+func badgerKey(namespace, key string) []byte {
+	return []byte(namespace + "\x00" + key)
+}
+
+func (s *badgerStore) Get(ctx context.Context, namespace, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var out []byte
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(badgerKey(namespace, key))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return ErrKeyNotFound
+		}
+		if err != nil {
+			return err
+		}
+		out, err = item.ValueCopy(nil)
+		return err
+	})
+	return out, err
+}
+
+func (s *badgerStore) Put(ctx context.Context, namespace, key string, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(badgerKey(namespace, key), value)
+	})
+}
+
+func (s *badgerStore) Delete(ctx context.Context, namespace, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(badgerKey(namespace, key))
+	})
+}
+
+func (s *badgerStore) Iterate(ctx context.Context, namespace string, fn func(key string, value []byte) error) error {
+	prefix := badgerKey(namespace, "")
+	return s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			item := it.Item()
+			v, err := item.ValueCopy(nil)
+			if err != nil {
+				return err
+			}
+			if err := fn(string(item.Key()[len(prefix):]), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Snapshot copies the namespaces in one read transaction.
+func (s *badgerStore) Snapshot(ctx context.Context, namespaces ...string) (Store, error) {
+	var out Store
+	err := s.db.View(func(txn *badger.Txn) error {
+		var err error
+		out, err = copyNamespaces(ctx, namespaces, func(namespace string, fn func(key string, value []byte) error) error {
+			prefix := badgerKey(namespace, "")
+			it := txn.NewIterator(badger.DefaultIteratorOptions)
+			defer it.Close()
+
+			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+				item := it.Item()
+				v, err := item.ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				if err := fn(string(item.Key()[len(prefix):]), v); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return err
+	})
+	return out, err
+}
+
+func (s *badgerStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,243 @@
+package quranapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+type boltStore struct {
+	db      *bolt.DB
+	timings *txTimings
+}
+
+// OpenBoltStore opens a store on the unmaintained boltdb/bolt. Prefer
+// OpenBBoltStore, which reads the same file format.
+func OpenBoltStore(path string) (Store, error) {
+	return openBoltStore(path, false)
+}
+
+func openBoltStore(path string, readOnly bool) (Store, error) {
+	opts := *bolt.DefaultOptions
+	opts.ReadOnly = readOnly
+	db, err := bolt.Open(path, os.ModePerm, &opts)
+	if err != nil {
+		return nil, err
+	}
+	return &boltStore{db: db, timings: newTxTimings()}, nil
+}
+
+func (s *boltStore) txTimings() *txTimings {
+	return s.timings
+}
+
+func (s *boltStore) Get(ctx context.Context, namespace, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer s.timings.observe("get", namespace, false, time.Now())
+
+	var out []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(namespace))
+		if b == nil {
+			return ErrKeyNotFound
+		}
+		v := b.Get([]byte(key))
+		if v == nil {
+			return ErrKeyNotFound
+		}
+		out = append([]byte(nil), v...)
+		return nil
+	})
+	return out, err
+}
+
+func (s *boltStore) Put(ctx context.Context, namespace, key string, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer s.timings.observe("put", namespace, true, time.Now())
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(namespace))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), value)
+	})
+}
+
+// PutBatch writes values in one transaction, saving the sync per key that
+// separate Puts cost.
+func (s *boltStore) PutBatch(ctx context.Context, namespace string, values map[string][]byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer s.timings.observe("put_batch", namespace, true, time.Now())
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(namespace))
+		if err != nil {
+			return err
+		}
+		for key, value := range values {
+			if err := b.Put([]byte(key), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *boltStore) Delete(ctx context.Context, namespace, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	defer s.timings.observe("delete", namespace, true, time.Now())
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(namespace))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+func (s *boltStore) Iterate(ctx context.Context, namespace string, fn func(key string, value []byte) error) error {
+	defer s.timings.observe("iterate", namespace, false, time.Now())
+
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(namespace))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			if v == nil { // nested bucket
+				return nil
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return fn(string(k), append([]byte(nil), v...))
+		})
+	})
+}
+
+// Snapshot copies the namespaces in one read transaction, which is closed
+// before returning so that it doesn't hold up writers growing the file.
+func (s *boltStore) Snapshot(ctx context.Context, namespaces ...string) (Store, error) {
+	defer s.timings.observe("snapshot", "", false, time.Now())
+
+	var out Store
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		out, err = copyNamespaces(ctx, namespaces, func(namespace string, fn func(key string, value []byte) error) error {
+			b := tx.Bucket([]byte(namespace))
+			if b == nil {
+				return nil
+			}
+			return b.ForEach(func(k, v []byte) error {
+				if v == nil { // nested bucket
+					return nil
+				}
+				return fn(string(k), v)
+			})
+		})
+		return err
+	})
+	return out, err
+}
+
+// WriteBackup writes a consistent copy of the database file to w from a
+// read transaction, so writers carry on meanwhile.
+func (s *boltStore) WriteBackup(ctx context.Context, w io.Writer) (int64, error) {
+	defer s.timings.observe("backup", "", false, time.Now())
+
+	var n int64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		n, err = tx.WriteTo(ctxWriter{ctx: ctx, w: w})
+		return err
+	})
+	return n, err
+}
+
+// RestoreBackup replaces every bucket with those of the database file at
+// path in a single write transaction.
+func (s *boltStore) RestoreBackup(ctx context.Context, path string) error {
+	defer s.timings.observe("restore", "", true, time.Now())
+
+	src, err := bolt.Open(path, 0o600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer src.Close()
+
+	return src.View(func(stx *bolt.Tx) error {
+		return s.db.Update(func(tx *bolt.Tx) error {
+			var names [][]byte
+			tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+				names = append(names, append([]byte(nil), name...))
+				return nil
+			})
+			for _, name := range names {
+				if err := tx.DeleteBucket(name); err != nil {
+					return err
+				}
+			}
+
+			return stx.ForEach(func(name []byte, sb *bolt.Bucket) error {
+				b, err := tx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+				return sb.ForEach(func(k, v []byte) error {
+					if v == nil { // nested bucket
+						return nil
+					}
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					return b.Put(k, v)
+				})
+			})
+		})
+	})
+}
+
+func (s *boltStore) DBStats(ctx context.Context) (DBStats, error) {
+	st := s.db.Stats()
+	out := DBStats{
+		PageSize:     s.db.Info().PageSize,
+		FreePages:    st.FreePageN,
+		PendingPages: st.PendingPageN,
+		FreeBytes:    int64(st.FreeAlloc),
+	}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		out.Size = tx.Size()
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			bs := b.Stats()
+			out.Buckets = append(out.Buckets, BucketStats{
+				Name:  string(name),
+				Keys:  bs.KeyN,
+				InUse: int64(bs.BranchInuse + bs.LeafInuse),
+				Alloc: int64(bs.BranchAlloc + bs.LeafAlloc),
+			})
+			return nil
+		})
+	})
+	return out, err
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
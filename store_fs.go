@@ -0,0 +1,186 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fsStore keeps one file per key under dir/<namespace>/. Keys are path
+// escaped so verse keys and the like are safe file names, on Windows too.
+type fsStore struct {
+	dir string
+}
+
+func OpenFSStore(dir string) (Store, error) {
+	return openFSStore(dir, false)
+}
+
+// fsFormat is written to the store's format file once its names are
+// escaped as fsName escapes them, so that they aren't checked again.
+const (
+	fsFormatFile = ".format"
+	fsFormat     = "2"
+)
+
+// openFSStore opens the store in dir, renaming names written by older
+// versions unless readOnly is set, in which case dir is left alone.
+func openFSStore(dir string, readOnly bool) (Store, error) {
+	if readOnly {
+		return &fsStore{dir: dir}, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	marker := filepath.Join(dir, fsFormatFile)
+	if b, err := os.ReadFile(marker); err != nil || string(b) != fsFormat {
+		if err := renameLegacyFSNames(dir); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(marker, []byte(fsFormat), 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return &fsStore{dir: dir}, nil
+}
+
+func (s *fsStore) path(namespace, key string) string {
+	return filepath.Join(s.dir, fsName(namespace), fsName(key))
+}
+
+// fsName escapes s into a single path element. A leading dot is escaped too,
+// which keeps "." and ".." out of paths and leaves dot files free for temp
+// files, as is the colon that PathEscape leaves alone but Windows reserves.
+func fsName(s string) string {
+	s = strings.ReplaceAll(url.PathEscape(s), ":", "%3A")
+	if strings.HasPrefix(s, ".") {
+		s = "%2E" + s[1:]
+	}
+	return s
+}
+
+// renameLegacyFSNames renames the namespaces and keys of dir written before
+// colons were escaped, such as verse keys, to their escaped names.
+func renameLegacyFSNames(dir string) error {
+	var rename func(dir string, depth int) error
+	rename = func(dir string, depth int) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if strings.Contains(name, ":") && name[0] != '.' {
+				unescaped, err := url.PathUnescape(name)
+				if err != nil {
+					continue
+				}
+				if err := os.Rename(filepath.Join(dir, name), filepath.Join(dir, fsName(unescaped))); err != nil {
+					return err
+				}
+				name = fsName(unescaped)
+			}
+			if e.IsDir() && depth == 0 {
+				if err := rename(filepath.Join(dir, name), 1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return rename(dir, 0)
+}
+
+func (s *fsStore) Get(ctx context.Context, namespace, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(s.path(namespace, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrKeyNotFound
+	}
+	return b, err
+}
+
+func (s *fsStore) Put(ctx context.Context, namespace, key string, value []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p := s.path(namespace, key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temp file and rename so readers never see a partial value
+	f, err := os.CreateTemp(filepath.Dir(p), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(value); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), p)
+}
+
+func (s *fsStore) Delete(ctx context.Context, namespace, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := os.Remove(s.path(namespace, key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *fsStore) Iterate(ctx context.Context, namespace string, fn func(key string, value []byte) error) error {
+	entries, err := os.ReadDir(filepath.Join(s.dir, fsName(namespace)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || e.Name()[0] == '.' {
+			continue
+		}
+		key, err := url.PathUnescape(e.Name())
+		if err != nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		v, err := s.Get(ctx, namespace, key)
+		if errors.Is(err, ErrKeyNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(key, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fsStore) Close() error {
+	return nil
+}
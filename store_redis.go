@@ -0,0 +1,212 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisStoreOptions struct {
+	// Prefix namespaces every key so several services can share a Redis
+	// database. Defaults to "quranapi".
+	Prefix string
+	// TTL expires values after the given duration; zero keeps them forever.
+	// NamespaceTTL overrides it per namespace.
+	TTL          time.Duration
+	NamespaceTTL map[string]time.Duration
+}
+
+type redisStore struct {
+	client redis.UniversalClient
+	opts   RedisStoreOptions
+}
+
+// NewRedisStore returns a Store backed by Redis, letting several server
+// replicas share one cache. Closing the store closes the client. Unlike
+// OpenRedisStore, it leaves keys written by older versions as they are.
+func NewRedisStore(client redis.UniversalClient, opts RedisStoreOptions) Store {
+	if opts.Prefix == "" {
+		opts.Prefix = "quranapi"
+	}
+	return &redisStore{client: client, opts: opts}
+}
+
+// OpenRedisStore connects to the Redis server at a redis:// URL.
+func OpenRedisStore(rawURL string, opts RedisStoreOptions) (Store, error) {
+	redisOpts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	s := NewRedisStore(redis.NewClient(redisOpts), opts).(*redisStore)
+	if err := s.migrateKeys(context.Background()); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("migrate redis keys: %w", err)
+	}
+	return s, nil
+}
+
+// redisKeyFormat is the format of the keys of the store, recorded once
+// older keys are migrated. Format 2 escapes the colons of namespaces, which
+// the user IDs of user data namespaces have, so that the keys of one user
+// never match the scan for the namespace of another, as those of
+// "bookmarks@jwt:a:b" would "bookmarks@jwt:a".
+const redisKeyFormat = "2"
+
+var redisNamespaceEscaper = strings.NewReplacer("%", "%25", ":", "%3A")
+
+func (s *redisStore) key(namespace, key string) string {
+	return s.opts.Prefix + ":" + redisNamespaceEscaper.Replace(namespace) + ":" + key
+}
+
+// migrateKeys renames the keys of user data namespaces written before
+// redisKeyFormat 2 to escape their user IDs.
+func (s *redisStore) migrateKeys(ctx context.Context) error {
+	marker := s.key("store_meta", "key_format")
+	format, err := s.client.Get(ctx, marker).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if format == redisKeyFormat {
+		return nil
+	}
+
+	// users whose IDs have colons of their own, such as JWT subjects
+	// that are URNs, are told apart from the keys after them by the
+	// users the service has seen
+	var users []string
+	for _, ns := range []string{bucketDataUsers, bucketAPIUsage} {
+		err := s.Iterate(ctx, ns, func(user string, _ []byte) error {
+			users = append(users, user)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return len(users[i]) > len(users[j]) })
+
+	prefix := s.opts.Prefix + ":"
+	iter := s.client.Scan(ctx, 0, redisGlobEscape(prefix)+"*@*", 100).Iterator()
+	for iter.Next(ctx) {
+		namespace, key, ok := splitLegacyRedisKey(strings.TrimPrefix(iter.Val(), prefix), users)
+		if !ok {
+			continue
+		}
+		// RENAME keeps the TTL of the key
+		err := s.client.Rename(ctx, iter.Val(), s.key(namespace, key)).Err()
+		if err != nil && !strings.Contains(err.Error(), "no such key") {
+			return err
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return s.client.Set(ctx, marker, redisKeyFormat, 0).Err()
+}
+
+// splitLegacyRedisKey splits the namespace:key of a key in the first key
+// format, reporting whether it is one of a user data namespace whose user
+// ID needs escaping. IDs are matched against users, longest first, and are
+// otherwise taken to be a kind and an ID, as "key:3f2a9c01d4e5" is.
+func splitLegacyRedisKey(s string, users []string) (namespace, key string, ok bool) {
+	base, rest, found := strings.Cut(s, "@")
+	if !found || strings.Contains(base, ":") {
+		return "", "", false
+	}
+	user := ""
+	for _, u := range users {
+		if strings.HasPrefix(rest, u+":") {
+			user = u
+			break
+		}
+	}
+	if user == "" {
+		kind, after, _ := strings.Cut(rest, ":")
+		id, _, found := strings.Cut(after, ":")
+		if kind != "key" && kind != "jwt" || !found {
+			return "", "", false
+		}
+		user = kind + ":" + id
+	}
+	if !strings.ContainsAny(user, ":%") {
+		return "", "", false
+	}
+	return base + "@" + user, strings.TrimPrefix(rest, user+":"), true
+}
+
+func (s *redisStore) ttl(namespace string) time.Duration {
+	if ttl, ok := s.opts.NamespaceTTL[namespace]; ok {
+		return ttl
+	}
+	return s.opts.TTL
+}
+
+func (s *redisStore) Get(ctx context.Context, namespace, key string) ([]byte, error) {
+	b, err := s.client.Get(ctx, s.key(namespace, key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrKeyNotFound
+	}
+	return b, err
+}
+
+func (s *redisStore) Put(ctx context.Context, namespace, key string, value []byte) error {
+	return s.client.Set(ctx, s.key(namespace, key), value, s.ttl(namespace)).Err()
+}
+
+func (s *redisStore) Delete(ctx context.Context, namespace, key string) error {
+	return s.client.Del(ctx, s.key(namespace, key)).Err()
+}
+
+func (s *redisStore) Iterate(ctx context.Context, namespace string, fn func(key string, value []byte) error) error {
+	prefix := s.key(namespace, "")
+	iter := s.client.Scan(ctx, 0, redisGlobEscape(prefix)+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		v, err := s.client.Get(ctx, iter.Val()).Bytes()
+		if errors.Is(err, redis.Nil) { // expired or deleted mid scan
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(strings.TrimPrefix(iter.Val(), prefix), v); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
+func (s *redisStore) AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	key := s.key(bucketLeases, name)
+	ok, err := s.client.SetNX(ctx, key, owner, ttl).Result()
+	if err != nil || ok {
+		return ok, err
+	}
+	cur, err := s.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	return err == nil && cur == owner, err
+}
+
+// releaseScript deletes a lease only if it is still held by the owner
+// releasing it, not by one that took it over after it expired.
+var releaseScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+func (s *redisStore) ReleaseLease(ctx context.Context, name, owner string) error {
+	return releaseScript.Run(ctx, s.client, []string{s.key(bucketLeases, name)}, owner).Err()
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
+
+var redisGlobReplacer = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+func redisGlobEscape(s string) string {
+	return redisGlobReplacer.Replace(s)
+}
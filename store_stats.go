@@ -0,0 +1,83 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// ErrDBStatsUnsupported is returned by DBStats for stores other than bolt
+// and bbolt.
+var ErrDBStatsUnsupported = errors.New("store does not report database stats")
+
+// BucketStats describes the space one namespace takes in a bolt database.
+type BucketStats struct {
+	Name string
+	Keys int
+	// InUse is the bytes holding keys and values; Alloc is the bytes of
+	// the pages allocated to them.
+	InUse int64
+	Alloc int64
+}
+
+// DBStats describes a bolt database file. Free pages are reused by later
+// writes but only returned to the file system by compaction.
+type DBStats struct {
+	Size         int64
+	PageSize     int
+	FreePages    int
+	PendingPages int // freed by transactions still open
+	FreeBytes    int64
+	Buckets      []BucketStats
+}
+
+// dbStatser is implemented by stores that can describe their database file.
+type dbStatser interface {
+	DBStats(ctx context.Context) (DBStats, error)
+}
+
+// DBStats reports the size of the database and of each namespace in it.
+func (q *QuranService) DBStats(ctx context.Context) (DBStats, error) {
+	s, ok := q.store.(dbStatser)
+	if !ok {
+		return DBStats{}, ErrDBStatsUnsupported
+	}
+	return s.DBStats(ctx)
+}
+
+func runDB(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) == 0 || args[0] != "stats" {
+		return errors.New("usage: db stats")
+	}
+
+	stats, err := q.DBStats(ctx)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "bucket\tkeys\tin use\tallocated\t")
+	for _, b := range stats.Buckets {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t\n", b.Name, b.Keys, formatBytes(b.InUse), formatBytes(b.Alloc))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\nfile %s, page size %d, %d free pages (%s), %d pending\n",
+		formatBytes(stats.Size), stats.PageSize, stats.FreePages, formatBytes(stats.FreeBytes), stats.PendingPages)
+	return nil
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,138 @@
+package quranapi
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultSlowWrite is the Update duration above which a write is logged as
+// slow. Writes on SD cards and other slow media routinely cross it.
+const defaultSlowWrite = 250 * time.Millisecond
+
+// TxStats summarises the transactions of one store operation on one
+// namespace.
+type TxStats struct {
+	Op        string // get, put, put_batch, delete, iterate, backup and so on
+	Namespace string
+	Count     uint64
+	Slow      uint64 // writes slower than the slow write threshold
+	Total     time.Duration
+	Max       time.Duration
+}
+
+func (s TxStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// txTimings records transaction durations for the bolt stores.
+type txTimings struct {
+	mu        sync.Mutex
+	slowWrite time.Duration
+	ops       map[[2]string]*TxStats
+}
+
+func newTxTimings() *txTimings {
+	return &txTimings{slowWrite: defaultSlowWrite, ops: make(map[[2]string]*TxStats)}
+}
+
+// observe records a transaction started at start. Writes slower than the
+// threshold are logged.
+func (t *txTimings) observe(op, namespace string, write bool, start time.Time) {
+	d := time.Since(start)
+
+	t.mu.Lock()
+	s, ok := t.ops[[2]string{op, namespace}]
+	if !ok {
+		s = &TxStats{Op: op, Namespace: namespace}
+		t.ops[[2]string{op, namespace}] = s
+	}
+	s.Count++
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+	slow := write && t.slowWrite > 0 && d > t.slowWrite
+	if slow {
+		s.Slow++
+	}
+	t.mu.Unlock()
+
+	if slow {
+		slog.Warn("slow store write", "op", op, "namespace", namespace, "duration", d, "threshold", t.slowWrite)
+	}
+}
+
+func (t *txTimings) setSlowWrite(d time.Duration) {
+	t.mu.Lock()
+	t.slowWrite = d
+	t.mu.Unlock()
+}
+
+func (t *txTimings) stats() []TxStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]TxStats, 0, len(t.ops))
+	for _, s := range t.ops {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Op != out[j].Op {
+			return out[i].Op < out[j].Op
+		}
+		return out[i].Namespace < out[j].Namespace
+	})
+	return out
+}
+
+// timedStore is implemented by stores that record transaction durations.
+type timedStore interface {
+	txTimings() *txTimings
+}
+
+// StoreTxStats reports transaction durations per operation and namespace.
+// It is empty for backends other than bolt and bbolt.
+func (q *QuranService) StoreTxStats() []TxStats {
+	ts, ok := q.store.(timedStore)
+	if !ok {
+		return nil
+	}
+	return ts.txTimings().stats()
+}
+
+type txStatsResponse struct {
+	Op        string  `json:"op"`
+	Namespace string  `json:"namespace"`
+	Count     uint64  `json:"count"`
+	Slow      uint64  `json:"slow"`
+	TotalMS   float64 `json:"total_ms"`
+	MeanMS    float64 `json:"mean_ms"`
+	MaxMS     float64 `json:"max_ms"`
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// handleStoreStats serves StoreTxStats on the admin endpoints.
+func (q *QuranService) handleStoreStats(w http.ResponseWriter, r *http.Request) {
+	stats := q.StoreTxStats()
+	out := make([]txStatsResponse, len(stats))
+	for i, s := range stats {
+		out[i] = txStatsResponse{
+			Op:        s.Op,
+			Namespace: s.Namespace,
+			Count:     s.Count,
+			Slow:      s.Slow,
+			TotalMS:   milliseconds(s.Total),
+			MeanMS:    milliseconds(s.Mean()),
+			MaxMS:     milliseconds(s.Max),
+		}
+	}
+	writeJSON(w, out)
+}
@@ -0,0 +1,406 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// bucketSync holds the version of every user data record as last synced,
+// and the ID of this device. It is scoped to the user like user data but
+// not backed up with it.
+const bucketSync = "sync"
+
+const (
+	keySyncDevice         = "device"
+	syncRecordKeyPrefix   = "record/"
+	syncModifiedKeyPrefix = "modified/"
+)
+
+// maxSyncState caps the size of a sync request or response.
+const maxSyncState = 64 << 20
+
+// syncNamespaces are the user data namespaces kept in step by Sync. The
+// trash is left out: a record deleted on one device is moved to the trash
+// of the other.
+var syncNamespaces = slices.DeleteFunc(slices.Clone(userDataNamespaces), func(ns string) bool {
+	return ns == bucketTrash
+})
+
+// VersionVector counts the changes each device made to a record. Of two
+// versions, the one whose counts are all at least those of the other
+// happened after it; otherwise they were made concurrently.
+type VersionVector map[string]uint64
+
+// descends reports whether v has seen every change of other.
+func (v VersionVector) descends(other VersionVector) bool {
+	for device, n := range other {
+		if v[device] < n {
+			return false
+		}
+	}
+	return true
+}
+
+// merge returns the changes seen by either v or other.
+func (v VersionVector) merge(other VersionVector) VersionVector {
+	out := make(VersionVector, len(v))
+	for device, n := range v {
+		out[device] = n
+	}
+	for device, n := range other {
+		out[device] = max(out[device], n)
+	}
+	return out
+}
+
+// SyncRecord is the state of one user data record exchanged by Sync.
+type SyncRecord struct {
+	Namespace string        `json:"namespace"`
+	Key       string        `json:"key"`
+	Value     []byte        `json:"value,omitempty"`
+	Deleted   bool          `json:"deleted,omitempty"`
+	Version   VersionVector `json:"version"`
+	// Modified is when the change was written on the device making it,
+	// or first seen by it for changes made without the service; it is the
+	// tie-break between concurrent changes.
+	Modified time.Time `json:"modified"`
+	Device   string    `json:"device"`
+}
+
+// newer reports whether r wins over other, made concurrently: the last
+// write wins, and the device ID breaks a tie.
+func (r SyncRecord) newer(other SyncRecord) bool {
+	if !r.Modified.Equal(other.Modified) {
+		return r.Modified.After(other.Modified)
+	}
+	return r.Device > other.Device
+}
+
+// syncMeta is what bucketSync keeps of a record.
+type syncMeta struct {
+	Hash     string
+	Deleted  bool
+	Version  VersionVector
+	Modified time.Time
+	Device   string
+}
+
+type syncRequest struct {
+	Device  string       `json:"device"`
+	Records []SyncRecord `json:"records"`
+}
+
+// SyncReport counts the records a sync changed on this device.
+type SyncReport struct {
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+	Conflicts int `json:"conflicts"`
+}
+
+func valueHash(value []byte) string {
+	sum := sha256.Sum256(value)
+	return hex.EncodeToString(sum[:])
+}
+
+func syncRecordKey(namespace, key string) string {
+	return syncRecordKeyPrefix + namespace + "/" + key
+}
+
+// touchSyncRecord records that a record of the sync namespaces was written
+// or deleted now, the time SyncState gives the change.
+func (q *QuranService) touchSyncRecord(ctx context.Context, namespace, key string) error {
+	return q.putValue(ctx, bucketSync, syncModifiedKeyPrefix+namespace+"/"+key, time.Now().UTC())
+}
+
+// syncDevice returns the ID of this device, created on first use.
+func (q *QuranService) syncDevice(ctx context.Context) (string, error) {
+	var id string
+	err := q.getValue(ctx, bucketSync, keySyncDevice, &id)
+	if errors.Is(err, ErrKeyNotFound) {
+		id = randomID()
+		err = q.putValue(ctx, bucketSync, keySyncDevice, id)
+	}
+	return id, err
+}
+
+// SyncState returns the state of every user data record of the user of
+// ctx, deleted ones included. Records changed since the last call are
+// first given a new version by this device, modified when they were last
+// written.
+func (q *QuranService) SyncState(ctx context.Context) ([]SyncRecord, error) {
+	device, err := q.syncDevice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metas := make(map[string]syncMeta)
+	written := make(map[string]time.Time)
+	err = q.iterate(ctx, bucketSync, func(key string, value []byte) error {
+		if rest, ok := strings.CutPrefix(key, syncModifiedKeyPrefix); ok {
+			var t time.Time
+			if err := valueDecode(value, &t); err != nil {
+				return err
+			}
+			written[syncRecordKeyPrefix+rest] = t
+			return nil
+		}
+		if !strings.HasPrefix(key, syncRecordKeyPrefix) {
+			return nil
+		}
+		var m syncMeta
+		if err := valueDecode(value, &m); err != nil {
+			return err
+		}
+		metas[key] = m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var out []SyncRecord
+	bump := func(namespace, key string, value []byte, deleted bool) error {
+		mkey := syncRecordKey(namespace, key)
+		m := metas[mkey]
+		delete(metas, mkey)
+		hash := ""
+		if !deleted {
+			hash = valueHash(value)
+		}
+		if m.Version == nil || m.Hash != hash || m.Deleted != deleted {
+			// a write time older than the last change is that of a change
+			// made without the service, seen only now
+			modified := now
+			if t, ok := written[mkey]; ok && t.After(m.Modified) && !t.After(now) {
+				modified = t
+			}
+			m.Version = m.Version.merge(VersionVector{device: m.Version[device] + 1})
+			m.Hash, m.Deleted, m.Modified, m.Device = hash, deleted, modified, device
+			if err := q.putValue(ctx, bucketSync, mkey, m); err != nil {
+				return err
+			}
+		}
+		out = append(out, SyncRecord{
+			Namespace: namespace, Key: key, Value: value, Deleted: deleted,
+			Version: m.Version, Modified: m.Modified, Device: m.Device,
+		})
+		return nil
+	}
+	for _, ns := range syncNamespaces {
+		var values [][2][]byte
+		err := q.iterate(ctx, ns, func(key string, value []byte) error {
+			values = append(values, [2][]byte{[]byte(key), value})
+			return ctx.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, kv := range values {
+			if err := bump(ns, string(kv[0]), kv[1], false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// records known before but gone now were deleted
+	for mkey := range metas {
+		ns, key, _ := strings.Cut(strings.TrimPrefix(mkey, syncRecordKeyPrefix), "/")
+		if err := bump(ns, key, nil, true); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// ApplySync merges the records of another device into the user data of
+// the user of ctx. A record replaces the one here when its version has
+// seen every change made here; when both were changed since they last
+// synced, the last write wins. Deleted records are moved to the trash.
+func (q *QuranService) ApplySync(ctx context.Context, records []SyncRecord) (SyncReport, error) {
+	q.syncMu.Lock()
+	defer q.syncMu.Unlock()
+	return q.applySync(ctx, records)
+}
+
+func (q *QuranService) applySync(ctx context.Context, records []SyncRecord) (SyncReport, error) {
+	var report SyncReport
+	// bring the versions of local changes up to date first
+	local, err := q.SyncState(ctx)
+	if err != nil {
+		return report, err
+	}
+	byKey := make(map[string]SyncRecord, len(local))
+	for _, r := range local {
+		byKey[syncRecordKey(r.Namespace, r.Key)] = r
+	}
+
+	for _, r := range records {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if !slices.Contains(syncNamespaces, r.Namespace) || r.Key == "" {
+			continue
+		}
+		mkey := syncRecordKey(r.Namespace, r.Key)
+		mine, ok := byKey[mkey]
+		switch {
+		case ok && mine.Version.descends(r.Version):
+			continue // seen already
+		case ok && !r.Version.descends(mine.Version):
+			report.Conflicts++
+			if !r.newer(mine) {
+				// keep ours, but remember theirs was seen
+				m := syncMeta{Deleted: mine.Deleted, Version: mine.Version.merge(r.Version), Modified: mine.Modified, Device: mine.Device}
+				if !mine.Deleted {
+					m.Hash = valueHash(mine.Value)
+				}
+				if err := q.putValue(ctx, bucketSync, mkey, m); err != nil {
+					return report, err
+				}
+				continue
+			}
+			r.Version = r.Version.merge(mine.Version)
+		}
+
+		m := syncMeta{Deleted: r.Deleted, Version: r.Version, Modified: r.Modified, Device: r.Device}
+		if r.Deleted {
+			if ok && !mine.Deleted {
+				if err := q.softDelete(ctx, r.Namespace, r.Key); err != nil && !errors.Is(err, ErrKeyNotFound) {
+					return report, err
+				}
+				report.Deleted++
+			}
+		} else {
+			if err := q.putRaw(ctx, r.Namespace, r.Key, r.Value); err != nil {
+				return report, err
+			}
+			m.Hash = valueHash(r.Value)
+			report.Updated++
+		}
+		if err := q.putValue(ctx, bucketSync, mkey, m); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// Sync reconciles the user data of the user of ctx with the instance
+// serving the sync endpoint at peerURL, both ways. apiKey authenticates
+// with the peer, and chooses whose data is synced when it keeps data per
+// user.
+func (q *QuranService) Sync(ctx context.Context, peerURL, apiKey string) (SyncReport, error) {
+	q.syncMu.Lock()
+	defer q.syncMu.Unlock()
+
+	device, err := q.syncDevice(ctx)
+	if err != nil {
+		return SyncReport{}, err
+	}
+	records, err := q.SyncState(ctx)
+	if err != nil {
+		return SyncReport{}, err
+	}
+	body, err := json.Marshal(syncRequest{Device: device, Records: records})
+	if err != nil {
+		return SyncReport{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(peerURL, "/")+"/sync", bytes.NewReader(body))
+	if err != nil {
+		return SyncReport{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := q.doer.Do(req)
+	if err != nil {
+		return SyncReport{}, fmt.Errorf("sync with %s: %w", peerURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return SyncReport{}, fmt.Errorf("sync with %s: status %d: %s", peerURL, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	var peer syncRequest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSyncState)).Decode(&peer); err != nil {
+		return SyncReport{}, fmt.Errorf("sync with %s: %w", peerURL, err)
+	}
+	if peer.Device == device {
+		return SyncReport{}, errors.New("sync: peer is this device")
+	}
+	return q.applySync(ctx, peer.Records)
+}
+
+// withSync serves the sync endpoint, POST /sync, in front of next. The
+// client posts its records and gets back those of this instance once
+// merged with them, which it applies in turn.
+func withSync(q *QuranService, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sync" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req syncRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSyncState)).Decode(&req); err != nil {
+			http.Error(w, "invalid sync request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := r.Context()
+		q.syncMu.Lock()
+		defer q.syncMu.Unlock()
+		report, err := q.applySync(ctx, req.Records)
+		if err != nil {
+			q.writeError(w, r, err)
+			return
+		}
+		device, err := q.syncDevice(ctx)
+		if err != nil {
+			q.writeError(w, r, err)
+			return
+		}
+		records, err := q.SyncState(ctx)
+		if err != nil {
+			q.writeError(w, r, err)
+			return
+		}
+		q.log(ctx).Info("synced", "peer", req.Device, "updated", report.Updated, "deleted", report.Deleted, "conflicts", report.Conflicts)
+		writeJSON(w, syncRequest{Device: device, Records: records})
+	})
+}
+
+func runSyncUserData(ctx context.Context, q *QuranService, cfg SyncConfig, args []string) error {
+	fs := flag.NewFlagSet("sync-userdata", flag.ContinueOnError)
+	key := fs.String("key", cfg.APIKey, "API key of the peer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	peer := cfg.Peer
+	if fs.NArg() == 1 {
+		peer = fs.Arg(0)
+	}
+	if peer == "" || fs.NArg() > 1 {
+		return errors.New("usage: sync-userdata [-key k] <peer url>")
+	}
+	report, err := q.Sync(ctx, peer, *key)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("updated %d, deleted %d, %d conflicts resolved\n", report.Updated, report.Deleted, report.Conflicts)
+	return nil
+}
@@ -0,0 +1,148 @@
+package quranapi
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// TajweedRule is a tajweed rule class of the Uthmani tajweed edition.
+type TajweedRule string
+
+const (
+	TajweedHamzatWasl          TajweedRule = "ham_wasl"
+	TajweedSilent              TajweedRule = "slnt"
+	TajweedLaamShamsiyah       TajweedRule = "laam_shamsiyah"
+	TajweedMaddNormal          TajweedRule = "madda_normal"
+	TajweedMaddPermissible     TajweedRule = "madda_permissible"
+	TajweedMaddNecessary       TajweedRule = "madda_necessary"
+	TajweedMaddObligatory      TajweedRule = "madda_obligatory"
+	TajweedQalqalah            TajweedRule = "qalaqah"
+	TajweedIkhfaShafawi        TajweedRule = "ikhafa_shafawi"
+	TajweedIkhfa               TajweedRule = "ikhafa"
+	TajweedIqlab               TajweedRule = "iqlab"
+	TajweedIdghamShafawi       TajweedRule = "idgham_shafawi"
+	TajweedIdghamGhunnah       TajweedRule = "idgham_ghunnah"
+	TajweedIdghamWithoutGhunna TajweedRule = "idgham_wo_ghunnah"
+	TajweedIdghamMutajanisayn  TajweedRule = "idgham_mutajanisayn"
+	TajweedIdghamMutaqaribayn  TajweedRule = "idgham_mutaqaribayn"
+	TajweedGhunnah             TajweedRule = "ghunnah"
+	// TajweedVerseEnd marks the verse number at the end of the text rather
+	// than a rule.
+	TajweedVerseEnd TajweedRule = "end"
+)
+
+var tajweedRules = []TajweedRule{
+	TajweedHamzatWasl, TajweedSilent, TajweedLaamShamsiyah,
+	TajweedMaddNormal, TajweedMaddPermissible, TajweedMaddNecessary, TajweedMaddObligatory,
+	TajweedQalqalah, TajweedIkhfaShafawi, TajweedIkhfa, TajweedIqlab,
+	TajweedIdghamShafawi, TajweedIdghamGhunnah, TajweedIdghamWithoutGhunna,
+	TajweedIdghamMutajanisayn, TajweedIdghamMutaqaribayn, TajweedGhunnah,
+	TajweedVerseEnd,
+}
+
+// Known reports whether r is one of the TajweedRule constants. Parsing
+// keeps unknown classes so that rules added upstream still reach renderers.
+func (r TajweedRule) Known() bool {
+	for _, known := range tajweedRules {
+		if r == known {
+			return true
+		}
+	}
+	return false
+}
+
+// TajweedSpan is a rule applied to the byte range [Start, End) of
+// TajweedText.Text.
+type TajweedSpan struct {
+	Rule  TajweedRule `json:"rule"`
+	Start int         `json:"start"`
+	End   int         `json:"end"`
+}
+
+// TajweedText is tajweed markup split into its plain text and the rule
+// spans over it, in order of their start.
+type TajweedText struct {
+	Text  string        `json:"text"`
+	Spans []TajweedSpan `json:"spans"`
+}
+
+// ErrInvalidTajweed is returned for markup that isn't well formed.
+var ErrInvalidTajweed = errors.New("invalid tajweed markup")
+
+var (
+	tajweedOpenRE  = regexp.MustCompile(`^<(tajweed|span)\s+class\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))\s*>`)
+	tajweedCloseRE = regexp.MustCompile(`^</(tajweed|span)\s*>`)
+)
+
+// ParseTajweed parses the markup of Verse.TextUthmaniTajweed, where each
+// rule is a <tajweed class="rule"> element and the verse number a <span
+// class="end"> element. Elements may nest; HTML entities in the text are
+// decoded.
+func ParseTajweed(markup string) (TajweedText, error) {
+	var (
+		text  strings.Builder
+		out   TajweedText
+		stack []struct {
+			tag  string
+			span int
+		}
+	)
+	fail := func(format string, args ...interface{}) (TajweedText, error) {
+		return TajweedText{}, fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalidTajweed}, args...)...)
+	}
+
+	for rest := markup; rest != ""; {
+		i := strings.IndexByte(rest, '<')
+		if i != 0 {
+			if i < 0 {
+				i = len(rest)
+			}
+			text.WriteString(html.UnescapeString(rest[:i]))
+			rest = rest[i:]
+			continue
+		}
+
+		if m := tajweedOpenRE.FindStringSubmatch(rest); m != nil {
+			class := m[2] + m[3] + m[4]
+			if class == "" {
+				return fail("empty class at byte %d", len(markup)-len(rest))
+			}
+			stack = append(stack, struct {
+				tag  string
+				span int
+			}{m[1], len(out.Spans)})
+			out.Spans = append(out.Spans, TajweedSpan{Rule: TajweedRule(class), Start: text.Len()})
+			rest = rest[len(m[0]):]
+			continue
+		}
+		if m := tajweedCloseRE.FindStringSubmatch(rest); m != nil {
+			if len(stack) == 0 {
+				return fail("unexpected </%s> at byte %d", m[1], len(markup)-len(rest))
+			}
+			open := stack[len(stack)-1]
+			if open.tag != m[1] {
+				return fail("</%s> closes <%s> at byte %d", m[1], open.tag, len(markup)-len(rest))
+			}
+			stack = stack[:len(stack)-1]
+			out.Spans[open.span].End = text.Len()
+			rest = rest[len(m[0]):]
+			continue
+		}
+		return fail("unexpected tag at byte %d", len(markup)-len(rest))
+	}
+	if len(stack) > 0 {
+		return fail("unclosed <%s>", stack[len(stack)-1].tag)
+	}
+
+	out.Text = text.String()
+	return out, nil
+}
+
+// Tajweed parses the verse's tajweed text, which is only present when
+// the chapter was fetched WithScript(ScriptUthmaniTajweed).
+func (v Verse) Tajweed() (TajweedText, error) {
+	return ParseTajweed(v.TextUthmaniTajweed)
+}
@@ -0,0 +1,157 @@
+package quranapi
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseTajweedRules(t *testing.T) {
+	tests := []struct {
+		rule   TajweedRule
+		markup string
+		text   string
+		span   string
+	}{
+		{TajweedHamzatWasl, `<tajweed class=ham_wasl>ٱ</tajweed>للَّهِ`, "ٱللَّهِ", "ٱ"},
+		{TajweedSilent, `قَالُوا<tajweed class=slnt>۟</tajweed>`, "قَالُوا۟", "۟"},
+		{TajweedLaamShamsiyah, `ٱ<tajweed class=laam_shamsiyah>ل</tajweed>رَّحْمَٰنِ`, "ٱلرَّحْمَٰنِ", "ل"},
+		{TajweedMaddNormal, `ٱلرَّحْمَ<tajweed class=madda_normal>ٰ</tajweed>نِ`, "ٱلرَّحْمَٰنِ", "ٰ"},
+		{TajweedMaddPermissible, `ٱلرَّحِي<tajweed class=madda_permissible>ـۧ</tajweed>مِ`, "ٱلرَّحِيـۧمِ", "ـۧ"},
+		{TajweedMaddNecessary, `ٱلضَّا<tajweed class=madda_necessary>ٓ</tajweed>لِّينَ`, "ٱلضَّآلِّينَ", "ٓ"},
+		{TajweedMaddObligatory, `جَا<tajweed class=madda_obligatory>ٓ</tajweed>ءَ`, "جَآءَ", "ٓ"},
+		{TajweedQalqalah, `أَحَ<tajweed class=qalaqah>د</tajweed>ٌ`, "أَحَدٌ", "د"},
+		{TajweedIkhfaShafawi, `هُ<tajweed class=ikhafa_shafawi>م</tajweed> بِ`, "هُم بِ", "م"},
+		{TajweedIkhfa, `مِ<tajweed class=ikhafa>ن</tajweed> قَبْلِ`, "مِن قَبْلِ", "ن"},
+		{TajweedIqlab, `مِ<tajweed class=iqlab>نۢ</tajweed> بَعْدِ`, "مِنۢ بَعْدِ", "نۢ"},
+		{TajweedIdghamShafawi, `لَهُ<tajweed class=idgham_shafawi>م</tajweed> مَّا`, "لَهُم مَّا", "م"},
+		{TajweedIdghamGhunnah, `مَ<tajweed class=idgham_ghunnah>ن</tajweed> يَقُولُ`, "مَن يَقُولُ", "ن"},
+		{TajweedIdghamWithoutGhunna, `مِّ<tajweed class=idgham_wo_ghunnah>ن</tajweed> رَّبِّهِمْ`, "مِّن رَّبِّهِمْ", "ن"},
+		{TajweedIdghamMutajanisayn, `قَ<tajweed class=idgham_mutajanisayn>د</tajweed> تَّبَيَّنَ`, "قَد تَّبَيَّنَ", "د"},
+		{TajweedIdghamMutaqaribayn, `أَلَمْ نَخْلُ<tajweed class=idgham_mutaqaribayn>ق</tajweed>كُّم`, "أَلَمْ نَخْلُقكُّم", "ق"},
+		{TajweedGhunnah, `إِ<tajweed class=ghunnah>نَّ</tajweed>`, "إِنَّ", "نَّ"},
+		{TajweedVerseEnd, `ٱلْعَٰلَمِينَ <span class=end>٢</span>`, "ٱلْعَٰلَمِينَ ٢", "٢"},
+	}
+
+	seen := make(map[TajweedRule]bool)
+	for _, tt := range tests {
+		t.Run(string(tt.rule), func(t *testing.T) {
+			got, err := ParseTajweed(tt.markup)
+			if err != nil {
+				t.Fatalf("ParseTajweed(%q): %v", tt.markup, err)
+			}
+			if got.Text != tt.text {
+				t.Errorf("text = %q, want %q", got.Text, tt.text)
+			}
+			if len(got.Spans) != 1 {
+				t.Fatalf("spans = %+v, want one", got.Spans)
+			}
+			span := got.Spans[0]
+			if span.Rule != tt.rule {
+				t.Errorf("rule = %q, want %q", span.Rule, tt.rule)
+			}
+			if s := got.Text[span.Start:span.End]; s != tt.span {
+				t.Errorf("span text = %q, want %q", s, tt.span)
+			}
+			if !span.Rule.Known() {
+				t.Errorf("%q is not Known", span.Rule)
+			}
+		})
+		seen[tt.rule] = true
+	}
+
+	for _, rule := range tajweedRules {
+		if !seen[rule] {
+			t.Errorf("no test for rule %q", rule)
+		}
+	}
+}
+
+func TestParseTajweedMarkup(t *testing.T) {
+	tests := []struct {
+		name   string
+		markup string
+		want   TajweedText
+	}{
+		{
+			name:   "plain text",
+			markup: "بِسْمِ",
+			want:   TajweedText{Text: "بِسْمِ"},
+		},
+		{
+			name:   "empty",
+			markup: "",
+			want:   TajweedText{},
+		},
+		{
+			name:   "quoted classes",
+			markup: `<tajweed class="ghunnah">a</tajweed><tajweed class='ikhafa'>b</tajweed>`,
+			want: TajweedText{Text: "ab", Spans: []TajweedSpan{
+				{Rule: TajweedGhunnah, Start: 0, End: 1},
+				{Rule: TajweedIkhfa, Start: 1, End: 2},
+			}},
+		},
+		{
+			name:   "nested",
+			markup: `x<tajweed class=ghunnah>a<tajweed class=madda_normal>b</tajweed></tajweed>`,
+			want: TajweedText{Text: "xab", Spans: []TajweedSpan{
+				{Rule: TajweedGhunnah, Start: 1, End: 3},
+				{Rule: TajweedMaddNormal, Start: 2, End: 3},
+			}},
+		},
+		{
+			name:   "entities",
+			markup: `a&amp;<tajweed class=slnt>&lt;</tajweed>`,
+			want: TajweedText{Text: "a&<", Spans: []TajweedSpan{
+				{Rule: TajweedSilent, Start: 2, End: 3},
+			}},
+		},
+		{
+			name:   "unknown rule kept",
+			markup: `<tajweed class=new_rule>a</tajweed>`,
+			want: TajweedText{Text: "a", Spans: []TajweedSpan{
+				{Rule: "new_rule", Start: 0, End: 1},
+			}},
+		},
+		{
+			name:   "empty element",
+			markup: `a<tajweed class=slnt></tajweed>b`,
+			want: TajweedText{Text: "ab", Spans: []TajweedSpan{
+				{Rule: TajweedSilent, Start: 1, End: 1},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTajweed(tt.markup)
+			if err != nil {
+				t.Fatalf("ParseTajweed(%q): %v", tt.markup, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTajweed(%q) = %+v, want %+v", tt.markup, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTajweedInvalid(t *testing.T) {
+	for _, markup := range []string{
+		`<tajweed class=ghunnah>a`,
+		`a</tajweed>`,
+		`<tajweed class=ghunnah>a</span>`,
+		`<b>a</b>`,
+		`<tajweed>a</tajweed>`,
+		`<tajweed class="">a</tajweed>`,
+		`a < b`,
+	} {
+		if _, err := ParseTajweed(markup); !errors.Is(err, ErrInvalidTajweed) {
+			t.Errorf("ParseTajweed(%q) = %v, want ErrInvalidTajweed", markup, err)
+		}
+	}
+}
+
+func TestTajweedRuleKnown(t *testing.T) {
+	if TajweedRule("new_rule").Known() {
+		t.Error("unknown rule reported as known")
+	}
+}
@@ -0,0 +1,178 @@
+// Package tokenizer splits the Arabic text of a verse into the words of
+// the quran.com word model, so that a substring of the text, such as a
+// search match or a selection, can be mapped to quranapi.Word positions.
+//
+// Words are separated by spaces and numbered from 1 as Word.Position is.
+// A verse-end sign, the ornate ۝ or the verse number in Arabic-Indic
+// digits, is a word of its own, as the word model's "end" words are.
+// Pause marks (ۖ ۗ ۘ ۙ ۚ ۛ ۜ) and the sajdah sign ۩ belong to the word
+// before them, and the rub el hizb sign ۞ to the word after it, whether
+// written apart or joined to the word; they are tokens of their own with
+// that word's position.
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind is the kind of a Token. KindWord and KindEnd match the char_type
+// of quranapi.Word.
+type Kind string
+
+const (
+	KindWord  Kind = "word"
+	KindEnd   Kind = "end"
+	KindPause Kind = "pause"
+	KindSign  Kind = "sign"
+)
+
+// Token is a word or sign of a verse.
+type Token struct {
+	Kind Kind   `json:"kind"`
+	Text string `json:"text"`
+	// Position is the Word.Position of the word, or of the word a pause
+	// mark or sign belongs to. It is zero for signs with no word to belong
+	// to, such as a pause mark opening the text.
+	Position int `json:"position"`
+	// Start and End are the byte range of Text in the tokenized text.
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Tokens are the tokens of a verse in text order.
+type Tokens []Token
+
+// IsPauseMark reports whether r is one of the small high pause marks.
+func IsPauseMark(r rune) bool {
+	return r >= 'ۖ' && r <= 'ۜ'
+}
+
+func isSajdah(r rune) bool { return r == '۩' }
+
+func isRubElHizb(r rune) bool { return r == '۞' }
+
+// isEndRune reports whether r may appear in a verse-end sign: the ayah
+// sign, ornate parentheses and digits.
+func isEndRune(r rune) bool {
+	return r == '۝' || r == '﴾' || r == '﴿' || unicode.IsDigit(r)
+}
+
+// Tokenize splits text, a verse in any of the Arabic editions, into its
+// tokens.
+func Tokenize(text string) Tokens {
+	var (
+		out      Tokens
+		position int
+		pending  []int // rub el hizb signs waiting for the next word
+	)
+	word := func(kind Kind, start, end int) {
+		position++
+		for _, i := range pending {
+			out[i].Position = position
+		}
+		pending = pending[:0]
+		out = append(out, Token{Kind: kind, Text: text[start:end], Position: position, Start: start, End: end})
+	}
+	sign := func(r rune, start int) {
+		t := Token{Kind: KindPause, Text: string(r), Position: position, Start: start, End: start + utf8.RuneLen(r)}
+		switch {
+		case isRubElHizb(r):
+			t.Kind, t.Position = KindSign, 0
+			pending = append(pending, len(out))
+		case isSajdah(r):
+			t.Kind = KindSign
+		}
+		out = append(out, t)
+	}
+
+	for start := 0; start < len(text); {
+		r, size := utf8.DecodeRuneInString(text[start:])
+		if unicode.IsSpace(r) {
+			start += size
+			continue
+		}
+		end := len(text)
+		if n := strings.IndexFunc(text[start:], unicode.IsSpace); n >= 0 {
+			end = start + n
+		}
+		field := text[start:end]
+
+		if strings.IndexFunc(field, func(r rune) bool { return !isEndRune(r) }) < 0 {
+			word(KindEnd, start, end)
+			start = end
+			continue
+		}
+		// signs before, within or after the letters of the field
+		i := start
+		for i < end {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			if !isRubElHizb(r) && !IsPauseMark(r) && !isSajdah(r) {
+				break
+			}
+			sign(r, i)
+			i += size
+		}
+		if i == end {
+			start = end
+			continue
+		}
+		j := i
+		for j < end {
+			r, size := utf8.DecodeRuneInString(text[j:])
+			if IsPauseMark(r) || isSajdah(r) || isRubElHizb(r) {
+				break
+			}
+			j += size
+		}
+		word(KindWord, i, j)
+		for j < end {
+			r, size := utf8.DecodeRuneInString(text[j:])
+			if IsPauseMark(r) || isSajdah(r) || isRubElHizb(r) {
+				sign(r, j)
+			}
+			j += size
+		}
+		start = end
+	}
+	return out
+}
+
+// Words returns the words and verse-end signs of ts, which are a token per
+// Word of the verse.
+func (ts Tokens) Words() Tokens {
+	var out Tokens
+	for _, t := range ts {
+		if t.Kind == KindWord || t.Kind == KindEnd {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// PositionAt returns the position of the word covering the byte offset of
+// the tokenized text, or zero between words.
+func (ts Tokens) PositionAt(offset int) int {
+	for _, t := range ts {
+		if offset >= t.Start && offset < t.End {
+			return t.Position
+		}
+	}
+	return 0
+}
+
+// Positions returns the positions of the words overlapping the byte range
+// [start, end) of the tokenized text, in order and without repeats.
+func (ts Tokens) Positions(start, end int) []int {
+	var out []int
+	for _, t := range ts {
+		if t.Position == 0 || t.End <= start || t.Start >= end {
+			continue
+		}
+		if n := len(out); n == 0 || out[n-1] != t.Position {
+			out = append(out, t.Position)
+		}
+	}
+	return out
+}
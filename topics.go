@@ -0,0 +1,284 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	bucketTopics     = "topics"
+	bucketUserTopics = "user_topics"
+)
+
+// seedTopics is a small starter index of common themes, loaded on first
+// use when no topic dataset was imported.
+//
+//go:embed topics.json
+var seedTopics []byte
+
+// ReadTopics reads a topic dataset: a JSON object mapping each topic to
+// the keys of the verses tagged with it.
+func ReadTopics(r io.Reader) (map[string][]string, error) {
+	var topics map[string][]string
+	if err := json.NewDecoder(r).Decode(&topics); err != nil {
+		return nil, err
+	}
+	for topic, keys := range topics {
+		for _, key := range keys {
+			if _, _, err := ValidateVerseKey(key); err != nil {
+				return nil, fmt.Errorf("topic %q: %w", topic, err)
+			}
+		}
+	}
+	return topics, nil
+}
+
+func normalizeTopic(topic string) string {
+	return strings.ToLower(strings.TrimSpace(topic))
+}
+
+// ImportTopics replaces the topic dataset with the one read from r. Custom
+// tags added with TagVerses are kept.
+func (q *QuranService) ImportTopics(ctx context.Context, r io.Reader) error {
+	topics, err := ReadTopics(r)
+	if err != nil {
+		return err
+	}
+
+	var stale []string
+	err = q.store.Iterate(ctx, bucketTopics, func(key string, value []byte) error {
+		stale = append(stale, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range stale {
+		if err := q.deleteValue(ctx, bucketTopics, key); err != nil {
+			return err
+		}
+	}
+
+	for topic, keys := range topics {
+		if err := q.putValue(ctx, bucketTopics, normalizeTopic(topic), sortedKeys(keys)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedTopicsOnce loads the embedded starter index when no topics are
+// stored.
+func (q *QuranService) seedTopicsOnce(ctx context.Context) error {
+	empty := true
+	err := q.store.Iterate(ctx, bucketTopics, func(key string, value []byte) error {
+		empty = false
+		return errStopIteration
+	})
+	if err != nil && !errors.Is(err, errStopIteration) {
+		return err
+	}
+	if !empty {
+		return nil
+	}
+	return q.ImportTopics(ctx, bytes.NewReader(seedTopics))
+}
+
+// errStopIteration ends a Store.Iterate early.
+var errStopIteration = errors.New("stop iteration")
+
+func sortedKeys(keys []string) []string {
+	seen := make(map[string]bool, len(keys))
+	out := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, key)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return VerseKey(out[i]).Compare(VerseKey(out[j])) < 0
+	})
+	return out
+}
+
+func (q *QuranService) topicKeys(ctx context.Context, namespace, topic string) ([]string, error) {
+	var keys []string
+	err := q.getValue(ctx, namespace, topic, &keys)
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, nil
+	}
+	return keys, err
+}
+
+// Topics lists every topic, from the dataset and custom tags.
+func (q *QuranService) Topics(ctx context.Context) ([]string, error) {
+	if err := q.seedTopicsOnce(ctx); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, namespace := range []string{bucketTopics, bucketUserTopics} {
+		err := q.iterate(ctx, namespace, func(key string, value []byte) error {
+			seen[key] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	out := make([]string, 0, len(seen))
+	for topic := range seen {
+		out = append(out, topic)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// VersesByTopic returns the keys of the verses tagged with topic, in
+// mushaf order.
+func (q *QuranService) VersesByTopic(ctx context.Context, topic string) ([]string, error) {
+	if err := q.seedTopicsOnce(ctx); err != nil {
+		return nil, err
+	}
+	topic = normalizeTopic(topic)
+	keys, err := q.topicKeys(ctx, bucketTopics, topic)
+	if err != nil {
+		return nil, err
+	}
+	custom, err := q.topicKeys(ctx, bucketUserTopics, topic)
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(append(keys, custom...)), nil
+}
+
+// TopicsForVerse returns the topics the verse with the given key is tagged
+// with.
+func (q *QuranService) TopicsForVerse(ctx context.Context, key string) ([]string, error) {
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return nil, err
+	}
+	key = verseKey(chapter, verse)
+	if err := q.seedTopicsOnce(ctx); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, namespace := range []string{bucketTopics, bucketUserTopics} {
+		err := q.iterate(ctx, namespace, func(topic string, value []byte) error {
+			var keys []string
+			if err := valueDecode(value, &keys); err != nil {
+				return err
+			}
+			for _, k := range keys {
+				if k == key {
+					seen[topic] = true
+					break
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	out := make([]string, 0, len(seen))
+	for topic := range seen {
+		out = append(out, topic)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// TagVerses adds a custom topic tag to the verses. Custom tags are user
+// data: they are backed up and survive ImportTopics.
+func (q *QuranService) TagVerses(ctx context.Context, topic string, keys ...string) error {
+	topic = normalizeTopic(topic)
+	if topic == "" {
+		return errors.New("empty topic")
+	}
+	existing, err := q.topicKeys(ctx, bucketUserTopics, topic)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		chapter, verse, err := ValidateVerseKey(key)
+		if err != nil {
+			return err
+		}
+		existing = append(existing, verseKey(chapter, verse))
+	}
+	return q.putValue(ctx, bucketUserTopics, topic, sortedKeys(existing))
+}
+
+// UntagVerses removes a custom topic tag from the verses.
+func (q *QuranService) UntagVerses(ctx context.Context, topic string, keys ...string) error {
+	topic = normalizeTopic(topic)
+	existing, err := q.topicKeys(ctx, bucketUserTopics, topic)
+	if err != nil {
+		return err
+	}
+	remove := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		remove[key] = true
+	}
+	kept := existing[:0]
+	for _, key := range existing {
+		if !remove[key] {
+			kept = append(kept, key)
+		}
+	}
+	if len(kept) == 0 {
+		return q.softDelete(ctx, bucketUserTopics, topic)
+	}
+	return q.putValue(ctx, bucketUserTopics, topic, kept)
+}
+
+const topicsUsage = "usage: topics list | verses <topic> | verse <key> | tag <topic> <key>... | untag <topic> <key>... | import <file>"
+
+func runTopics(ctx context.Context, q *QuranService, args []string) error {
+	if len(args) == 0 {
+		return errors.New(topicsUsage)
+	}
+
+	printAll := func(lines []string, err error) error {
+		if err != nil {
+			return err
+		}
+		for _, l := range lines {
+			fmt.Println(l)
+		}
+		return nil
+	}
+
+	switch {
+	case args[0] == "list":
+		return printAll(q.Topics(ctx))
+	case args[0] == "verses" && len(args) == 2:
+		return printAll(q.VersesByTopic(ctx, args[1]))
+	case args[0] == "verse" && len(args) == 2:
+		return printAll(q.TopicsForVerse(ctx, args[1]))
+	case args[0] == "tag" && len(args) > 2:
+		return q.TagVerses(ctx, args[1], args[2:]...)
+	case args[0] == "untag" && len(args) > 2:
+		return q.UntagVerses(ctx, args[1], args[2:]...)
+	case args[0] == "import" && len(args) == 2:
+		f, err := os.Open(args[1])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return q.ImportTopics(ctx, f)
+	default:
+		return errors.New(topicsUsage)
+	}
+}
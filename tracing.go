@@ -0,0 +1,39 @@
+package quranapi
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/alilmtech/quranapi"
+
+// WithTracerProvider sets the provider spans are created with. It defaults
+// to the global provider registered with otel.SetTracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(q *QuranService) {
+		q.tracer = tp.Tracer(tracerName)
+	}
+}
+
+func (q *QuranService) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return q.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func storeAttrs(namespace, key string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("store.namespace", namespace),
+		attribute.String("store.key", key),
+	}
+}
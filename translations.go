@@ -0,0 +1,184 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TranslationSyncReport counts what SyncTranslations changed. Failed maps
+// the chapters whose translations could not be fetched or stored to their
+// error.
+type TranslationSyncReport struct {
+	Chapters int // chapters with a changed translation
+	Changed  int // verse translations changed or added
+	Failed   map[int]error
+}
+
+// SyncTranslations refetches the given translation resources, or the
+// service's when none are given, for every stored chapter and updates the
+// verses whose text changed. Only the translations are updated; the
+// Arabic, words and audio of the stored verses are kept. Resources the
+// chapters were stored without are added to them.
+func (q *QuranService) SyncTranslations(ctx context.Context, resourceIDs []int) (TranslationSyncReport, error) {
+	report := TranslationSyncReport{Failed: make(map[int]error)}
+	if len(resourceIDs) == 0 {
+		resourceIDs = q.translations
+	}
+	if len(resourceIDs) == 0 {
+		return report, errors.New("no translations to sync")
+	}
+
+	stored, err := q.storedChapters(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, id := range stored {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		changed, err := q.syncChapterTranslations(ctx, id, resourceIDs)
+		if err != nil {
+			q.log(ctx).Warn("sync translations", "chapter", id, "err", err)
+			report.Failed[id] = err
+			continue
+		}
+		if changed > 0 {
+			report.Chapters++
+			report.Changed += changed
+		}
+	}
+	return report, nil
+}
+
+// storedChapters returns the ids of the chapters in the store, in order.
+func (q *QuranService) storedChapters(ctx context.Context) ([]int, error) {
+	var ids []int
+	err := q.store.Iterate(ctx, bucketChecksums, func(key string, _ []byte) error {
+		if id, err := strconv.Atoi(key); err == nil {
+			ids = append(ids, id)
+		}
+		return nil
+	})
+	sort.Ints(ids)
+	return ids, err
+}
+
+func (q *QuranService) syncChapterTranslations(ctx context.Context, id int, resourceIDs []int) (int, error) {
+	chapter, err := q.getChapterDB(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, resourceID := range resourceIDs {
+		edition, err := q.fetchTranslation(ctx, resourceID, id)
+		if err != nil {
+			return 0, err
+		}
+		for i := range chapter.Verses {
+			v := &chapter.Verses[i]
+			text, ok := edition.texts[v.VerseNumber]
+			if ok && setTranslation(v, resourceID, edition.name, text) {
+				changed++
+			}
+		}
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+
+	if err := q.setChapterDB(ctx, chapter); err != nil {
+		return 0, err
+	}
+	q.uncacheChapter(id)
+	return changed, nil
+}
+
+// setTranslation sets the text of resource resourceID on v, reporting
+// whether it changed.
+func setTranslation(v *Verse, resourceID int, name, text string) bool {
+	for i := range v.Translations {
+		tr := &v.Translations[i]
+		if tr.ResourceID != resourceID {
+			continue
+		}
+		if tr.Text == text {
+			return false
+		}
+		tr.Text = text
+		return true
+	}
+	v.Translations = append(v.Translations, Translation{
+		ResourceID:   resourceID,
+		ResourceName: name,
+		Text:         text,
+	})
+	return true
+}
+
+type translationEdition struct {
+	name  string
+	texts map[int]string // by verse number
+}
+
+// fetchTranslation fetches the text of translation resourceID for a
+// chapter, with the verses it translates, which the v3 API has no way to
+// leave out.
+func (q *QuranService) fetchTranslation(ctx context.Context, resourceID, chapter int) (translationEdition, error) {
+	path := fmt.Sprintf("/chapters/%d/verses", chapter)
+	verses, err := paginate(ctx, q.paging, versesPerPage, chapterVerseCounts[chapter-1],
+		func(v Verse) int { return v.VerseNumber },
+		func(ctx context.Context, params []queryParam) ([]Verse, error) {
+			var resp struct {
+				Verses []Verse `json:"verses"`
+			}
+			req := q.httpClient.Get(path).QueryParam("translations", strconv.Itoa(resourceID))
+			for _, p := range params {
+				req = req.QueryParam(p.name, p.value)
+			}
+			ctx, span := q.startSpan(ctx, "GET "+path, attribute.Int("translation.id", resourceID))
+			err := q.fetch(ctx, path, req, &resp)
+			endSpan(span, err)
+			return resp.Verses, err
+		})
+	if err != nil {
+		return translationEdition{}, err
+	}
+
+	edition := translationEdition{texts: make(map[int]string, len(verses))}
+	for _, v := range verses {
+		for _, tr := range v.Translations {
+			if tr.ResourceID != resourceID {
+				continue
+			}
+			edition.name = tr.ResourceName
+			edition.texts[v.VerseNumber] = tr.Text
+		}
+	}
+	return edition, nil
+}
+
+func runSyncTranslations(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("sync-translations", flag.ContinueOnError)
+	ids := fs.String("t", "", "comma separated translation resource ids (default: the configured translations)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	resourceIDs, err := parseInts(*ids)
+	if err != nil {
+		return err
+	}
+
+	report, err := q.SyncTranslations(ctx, resourceIDs)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("updated %d translations in %d chapters, %d chapters failed\n", report.Changed, report.Chapters, len(report.Failed))
+	return nil
+}
@@ -0,0 +1,204 @@
+package quranapi
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WithTransliteration fills in Verse.Transliteration and any missing word
+// transliterations. Verses use the upstream word by word transliteration
+// when every word has one, and Transliterate otherwise.
+func WithTransliteration() Option {
+	return func(q *QuranService) {
+		q.transliteration = true
+	}
+}
+
+func (q *QuranService) transliterate(chapter *Chapter) {
+	if !q.transliteration {
+		return
+	}
+	for i := range chapter.Verses {
+		v := &chapter.Verses[i]
+		if v.Transliteration != "" {
+			continue
+		}
+
+		var words []string
+		upstream := true
+		for j := range v.Words {
+			w := &v.Words[j]
+			if w.CharType == "end" {
+				continue
+			}
+			if w.Transliteration.Text == "" {
+				upstream = false
+				w.Transliteration.Text = Transliterate(w.TextMadani)
+			}
+			words = append(words, w.Transliteration.Text)
+		}
+		if upstream && len(words) > 0 {
+			v.Transliteration = strings.Join(words, " ")
+		} else {
+			v.Transliteration = Transliterate(v.TextMadani)
+		}
+	}
+}
+
+var latinConsonants = map[rune]string{
+	'ء': "'", 'أ': "'", 'إ': "'", 'ؤ': "'", 'ئ': "'",
+	'ب': "b", 'ت': "t", 'ث': "th", 'ج': "j", 'ح': "ḥ", 'خ': "kh",
+	'د': "d", 'ذ': "dh", 'ر': "r", 'ز': "z", 'س': "s", 'ش': "sh",
+	'ص': "ṣ", 'ض': "ḍ", 'ط': "ṭ", 'ظ': "ẓ", 'ع': "ʿ", 'غ': "gh",
+	'ف': "f", 'ق': "q", 'ك': "k", 'ل': "l", 'م': "m", 'ن': "n",
+	'ه': "h", 'و': "w", 'ي': "y",
+}
+
+const (
+	fatha       = 'َ'
+	damma       = 'ُ'
+	kasra       = 'ِ'
+	fathatan    = 'ً'
+	dammatan    = 'ٌ'
+	kasratan    = 'ٍ'
+	shadda      = 'ّ'
+	sukun       = 'ْ'
+	daggerAlef  = 'ٰ'
+	alefWasla   = 'ٱ'
+	alefMaqsura = 'ى'
+	taMarbuta   = 'ة'
+)
+
+var latinVowels = map[rune]string{
+	fatha: "a", damma: "u", kasra: "i",
+	fathatan: "an", dammatan: "un", kasratan: "in",
+}
+
+// Transliterate romanizes vocalized Arabic text by rule, word by word. It
+// spells long vowels, doubled consonants and assimilated sun letters, but
+// it is an approximation for readers rather than a scholarly romanization:
+// elision across words, pause forms and the pronunciation of Quranic signs
+// are not modelled.
+func Transliterate(arabic string) string {
+	var out []string
+	for _, word := range strings.Fields(arabic) {
+		if w := transliterateWord([]rune(word)); w != "" {
+			out = append(out, w)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func transliterateWord(rs []rune) string {
+	var b strings.Builder
+	// vowel is the short vowel last written, which a following alef, waw
+	// or ya may lengthen.
+	var vowel rune
+	next := func(i int) rune {
+		if i+1 < len(rs) {
+			return rs[i+1]
+		}
+		return 0
+	}
+	for i, r := range rs {
+		switch {
+		case r == alefWasla:
+			// elided after a vowel in recitation, but written so that the
+			// article stays recognizable
+			if i == 0 {
+				b.WriteString("a")
+			}
+			vowel = 0
+		case r == 'ا' || r == alefMaqsura:
+			if vowel == fatha {
+				lengthen(&b, "a", "ā")
+			}
+			vowel = 0
+		case r == 'آ':
+			b.WriteString("'ā")
+			vowel = 0
+		case (r == 'و' && vowel == damma || r == 'ي' && vowel == kasra) && !isHaraka(next(i)):
+			lengthen(&b, map[rune]string{damma: "u", kasra: "i"}[vowel], map[rune]string{damma: "ū", kasra: "ī"}[vowel])
+			vowel = 0
+		case r == taMarbuta:
+			if isHaraka(next(i)) && next(i) != sukun {
+				b.WriteString("t")
+			} else {
+				b.WriteString("h")
+			}
+			vowel = 0
+		case r == 'ل' && !isHaraka(next(i)) && hasShadda(rs, i+1):
+			// the article's lam assimilates into a doubled sun letter
+			vowel = 0
+		case latinConsonants[r] != "":
+			if i == 0 && latinConsonants[r] == "'" {
+				vowel = 0 // a word initial glottal stop is not written
+				continue
+			}
+			b.WriteString(latinConsonants[r])
+			vowel = 0
+		case r == shadda:
+			doubleLast(&b, rs, i)
+		case r == daggerAlef:
+			switch {
+			case vowel == fatha:
+				lengthen(&b, "a", "ā")
+			case i == 0 || rs[i-1] != alefMaqsura:
+				b.WriteString("ā")
+			}
+			vowel = 0
+		case latinVowels[r] != "":
+			b.WriteString(latinVowels[r])
+			vowel = r
+		case r == sukun, unicode.Is(unicode.Mn, r), r == 'ـ':
+			// silent marks and Quranic annotation signs
+		case unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			// verse numbers and ornaments
+		}
+	}
+	return b.String()
+}
+
+func isHaraka(r rune) bool {
+	return r >= fathatan && r <= sukun
+}
+
+// hasShadda reports whether the letter at i carries a shadda.
+func hasShadda(rs []rune, i int) bool {
+	for i++; i < len(rs) && unicode.Is(unicode.Mn, rs[i]); i++ {
+		if rs[i] == shadda {
+			return true
+		}
+	}
+	return false
+}
+
+// lengthen replaces the trailing short vowel with its long form.
+func lengthen(b *strings.Builder, short, long string) {
+	s := strings.TrimSuffix(b.String(), short)
+	b.Reset()
+	b.WriteString(s + long)
+}
+
+// doubleLast repeats the consonant written for the letter carrying the
+// shadda at i, before its vowel when that was encoded first. A shadda on a
+// word's first letter marks assimilation of the previous word's ending,
+// which is not written.
+func doubleLast(b *strings.Builder, rs []rune, i int) {
+	vowel := ""
+	for j := i - 1; j >= 0; j-- {
+		if v := latinVowels[rs[j]]; v != "" {
+			vowel = v
+			continue
+		}
+		if c := latinConsonants[rs[j]]; c != "" && j > 0 {
+			s := strings.TrimSuffix(b.String(), vowel)
+			b.Reset()
+			b.WriteString(s + c + vowel)
+			return
+		}
+		if !unicode.Is(unicode.Mn, rs[j]) {
+			return
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package quranapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const defaultHTTPTimeout = 10 * time.Second
+
+// TransportConfig configures the HTTP client the CLI and server sync with.
+// Without a proxy, the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables apply.
+type TransportConfig struct {
+	// Proxy is an http, https or socks5 proxy URL; socks5h is accepted
+	// for Tor, whose proxy resolves host names itself.
+	Proxy     string        `yaml:"proxy" toml:"proxy"`
+	UserAgent string        `yaml:"user_agent" toml:"user_agent"`
+	CAFile    string        `yaml:"ca_file" toml:"ca_file"`
+	Timeout   time.Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// transportOptions hold the options applied to the Doer.
+type transportOptions struct {
+	proxy     string
+	tlsConfig *tls.Config
+	caFile    string
+	userAgent string
+	timeout   time.Duration
+}
+
+// WithProxy sends upstream requests through an http, https, socks5 or, for
+// Tor, socks5h proxy. Like WithTLSConfig and WithTimeout, it configures the
+// client NewQuranService creates when given a nil Doer.
+func WithProxy(proxyURL string) Option {
+	return func(q *QuranService) {
+		q.transport.proxy = proxyURL
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of upstream requests, for
+// example to present a client certificate.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(q *QuranService) {
+		q.transport.tlsConfig = cfg
+	}
+}
+
+// WithCAFile trusts the PEM certificates in path besides the system's, for
+// networks that intercept TLS.
+func WithCAFile(path string) Option {
+	return func(q *QuranService) {
+		q.transport.caFile = path
+	}
+}
+
+// WithTimeout bounds each upstream request; it defaults to 10s.
+func WithTimeout(d time.Duration) Option {
+	return func(q *QuranService) {
+		q.transport.timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent of upstream requests, with any Doer.
+func WithUserAgent(ua string) Option {
+	return func(q *QuranService) {
+		q.transport.userAgent = ua
+	}
+}
+
+// newDoer returns doer, or the client configured by the transport options
+// when it is nil, setting the User-Agent of its requests.
+func (q *QuranService) newDoer(doer Doer) (Doer, error) {
+	t := q.transport
+	if doer == nil {
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		if t.proxy != "" {
+			u, err := parseProxyURL(t.proxy)
+			if err != nil {
+				return nil, err
+			}
+			tr.Proxy = http.ProxyURL(u)
+		}
+		if t.tlsConfig != nil {
+			tr.TLSClientConfig = t.tlsConfig.Clone()
+		}
+		if t.caFile != "" {
+			pool, err := loadCAFile(t.caFile)
+			if err != nil {
+				return nil, err
+			}
+			if tr.TLSClientConfig == nil {
+				tr.TLSClientConfig = &tls.Config{}
+			}
+			tr.TLSClientConfig.RootCAs = pool
+		}
+		timeout := t.timeout
+		if timeout == 0 {
+			timeout = defaultHTTPTimeout
+		}
+		doer = &http.Client{Timeout: timeout, Transport: tr}
+	} else if t.proxy != "" || t.tlsConfig != nil || t.caFile != "" || t.timeout != 0 {
+		return nil, errors.New("proxy, TLS and timeout options configure the default client; pass a nil Doer to use them")
+	}
+
+	if t.userAgent != "" {
+		doer = userAgentDoer{doer: doer, userAgent: t.userAgent}
+	}
+	return doer, nil
+}
+
+func parseProxyURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", s, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	case "socks5h":
+		// net/http's socks5 already leaves resolving to the proxy
+		u.Scheme = "socks5"
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: want an http, https, socks5 or socks5h URL", s)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", s)
+	}
+	return u, nil
+}
+
+type userAgentDoer struct {
+	doer      Doer
+	userAgent string
+}
+
+func (d userAgentDoer) Do(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", d.userAgent)
+	return d.doer.Do(req)
+}
+
+func loadCAFile(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in %s", path)
+	}
+	return pool, nil
+}
+
+// options returns the service options for c.
+func (c TransportConfig) options() []Option {
+	var opts []Option
+	if c.Proxy != "" {
+		opts = append(opts, WithProxy(c.Proxy))
+	}
+	if c.UserAgent != "" {
+		opts = append(opts, WithUserAgent(c.UserAgent))
+	}
+	if c.CAFile != "" {
+		opts = append(opts, WithCAFile(c.CAFile))
+	}
+	if c.Timeout != 0 {
+		opts = append(opts, WithTimeout(c.Timeout))
+	}
+	return opts
+}
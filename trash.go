@@ -0,0 +1,215 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const bucketTrash = "trash"
+
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// ErrTrashEmpty is returned by UndoDelete when there is nothing to restore.
+var ErrTrashEmpty = errors.New("trash is empty")
+
+// TrashEntry is a soft-deleted user data record. ID identifies it for
+// Undelete.
+type TrashEntry struct {
+	ID        string
+	Namespace string
+	Key       string
+	Value     []byte
+	DeletedAt time.Time
+}
+
+// WithTrashRetention sets how long soft-deleted user data can be restored
+// before PurgeTrash removes it. The default is 30 days.
+func WithTrashRetention(d time.Duration) Option {
+	return func(q *QuranService) {
+		q.trashRetention = d
+	}
+}
+
+// trashID identifies a deletion of key, including when it was deleted so
+// that deleting a record recreated under the same key keeps both entries.
+func trashID(namespace, key string, deletedAt time.Time) string {
+	return namespace + "/" + key + "/" + strconv.FormatInt(deletedAt.UnixNano(), 10)
+}
+
+// softDelete moves a user data record to the trash instead of removing it,
+// and purges the entries of the trash past the retention window.
+func (q *QuranService) softDelete(ctx context.Context, namespace, key string) error {
+	value, err := q.store.Get(ctx, userNamespace(ctx, namespace), key)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	entry := TrashEntry{
+		ID:        trashID(namespace, key, now),
+		Namespace: namespace,
+		Key:       key,
+		Value:     value,
+		DeletedAt: now,
+	}
+	if err := q.putValue(ctx, bucketTrash, entry.ID, entry); err != nil {
+		return err
+	}
+	if err := q.deleteValue(ctx, namespace, key); err != nil {
+		return err
+	}
+	if _, err := q.PurgeTrash(ctx, entry.DeletedAt); err != nil {
+		q.log(ctx).Warn("purge trash", "err", err)
+	}
+	return nil
+}
+
+// Trash lists restorable entries, most recently deleted first.
+func (q *QuranService) Trash(ctx context.Context) ([]TrashEntry, error) {
+	cutoff := time.Now().Add(-q.trashRetention)
+
+	var out []TrashEntry
+	err := q.iterate(ctx, bucketTrash, func(key string, value []byte) error {
+		var entry TrashEntry
+		if err := valueDecode(value, &entry); err != nil {
+			return err
+		}
+		if entry.DeletedAt.After(cutoff) {
+			out = append(out, entry)
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].DeletedAt.After(out[j].DeletedAt)
+	})
+	return out, err
+}
+
+// Undelete restores the trash entry with the given ID, replacing any record
+// created under the same key since.
+func (q *QuranService) Undelete(ctx context.Context, id string) error {
+	var entry TrashEntry
+	if err := q.getValue(ctx, bucketTrash, id, &entry); err != nil {
+		return fmt.Errorf("trash entry %q: %w", id, err)
+	}
+	if time.Since(entry.DeletedAt) > q.trashRetention {
+		return fmt.Errorf("trash entry %q: %w", id, ErrKeyNotFound)
+	}
+
+	if err := q.putRaw(ctx, entry.Namespace, entry.Key, entry.Value); err != nil {
+		return err
+	}
+	return q.deleteValue(ctx, bucketTrash, id)
+}
+
+// UndoDelete restores the most recently deleted entry and returns it.
+func (q *QuranService) UndoDelete(ctx context.Context) (TrashEntry, error) {
+	entries, err := q.Trash(ctx)
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	if len(entries) == 0 {
+		return TrashEntry{}, ErrTrashEmpty
+	}
+	return entries[0], q.Undelete(ctx, entries[0].ID)
+}
+
+// PurgeTrash permanently removes entries deleted longer than the retention
+// window before now, returning how many were removed.
+func (q *QuranService) PurgeTrash(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.Add(-q.trashRetention)
+
+	var expired []string
+	err := q.iterate(ctx, bucketTrash, func(key string, value []byte) error {
+		var entry TrashEntry
+		if err := valueDecode(value, &entry); err != nil || entry.DeletedAt.Before(cutoff) {
+			expired = append(expired, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range expired {
+		if err := q.deleteValue(ctx, bucketTrash, key); err != nil {
+			return 0, err
+		}
+	}
+	return len(expired), nil
+}
+
+// RunTrashPurge calls PurgeTrash every interval until ctx is done, for the
+// trash written without a user and that of each of the DataUsers. It
+// returns at once for read-only services.
+func (q *QuranService) RunTrashPurge(ctx context.Context, interval time.Duration) {
+	if q.readOnly {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			purged := 0
+			err := q.forEachDataUser(ctx, func(ctx context.Context) error {
+				n, err := q.PurgeTrash(ctx, now)
+				purged += n
+				return err
+			})
+			if err != nil {
+				q.log(ctx).Error("purge trash", "err", err)
+			}
+			if purged > 0 {
+				q.log(ctx).Info("purged trash", "entries", purged)
+			}
+		}
+	}
+}
+
+func runTrash(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("trash", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch cmd := fs.Arg(0); cmd {
+	case "list", "":
+		entries, err := q.Trash(ctx)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			fmt.Printf("%s\tdeleted %s\n", e.ID, e.DeletedAt.Local().Format(time.DateTime))
+		}
+		return nil
+	case "undo":
+		entry, err := q.UndoDelete(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("restored %s\n", entry.ID)
+		return nil
+	case "restore":
+		if fs.NArg() < 2 {
+			return errors.New("usage: trash restore <id>")
+		}
+		return q.Undelete(ctx, fs.Arg(1))
+	case "purge":
+		n, err := q.PurgeTrash(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("purged %d entries\n", n)
+		return nil
+	default:
+		return fmt.Errorf("unknown trash command: %q", cmd)
+	}
+}
@@ -0,0 +1,242 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrNoTTS is returned for translations that have neither recorded audio
+// nor a TTS engine, set by WithTTS, to speak them.
+var ErrNoTTS = errors.New("no recorded translation audio and no text-to-speech engine")
+
+// ErrTranslationNotFound is returned for translations a verse is not
+// stored with.
+var ErrTranslationNotFound = errors.New("translation not found")
+
+// TTS is a text-to-speech engine, running locally or at a cloud provider.
+type TTS interface {
+	// Synthesize returns text, in language as upstream names it such as
+	// "english", spoken, and the file extension of its format such as
+	// "mp3" or "wav".
+	Synthesize(ctx context.Context, text, language string) (audio []byte, ext string, err error)
+}
+
+// WithTTS speaks translations with no recorded audio with tts in
+// TranslationAudio.
+func WithTTS(tts TTS) Option {
+	return func(q *QuranService) {
+		q.tts = tts
+	}
+}
+
+// TranslationAudio returns the path of the audio of a verse's translation
+// by resourceID. Recorded audio, placed in the audio directory as
+// translations/{resourceID}/{chapter}{verse}.mp3 like the verse audio, is
+// preferred; otherwise the translation is spoken by the TTS engine and the
+// audio kept as tts/{resourceID}/{chapter}{verse} for later calls.
+func (q *QuranService) TranslationAudio(ctx context.Context, key string, resourceID int) (string, error) {
+	if err := q.requireFeature(FeatureAudio); err != nil {
+		return "", err
+	}
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return "", err
+	}
+	if q.audioDir == "" {
+		return "", errors.New("translation audio: no audio directory set")
+	}
+	name := fmt.Sprintf("%03d%03d", chapter, verse)
+	resource := strconv.Itoa(resourceID)
+	for _, dir := range []string{"translations", "tts"} {
+		matches, err := filepath.Glob(filepath.Join(q.audioDir, dir, resource, name+".*"))
+		if err != nil {
+			return "", err
+		}
+		for _, m := range matches {
+			if !strings.HasPrefix(filepath.Base(m), ".tmp-") {
+				return m, nil
+			}
+		}
+	}
+	if q.tts == nil {
+		return "", fmt.Errorf("%w: %s translation %d", ErrNoTTS, key, resourceID)
+	}
+
+	v, err := q.GetVerse(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	var tr *Translation
+	for i := range v.Translations {
+		if v.Translations[i].ResourceID == resourceID {
+			tr = &v.Translations[i]
+		}
+	}
+	if tr == nil {
+		return "", fmt.Errorf("%w: %s translation %d", ErrTranslationNotFound, key, resourceID)
+	}
+
+	ctx, span := q.startSpan(ctx, "TTS")
+	// speak the text as read: without footnote markers, and with entities
+	// such as &#39; decoded rather than spelled out
+	text := strings.Join(strings.Fields(html.UnescapeString(stripTags(tr.Text))), " ")
+	audio, ext, err := q.tts.Synthesize(ctx, text, tr.LanguageName)
+	endSpan(span, err)
+	if err != nil {
+		return "", fmt.Errorf("speak %s translation %d: %w", key, resourceID, err)
+	}
+	dir := filepath.Join(q.audioDir, "tts", resource)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name+"."+strings.TrimPrefix(ext, "."))
+	return path, writeFileAtomic(path, audio)
+}
+
+// CommandTTS is a local engine, such as espeak-ng or piper, run once for
+// each text: it is given the text on stdin and writes audio to stdout.
+type CommandTTS struct {
+	// Command is the program and its arguments. An argument {voice} is
+	// replaced by the voice of the language.
+	Command []string
+	// Ext is the format of the audio written, wav unless set.
+	Ext string
+	// Voices maps upstream language names to voices of the engine. The
+	// language name is passed for languages missing from it.
+	Voices map[string]string
+}
+
+func (c CommandTTS) Synthesize(ctx context.Context, text, language string) ([]byte, string, error) {
+	if len(c.Command) == 0 {
+		return nil, "", errors.New("tts: no command")
+	}
+	voice := c.Voices[strings.ToLower(language)]
+	if voice == "" {
+		voice = strings.ToLower(language)
+	}
+	args := make([]string, len(c.Command)-1)
+	for i, a := range c.Command[1:] {
+		args[i] = strings.ReplaceAll(a, "{voice}", voice)
+	}
+	cmd := exec.CommandContext(ctx, c.Command[0], args...)
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("tts %s: %w: %s", c.Command[0], err, strings.TrimSpace(stderr.String()))
+	}
+	ext := c.Ext
+	if ext == "" {
+		ext = "wav"
+	}
+	return out, ext, nil
+}
+
+// DefaultGoogleTTSURL is the Google Cloud Text-to-Speech synthesize
+// endpoint.
+const DefaultGoogleTTSURL = "https://texttospeech.googleapis.com/v1/text:synthesize"
+
+// googleLanguageCodes maps upstream language names to BCP 47 codes for
+// GoogleTTS.
+var googleLanguageCodes = map[string]string{
+	"bengali":    "bn-IN",
+	"chinese":    "cmn-CN",
+	"dutch":      "nl-NL",
+	"english":    "en-US",
+	"french":     "fr-FR",
+	"german":     "de-DE",
+	"hindi":      "hi-IN",
+	"indonesian": "id-ID",
+	"italian":    "it-IT",
+	"japanese":   "ja-JP",
+	"korean":     "ko-KR",
+	"malay":      "ms-MY",
+	"portuguese": "pt-PT",
+	"russian":    "ru-RU",
+	"spanish":    "es-ES",
+	"swedish":    "sv-SE",
+	"tamil":      "ta-IN",
+	"turkish":    "tr-TR",
+	"urdu":       "ur-IN",
+}
+
+// GoogleTTS speaks with Google Cloud Text-to-Speech, as MP3.
+type GoogleTTS struct {
+	APIKey string
+	// URL defaults to DefaultGoogleTTSURL.
+	URL string
+	// Voices maps upstream language names to voice names such as
+	// "en-US-Wavenet-D"; Google picks a voice for the others.
+	Voices map[string]string
+	// Client defaults to http.DefaultClient.
+	Client Doer
+}
+
+func (g GoogleTTS) Synthesize(ctx context.Context, text, language string) ([]byte, string, error) {
+	language = strings.ToLower(language)
+	code := googleLanguageCodes[language]
+	voice := g.Voices[language]
+	// voice names start with their language code
+	if parts := strings.Split(voice, "-"); len(parts) > 2 {
+		code = parts[0] + "-" + parts[1]
+	}
+	if code == "" {
+		return nil, "", fmt.Errorf("tts: no voice for %s", language)
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"input":       map[string]string{"text": text},
+		"voice":       map[string]string{"languageCode": code, "name": voice},
+		"audioConfig": map[string]string{"audioEncoding": "MP3"},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	u := g.URL
+	if u == "" {
+		u = DefaultGoogleTTSURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Goog-Api-Key", g.APIKey)
+	client := g.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, "", fmt.Errorf("tts: status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	var out struct {
+		AudioContent string `json:"audioContent"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, "", fmt.Errorf("tts: %w", err)
+	}
+	audio, err := base64.StdEncoding.DecodeString(out.AudioContent)
+	if err != nil {
+		return nil, "", fmt.Errorf("tts: %w", err)
+	}
+	return audio, "mp3", nil
+}
@@ -0,0 +1,414 @@
+package quranapi
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// userDataNamespaces are the store namespaces holding data created by users
+// rather than fetched from upstream. They are backed up separately from the
+// text cache.
+var userDataNamespaces = []string{
+	bucketTrash,
+	bucketBookmarks,
+	bucketReading,
+	bucketHifz,
+	bucketAnnotations,
+	bucketPlans,
+	bucketUserTopics,
+	bucketCollections,
+	bucketNotes,
+	bucketReadingOccasions,
+	bucketKhatmahs,
+}
+
+var isUserDataNamespace = func() map[string]bool {
+	m := make(map[string]bool, len(userDataNamespaces))
+	for _, ns := range userDataNamespaces {
+		m[ns] = true
+	}
+	// sync state follows the data it describes, though it isn't backed up
+	m[bucketSync] = true
+	return m
+}()
+
+type userKey struct{}
+
+// ContextWithUser scopes the user data read and written with ctx, such as
+// bookmarks, notes and plans, to user, so that the users of one server
+// never see each other's data. Data written without a user is that of the
+// CLI and of servers without AuthConfig.PerUserData.
+func ContextWithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+func UserFromContext(ctx context.Context) (string, bool) {
+	user, ok := ctx.Value(userKey{}).(string)
+	return user, ok && user != ""
+}
+
+// userNamespace returns the namespace holding the data of namespace for
+// the user of ctx: namespace itself for caches and without a user.
+func userNamespace(ctx context.Context, namespace string) string {
+	if user, ok := UserFromContext(ctx); ok && isUserDataNamespace[namespace] {
+		return namespace + "@" + user
+	}
+	return namespace
+}
+
+// bucketDataUsers registers the users with data of their own, so that
+// backups and trash purges run without a user can visit the data of every
+// user.
+const bucketDataUsers = "data_users"
+
+// registerDataUser records that the user of ctx, if any, has data.
+func (q *QuranService) registerDataUser(ctx context.Context) error {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if _, seen := q.dataUsers.Load(user); seen {
+		return nil
+	}
+	if err := q.store.Put(ctx, bucketDataUsers, user, []byte(time.Now().UTC().Format(time.RFC3339))); err != nil {
+		return err
+	}
+	q.dataUsers.Store(user, true)
+	return nil
+}
+
+// DataUsers lists the users whose data is kept apart from the data written
+// without a user: those registered as they write, and the API keys and JWT
+// subjects in the usage records, which had data before users were
+// registered.
+func (q *QuranService) DataUsers(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, ns := range []string{bucketDataUsers, bucketAPIUsage} {
+		err := q.store.Iterate(ctx, ns, func(key string, _ []byte) error {
+			seen[key] = true
+			return ctx.Err()
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	users := make([]string, 0, len(seen))
+	for user := range seen {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	return users, nil
+}
+
+// forEachDataUser calls fn with ctx, and when ctx has no user, with a
+// context for each of the DataUsers too.
+func (q *QuranService) forEachDataUser(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	if _, ok := UserFromContext(ctx); ok {
+		return nil
+	}
+	users, err := q.DataUsers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if err := fn(ContextWithUser(ctx, user)); err != nil {
+			return fmt.Errorf("user %s: %w", user, err)
+		}
+	}
+	return nil
+}
+
+type UserDataBackup struct {
+	CreatedAt  time.Time                    `json:"created_at"`
+	Namespaces map[string]map[string][]byte `json:"namespaces"`
+	// Users holds the namespaces of each of the DataUsers, in backups
+	// taken without a user.
+	Users map[string]map[string]map[string][]byte `json:"users,omitempty"`
+}
+
+// BackupUserData writes a gzipped JSON snapshot of every user data namespace
+// to w: those of the user of ctx if any, otherwise those written without a
+// user and those of every user.
+func (q *QuranService) BackupUserData(ctx context.Context, w io.Writer) error {
+	namespaces, err := q.backupNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+	backup := UserDataBackup{CreatedAt: time.Now().UTC(), Namespaces: namespaces}
+	if _, ok := UserFromContext(ctx); !ok {
+		users, err := q.DataUsers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			namespaces, err := q.backupNamespaces(ContextWithUser(ctx, user))
+			if err != nil {
+				return fmt.Errorf("user %s: %w", user, err)
+			}
+			if backup.Users == nil {
+				backup.Users = make(map[string]map[string]map[string][]byte)
+			}
+			backup.Users[user] = namespaces
+		}
+	}
+
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(backup); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (q *QuranService) backupNamespaces(ctx context.Context) (map[string]map[string][]byte, error) {
+	out := make(map[string]map[string][]byte, len(userDataNamespaces))
+	for _, ns := range userDataNamespaces {
+		values := make(map[string][]byte)
+		err := q.iterate(ctx, ns, func(key string, value []byte) error {
+			values[key] = value
+			return ctx.Err()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("backup %s: %w", ns, err)
+		}
+		out[ns] = values
+	}
+	return out, nil
+}
+
+// RestoreUserData replaces the user data namespaces found in the backup with
+// their backed up contents, as the data of the user of ctx if any. Without
+// a user, the data of each user in the backup is restored as theirs too.
+// Backups naming any other namespace are rejected with ErrInvalidBackup.
+func (q *QuranService) RestoreUserData(ctx context.Context, r io.Reader) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	var backup UserDataBackup
+	if err := json.NewDecoder(zr).Decode(&backup); err != nil {
+		return err
+	}
+
+	// a backup holds only user data; anything else would overwrite the
+	// text cache or indexes
+	all := []map[string]map[string][]byte{backup.Namespaces}
+	for _, namespaces := range backup.Users {
+		all = append(all, namespaces)
+	}
+	for _, namespaces := range all {
+		for ns := range namespaces {
+			if !isUserDataNamespace[ns] {
+				return fmt.Errorf("%w: namespace %q is not user data", ErrInvalidBackup, ns)
+			}
+		}
+	}
+
+	if err := q.restoreNamespaces(ctx, backup.Namespaces); err != nil {
+		return err
+	}
+	if _, ok := UserFromContext(ctx); ok {
+		return nil
+	}
+	for user, namespaces := range backup.Users {
+		if err := q.restoreNamespaces(ContextWithUser(ctx, user), namespaces); err != nil {
+			return fmt.Errorf("user %s: %w", user, err)
+		}
+	}
+	return nil
+}
+
+func (q *QuranService) restoreNamespaces(ctx context.Context, namespaces map[string]map[string][]byte) error {
+	for ns, values := range namespaces {
+		var existing []string
+		err := q.iterate(ctx, ns, func(key string, _ []byte) error {
+			existing = append(existing, key)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range existing {
+			if err := q.deleteValue(ctx, ns, key); err != nil {
+				return err
+			}
+		}
+
+		for key, value := range values {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := q.putRaw(ctx, ns, key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type BackupConfig struct {
+	Dir      string        `yaml:"dir" toml:"dir"`
+	Interval time.Duration `yaml:"interval" toml:"interval"`
+	// Keep is the number of backups retained; older ones are removed.
+	Keep int `yaml:"keep" toml:"keep"`
+}
+
+const userDataBackupPrefix = "userdata-"
+
+// BackupUserDataToDir writes a timestamped backup into dir and removes all
+// but the newest keep backups, returning the new file's path.
+func (q *QuranService) BackupUserDataToDir(ctx context.Context, dir string, keep int) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := filepath.Join(dir, userDataBackupPrefix+time.Now().UTC().Format("20060102T150405Z")+".json.gz")
+	f, err := os.Create(name)
+	if err != nil {
+		return "", err
+	}
+	if err := q.BackupUserData(ctx, f); err != nil {
+		f.Close()
+		os.Remove(name)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	return name, rotateBackups(dir, keep)
+}
+
+// UserDataBackups lists the backups in dir, newest first.
+func UserDataBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), userDataBackupPrefix) && strings.HasSuffix(e.Name(), ".json.gz") {
+			out = append(out, filepath.Join(dir, e.Name()))
+		}
+	}
+	// timestamps in the names sort chronologically
+	sort.Sort(sort.Reverse(sort.StringSlice(out)))
+	return out, nil
+}
+
+func rotateBackups(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	backups, err := UserDataBackups(dir)
+	if err != nil {
+		return err
+	}
+	for len(backups) > keep {
+		if err := os.Remove(backups[len(backups)-1]); err != nil {
+			return err
+		}
+		backups = backups[:len(backups)-1]
+	}
+	return nil
+}
+
+// RunUserDataBackups backs up user data into cfg.Dir every cfg.Interval
+// until ctx is cancelled. Failed backups are logged and retried on the next
+// tick.
+func (q *QuranService) RunUserDataBackups(ctx context.Context, cfg BackupConfig) error {
+	if cfg.Dir == "" || cfg.Interval <= 0 {
+		return errors.New("user data backups need a directory and a positive interval")
+	}
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			name, err := q.BackupUserDataToDir(ctx, cfg.Dir, cfg.Keep)
+			if err != nil {
+				q.log(ctx).Error("user data backup", "dir", cfg.Dir, "err", err)
+				continue
+			}
+			q.log(ctx).Info("user data backup", "file", name)
+		}
+	}
+}
+
+func runUserData(ctx context.Context, q *QuranService, cfg BackupConfig, args []string) error {
+	fs := flag.NewFlagSet("userdata", flag.ContinueOnError)
+	dir := fs.String("dir", cfg.Dir, "backup directory")
+	keep := fs.Int("keep", cfg.Keep, "number of backups to keep")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch cmd := fs.Arg(0); cmd {
+	case "backup":
+		name, err := q.BackupUserDataToDir(ctx, *dir, *keep)
+		if err != nil {
+			return err
+		}
+		fmt.Println(name)
+		return nil
+	case "schedule":
+		return q.RunUserDataBackups(ctx, BackupConfig{Dir: *dir, Interval: cfg.Interval, Keep: *keep})
+	case "list":
+		backups, err := UserDataBackups(*dir)
+		if err != nil {
+			return err
+		}
+		for _, b := range backups {
+			fmt.Println(b)
+		}
+		return nil
+	case "export", "import":
+		return runUserDataArchive(ctx, q, cmd, fs.Args()[1:])
+	case "restore":
+		name := fs.Arg(1)
+		if name == "" {
+			backups, err := UserDataBackups(*dir)
+			if err != nil {
+				return err
+			}
+			if len(backups) == 0 {
+				return fmt.Errorf("no backups in %s", *dir)
+			}
+			name = backups[0]
+		}
+
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := q.RestoreUserData(ctx, f); err != nil {
+			return err
+		}
+		fmt.Printf("restored %s\n", name)
+		return nil
+	default:
+		return fmt.Errorf("unknown userdata command: %q", cmd)
+	}
+}
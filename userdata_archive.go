@@ -0,0 +1,312 @@
+package quranapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+// UserDataArchiveVersion is the version of the archives written by
+// ExportUserData.
+const UserDataArchiveVersion = 1
+
+// ErrInvalidUserDataArchive is returned for archives that aren't valid JSON
+// or have an unsupported version.
+var ErrInvalidUserDataArchive = errors.New("invalid user data archive")
+
+// UserDataArchive is the portable form of a user's data, for moving it to
+// another device or install. Unlike the backups of BackupUserData, which
+// keep the store's encoding, it is plain JSON that later versions and other
+// apps can read.
+type UserDataArchive struct {
+	Version     int              `json:"version"`
+	ExportedAt  time.Time        `json:"exported_at"`
+	Bookmarks   []Bookmark       `json:"bookmarks"`
+	LastRead    *ReadingPosition `json:"last_read,omitempty"`
+	Notes       []Note           `json:"notes"`
+	Annotations []Annotation     `json:"annotations"`
+	Hifz        []HifzItem       `json:"hifz"`
+	Plans       []Plan           `json:"plans"`
+	// Topics are the custom topic tags, mapping each to its verse keys.
+	Topics      map[string][]string `json:"topics"`
+	Collections []Collection        `json:"collections"`
+	Khatmahs    []Khatmah           `json:"khatmahs"`
+}
+
+// ExportUserData writes the data of the user of ctx, or the local user, to
+// w as a UserDataArchive.
+func (q *QuranService) ExportUserData(ctx context.Context, w io.Writer) error {
+	archive := UserDataArchive{
+		Version:    UserDataArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+		Topics:     make(map[string][]string),
+	}
+	var err error
+	if archive.Bookmarks, err = q.ListBookmarks(ctx, BookmarkFilter{}); err != nil {
+		return err
+	}
+	pos, err := q.GetLastRead(ctx)
+	switch {
+	case err == nil:
+		archive.LastRead = &pos
+	case !errors.Is(err, ErrKeyNotFound):
+		return err
+	}
+	if archive.Notes, err = q.ListNotes(ctx, NoteFilter{}); err != nil {
+		return err
+	}
+	if archive.Annotations, err = q.Annotations(ctx); err != nil {
+		return err
+	}
+	if archive.Hifz, err = q.HifzProgress(ctx); err != nil {
+		return err
+	}
+	if archive.Plans, err = q.Plans(ctx); err != nil {
+		return err
+	}
+	err = q.iterate(ctx, bucketUserTopics, func(topic string, value []byte) error {
+		var keys []string
+		if err := valueDecode(value, &keys); err != nil {
+			return err
+		}
+		archive.Topics[topic] = keys
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if archive.Khatmahs, err = q.Khatmahs(ctx); err != nil {
+		return err
+	}
+	collections, err := q.ListCollections(ctx)
+	if err != nil {
+		return err
+	}
+	for _, c := range collections {
+		if !c.Curated {
+			archive.Collections = append(archive.Collections, c)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(archive)
+}
+
+// importRecord stores v under key unless a record stored there already is
+// as recent, as reported by newer.
+func importRecord[T any](ctx context.Context, q *QuranService, report *ImportReport, namespace, key string, v T, newer func(old T) bool) error {
+	var old T
+	err := q.getValue(ctx, namespace, key, &old)
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+	case err != nil:
+		return err
+	case !newer(old):
+		report.Duplicates++
+		return nil
+	}
+	if err := q.putValue(ctx, namespace, key, v); err != nil {
+		return err
+	}
+	report.Imported++
+	return nil
+}
+
+// ImportUserData merges the archive written by ExportUserData read from r
+// into the data of the user of ctx. Records missing here are added and
+// those here are replaced only by more recent ones: a note edited later, a
+// memorized span reviewed since. Annotations and custom topics are merged.
+func (q *QuranService) ImportUserData(ctx context.Context, r io.Reader) (ImportReport, error) {
+	var archive UserDataArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return ImportReport{}, fmt.Errorf("%w: %v", ErrInvalidUserDataArchive, err)
+	}
+	if archive.Version != UserDataArchiveVersion {
+		return ImportReport{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidUserDataArchive, archive.Version)
+	}
+
+	var report ImportReport
+	skip := func(kind string, err error) {
+		report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", kind, err))
+	}
+	for _, b := range archive.Bookmarks {
+		if err := b.VerseKey.Validate(); err != nil {
+			skip("bookmark", err)
+			continue
+		}
+		err := importRecord(ctx, q, &report, bucketBookmarks, b.VerseKey.String(), b, func(old Bookmark) bool {
+			return b.CreatedAt.After(old.CreatedAt)
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	if pos := archive.LastRead; pos != nil {
+		if err := pos.VerseKey.Validate(); err != nil {
+			skip("last read", err)
+		} else {
+			err := importRecord(ctx, q, &report, bucketReading, keyLastRead, *pos, func(old ReadingPosition) bool {
+				return pos.UpdatedAt.After(old.UpdatedAt)
+			})
+			if err != nil {
+				return report, err
+			}
+		}
+	}
+	for _, n := range archive.Notes {
+		if n.ID == "" {
+			skip("note", errors.New("no id"))
+			continue
+		}
+		if err := n.VerseKey.Validate(); err != nil {
+			skip("note "+n.ID, err)
+			continue
+		}
+		n.Tags = normalizeTags(n.Tags)
+		err := importRecord(ctx, q, &report, bucketNotes, n.ID, n, func(old Note) bool {
+			return n.UpdatedAt.After(old.UpdatedAt)
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	for _, a := range archive.Annotations {
+		if err := a.validate(); err != nil {
+			skip("annotation", err)
+			continue
+		}
+		var existing Annotation
+		err := q.getValue(ctx, bucketAnnotations, a.VerseKey.String(), &existing)
+		switch {
+		case errors.Is(err, ErrKeyNotFound):
+		case err != nil:
+			return report, err
+		default:
+			var changed bool
+			if a, changed = existing.merge(a); !changed {
+				report.Duplicates++
+				continue
+			}
+		}
+		if err := q.putValue(ctx, bucketAnnotations, a.VerseKey.String(), a); err != nil {
+			return report, err
+		}
+		report.Imported++
+	}
+	for _, h := range archive.Hifz {
+		if err := h.Scope.Validate(); err != nil {
+			skip("hifz "+h.ID, err)
+			continue
+		}
+		h.ID = h.Scope.String()
+		err := importRecord(ctx, q, &report, bucketHifz, h.ID, h, func(old HifzItem) bool {
+			return h.ReviewedAt.After(old.ReviewedAt)
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	for _, p := range archive.Plans {
+		if p.ID == "" || len(p.Portions) == 0 {
+			skip("plan "+p.Name, errors.New("no id or portions"))
+			continue
+		}
+		// plans never change once generated
+		err := importRecord(ctx, q, &report, bucketPlans, p.ID, p, func(Plan) bool { return false })
+		if err != nil {
+			return report, err
+		}
+	}
+	for topic, keys := range archive.Topics {
+		existing, err := q.topicKeys(ctx, bucketUserTopics, normalizeTopic(topic))
+		if err != nil {
+			return report, err
+		}
+		if !slices.ContainsFunc(keys, func(k string) bool { return !slices.Contains(existing, k) }) {
+			report.Duplicates++
+			continue
+		}
+		if err := q.TagVerses(ctx, topic, keys...); err != nil {
+			skip("topic "+topic, err)
+			continue
+		}
+		report.Imported++
+	}
+	for _, k := range archive.Khatmahs {
+		if _, err := k.Unit.total(); k.ID == "" || err != nil {
+			skip("khatmah "+k.Name, errors.New("no id or unit"))
+			continue
+		}
+		err := importRecord(ctx, q, &report, bucketKhatmahs, k.ID, k, func(old Khatmah) bool {
+			return k.UpdatedAt.After(old.UpdatedAt)
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	for _, c := range archive.Collections {
+		if err := c.Validate(); err != nil {
+			skip("collection", err)
+			continue
+		}
+		if _, ok := curatedCollection(c.Name); ok {
+			skip("collection", fmt.Errorf("%w: %s", ErrCuratedCollection, c.Name))
+			continue
+		}
+		c.Curated = false
+		err := importRecord(ctx, q, &report, bucketCollections, c.Name, c, func(old Collection) bool {
+			return c.UpdatedAt.After(old.UpdatedAt)
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func runUserDataArchive(ctx context.Context, q *QuranService, cmd string, args []string) error {
+	fs := flag.NewFlagSet("userdata "+cmd, flag.ContinueOnError)
+	out := fs.String("o", "", "output file (defaults to stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd == "export" {
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		return q.ExportUserData(ctx, w)
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: userdata import <file>")
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	report, err := q.ImportUserData(ctx, f)
+	if err != nil {
+		return err
+	}
+	for _, s := range report.Skipped {
+		fmt.Printf("skipped: %s\n", s)
+	}
+	fmt.Printf("imported %d records, %d already up to date, %d skipped\n",
+		report.Imported, report.Duplicates, len(report.Skipped))
+	return nil
+}
@@ -0,0 +1,204 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// The verse indexes let verse, page and juz reads look up what they need
+// instead of decoding whole chapters. They are written with each chapter,
+// and a read falls back to the chapters whenever an entry is missing.
+const (
+	// bucketVerses holds each verse by its key.
+	bucketVerses = "verses"
+	// bucketPageIndex and bucketJuzIndex hold the keys of the verses of a
+	// page or juz, in order, under "<n>/<chapter>" for each chapter it
+	// spans, so that chapters are indexed independently of each other.
+	bucketPageIndex = "page_index"
+	bucketJuzIndex  = "juz_index"
+)
+
+// juzStarts holds the first verse of each juz.
+var juzStarts = [30][2]int{
+	{1, 1}, {2, 142}, {2, 253}, {3, 93}, {4, 24}, {4, 148}, {5, 82}, {6, 111},
+	{7, 88}, {8, 41}, {9, 93}, {11, 6}, {12, 53}, {15, 1}, {17, 1}, {18, 75},
+	{21, 1}, {23, 1}, {25, 21}, {27, 56}, {29, 46}, {33, 31}, {36, 28}, {39, 32},
+	{41, 47}, {46, 1}, {51, 31}, {58, 1}, {67, 1}, {78, 1},
+}
+
+// juzChapters returns the chapters juz n spans.
+func juzChapters(n int) []int {
+	if n < 1 || n > len(juzStarts) {
+		return nil
+	}
+	first, last := juzStarts[n-1][0], 114
+	if n < len(juzStarts) {
+		next := juzStarts[n]
+		last = next[0]
+		if next[1] == 1 {
+			last--
+		}
+	}
+	var out []int
+	for c := first; c <= last; c++ {
+		out = append(out, c)
+	}
+	return out
+}
+
+func locationKey(n, chapter int) string {
+	return strconv.Itoa(n) + "/" + strconv.Itoa(chapter)
+}
+
+// batchPutter is implemented by stores that can write many keys in one
+// transaction.
+type batchPutter interface {
+	PutBatch(ctx context.Context, namespace string, values map[string][]byte) error
+}
+
+func (q *QuranService) putBatch(ctx context.Context, namespace string, values map[string][]byte) error {
+	if q.readOnly {
+		return readOnlyWrite(namespace)
+	}
+	b, ok := q.store.(batchPutter)
+	if !ok {
+		for key, value := range values {
+			if err := q.putRaw(ctx, namespace, key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, span := q.startSpan(ctx, "store.PutBatch", storeAttrs(namespace, "")...)
+	err := b.PutBatch(ctx, namespace, values)
+	endSpan(span, err)
+	return err
+}
+
+func (q *QuranService) indexVerses(ctx context.Context, chapter Chapter) error {
+	verses := make(map[string][]byte, len(chapter.Verses))
+	pages := make(map[int][]string)
+	juz := make(map[int][]string)
+	for _, v := range chapter.Verses {
+		key := verseKey(chapter.ID, v.VerseNumber)
+		buf, err := valueEncoder(v)
+		if err != nil {
+			return err
+		}
+		verses[key] = buf.Bytes()
+		pages[v.PageNumber] = append(pages[v.PageNumber], key)
+		juz[v.JuzNumber] = append(juz[v.JuzNumber], key)
+	}
+	if err := q.putBatch(ctx, bucketVerses, verses); err != nil {
+		return err
+	}
+	if err := q.putLocations(ctx, bucketPageIndex, chapter.ID, pages); err != nil {
+		return err
+	}
+	return q.putLocations(ctx, bucketJuzIndex, chapter.ID, juz)
+}
+
+func (q *QuranService) putLocations(ctx context.Context, namespace string, chapter int, locations map[int][]string) error {
+	values := make(map[string][]byte, len(locations))
+	for n, keys := range locations {
+		buf, err := valueEncoder(keys)
+		if err != nil {
+			return err
+		}
+		values[locationKey(n, chapter)] = buf.Bytes()
+	}
+	return q.putBatch(ctx, namespace, values)
+}
+
+// unindexVerses removes the index entries of the stored chapter id.
+func (q *QuranService) unindexVerses(ctx context.Context, id int) error {
+	chapter, err := q.getChapterDB(ctx, id)
+	if errors.Is(err, ErrCacheMiss) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	pages := make(map[int]bool)
+	juz := make(map[int]bool)
+	for _, v := range chapter.Verses {
+		if err := q.deleteValue(ctx, bucketVerses, verseKey(id, v.VerseNumber)); err != nil {
+			return err
+		}
+		pages[v.PageNumber] = true
+		juz[v.JuzNumber] = true
+	}
+	for n := range pages {
+		if err := q.deleteValue(ctx, bucketPageIndex, locationKey(n, id)); err != nil {
+			return err
+		}
+	}
+	for n := range juz {
+		if err := q.deleteValue(ctx, bucketJuzIndex, locationKey(n, id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillVerseIndex indexes a chapter read from a store written before the
+// indexes existed.
+func (q *QuranService) backfillVerseIndex(ctx context.Context, chapter Chapter) {
+	if err := q.indexVerses(ctx, chapter); err != nil {
+		q.log(ctx).Warn("index verses", "chapter", chapter.ID, "err", err)
+	}
+}
+
+// getVerseDB reads a verse from the index, returning ErrCacheMiss when it
+// isn't there.
+func (q *QuranService) getVerseDB(ctx context.Context, key string) (Verse, error) {
+	var out Verse
+	err := q.getValue(ctx, bucketVerses, key, &out)
+	if errors.Is(err, ErrKeyNotFound) {
+		return out, ErrCacheMiss
+	}
+	return out, err
+}
+
+// indexedScopeVerses reads the verses of a page or juz scope spanning
+// chapters from the indexes, returning ErrCacheMiss unless all of them are
+// indexed.
+func (q *QuranService) indexedScopeVerses(ctx context.Context, scope Scope, chapters []int) ([]Verse, error) {
+	if len(chapters) == 0 {
+		return nil, ErrCacheMiss
+	}
+	namespace := bucketPageIndex
+	if scope.Kind == ScopeJuz {
+		namespace = bucketJuzIndex
+	}
+
+	var keys []string
+	for _, c := range chapters {
+		var chapterKeys []string
+		err := q.getValue(ctx, namespace, locationKey(scope.Number, c), &chapterKeys)
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil, ErrCacheMiss
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, chapterKeys...)
+	}
+
+	out := make([]Verse, 0, len(keys))
+	for _, key := range keys {
+		if v, ok := q.verseCache.get(key); ok {
+			out = append(out, v)
+			continue
+		}
+		v, err := q.getVerseDB(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		q.verseCache.put(key, v)
+		out = append(out, v)
+	}
+	return out, nil
+}
@@ -0,0 +1,197 @@
+package quranapi
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ChapterCount is the number of chapters (surahs) in the Qur'an.
+	ChapterCount = 114
+	// VerseCount is the number of verses in the Qur'an.
+	VerseCount = 6236
+)
+
+// ErrInvalidVerseKey is returned for verse keys not of the form
+// "chapter:verse".
+var ErrInvalidVerseKey = errors.New("invalid verse key")
+
+// chapterVerseCounts holds the verse count of each chapter, indexed by
+// chapter number - 1.
+var chapterVerseCounts = [ChapterCount]int{
+	7, 286, 200, 176, 120, 165, 206, 75, 129, 109, 123, 111, 43, 52, 99, 128, 111, 110, 98, 135,
+	112, 78, 118, 64, 77, 227, 93, 88, 69, 60, 34, 30, 73, 54, 45, 83, 182, 88, 75, 85,
+	54, 53, 89, 59, 37, 35, 38, 29, 18, 45, 60, 49, 62, 55, 78, 96, 29, 22, 24, 13,
+	14, 11, 11, 18, 12, 12, 30, 52, 52, 44, 28, 28, 20, 56, 40, 31, 50, 40, 46, 42,
+	29, 19, 36, 25, 22, 17, 19, 26, 30, 20, 15, 21, 11, 8, 8, 19, 5, 8, 8, 11,
+	11, 8, 3, 9, 5, 4, 7, 3, 6, 3, 5, 4, 5, 6,
+}
+
+// ValidateChapter reports whether chapter is between 1 and 114.
+func ValidateChapter(chapter int) error {
+	if chapter < 1 || chapter > ChapterCount {
+		return fmt.Errorf("%w: %d is outside 1-%d", ErrChapterNotFound, chapter, ChapterCount)
+	}
+	return nil
+}
+
+// ValidateVerse reports whether verse exists in chapter.
+func ValidateVerse(chapter, verse int) error {
+	if err := ValidateChapter(chapter); err != nil {
+		return err
+	}
+	if n := chapterVerseCounts[chapter-1]; verse < 1 || verse > n {
+		return fmt.Errorf("%w: %s, chapter %d has %d verses", ErrVerseNotFound, verseKey(chapter, verse), chapter, n)
+	}
+	return nil
+}
+
+// ValidateVerseKey parses key and checks that the verse it names exists.
+func ValidateVerseKey(key string) (chapter, verse int, err error) {
+	chapter, verse, err = parseVerseKey(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := ValidateVerse(chapter, verse); err != nil {
+		return 0, 0, err
+	}
+	return chapter, verse, nil
+}
+
+// verseAt returns the chapter and verse of the n-th verse of the mushaf,
+// counted from 1.
+func verseAt(n int) (chapter, verse int) {
+	for i, count := range chapterVerseCounts {
+		if n <= count {
+			return i + 1, n
+		}
+		n -= count
+	}
+	return ChapterCount, chapterVerseCounts[ChapterCount-1]
+}
+
+func verseKey(chapter, verse int) string {
+	return strconv.Itoa(chapter) + ":" + strconv.Itoa(verse)
+}
+
+// VerseKey names a verse as "chapter:verse", such as "2:255". It is a
+// string, so keys stored or served as strings read unchanged.
+type VerseKey string
+
+// NewVerseKey returns the key of verse in chapter.
+func NewVerseKey(chapter, verse int) VerseKey {
+	return VerseKey(verseKey(chapter, verse))
+}
+
+// ParseVerseKey parses and validates a key such as "2:255", returning it
+// in canonical form: "002:255" becomes "2:255".
+func ParseVerseKey(s string) (VerseKey, error) {
+	chapter, verse, err := ValidateVerseKey(strings.TrimSpace(s))
+	if err != nil {
+		return "", err
+	}
+	return NewVerseKey(chapter, verse), nil
+}
+
+// Split returns the chapter and verse of k.
+func (k VerseKey) Split() (chapter, verse int, err error) {
+	return parseVerseKey(string(k))
+}
+
+// Chapter returns the chapter of k, or 0 if k doesn't parse.
+func (k VerseKey) Chapter() int {
+	chapter, _, err := k.Split()
+	if err != nil {
+		return 0
+	}
+	return chapter
+}
+
+// Verse returns the verse number of k within its chapter, or 0 if k
+// doesn't parse.
+func (k VerseKey) Verse() int {
+	_, verse, err := k.Split()
+	if err != nil {
+		return 0
+	}
+	return verse
+}
+
+// Validate reports whether k names a verse that exists.
+func (k VerseKey) Validate() error {
+	_, _, err := ValidateVerseKey(string(k))
+	return err
+}
+
+// Next returns the verse after k in the mushaf, crossing into the next
+// chapter after its last verse. It reports false after 114:6 and for
+// invalid keys.
+func (k VerseKey) Next() (VerseKey, bool) {
+	chapter, verse, err := ValidateVerseKey(string(k))
+	switch {
+	case err != nil:
+		return "", false
+	case verse < chapterVerseCounts[chapter-1]:
+		return NewVerseKey(chapter, verse+1), true
+	case chapter < ChapterCount:
+		return NewVerseKey(chapter+1, 1), true
+	}
+	return "", false
+}
+
+// Prev returns the verse before k in the mushaf, crossing into the last
+// verse of the previous chapter. It reports false before 1:1 and for
+// invalid keys.
+func (k VerseKey) Prev() (VerseKey, bool) {
+	chapter, verse, err := ValidateVerseKey(string(k))
+	switch {
+	case err != nil:
+		return "", false
+	case verse > 1:
+		return NewVerseKey(chapter, verse-1), true
+	case chapter > 1:
+		return NewVerseKey(chapter-1, chapterVerseCounts[chapter-2]), true
+	}
+	return "", false
+}
+
+// Compare orders k and other as they appear in the mushaf, returning -1,
+// 0 or +1. Keys that don't parse sort first.
+func (k VerseKey) Compare(other VerseKey) int {
+	kc, kv, _ := k.Split()
+	oc, ov, _ := other.Split()
+	if kc != oc {
+		return cmp.Compare(kc, oc)
+	}
+	return cmp.Compare(kv, ov)
+}
+
+// SortVerseKeys sorts keys in mushaf order.
+func SortVerseKeys(keys []VerseKey) {
+	slices.SortFunc(keys, VerseKey.Compare)
+}
+
+func (k VerseKey) String() string {
+	return string(k)
+}
+
+// parseVerseKey splits a "chapter:verse" key such as "2:255".
+func parseVerseKey(key string) (chapter, verse int, err error) {
+	c, v, ok := strings.Cut(key, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("%w %q: want chapter:verse", ErrInvalidVerseKey, key)
+	}
+	chapter, err = strconv.Atoi(c)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w %q: chapter is not a number", ErrInvalidVerseKey, key)
+	}
+	verse, err = strconv.Atoi(v)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w %q: verse is not a number", ErrInvalidVerseKey, key)
+	}
+	return chapter, verse, nil
+}
@@ -0,0 +1,212 @@
+package quranapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// votdSeed fixes the verse of the day sequence. Changing it changes the
+// verse shown on every date, so it must stay the same across releases.
+const votdSeed = 0x51_75_72_61_6e_41_50_49
+
+// splitmix64 is used instead of math/rand so the sequence can never change
+// with the Go release.
+type splitmix64 uint64
+
+func (s *splitmix64) next() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	z := uint64(*s)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// VerseOfTheDayKey returns the verse key for date. Every verse is shown
+// once in a shuffled cycle of 6236 days before the next cycle, shuffled
+// anew, begins. Only the calendar date of date matters.
+func VerseOfTheDayKey(date time.Time) string {
+	day := int64(civilDate(date).Unix() / 86400)
+	cycle, index := day/VerseCount, day%VerseCount
+	if index < 0 {
+		cycle, index = cycle-1, index+VerseCount
+	}
+
+	order := make([]int, VerseCount)
+	for i := range order {
+		order[i] = i + 1
+	}
+	rng := splitmix64(votdSeed ^ uint64(cycle))
+	for i := len(order) - 1; i > 0; i-- {
+		j := int(rng.next() % uint64(i+1))
+		order[i], order[j] = order[j], order[i]
+	}
+	return verseKey(verseAt(order[index]))
+}
+
+// VerseOfTheDay returns the verse for date. Every instance returns the same
+// verse for the same date.
+func (q *QuranService) VerseOfTheDay(ctx context.Context, date time.Time) (Verse, error) {
+	return q.GetVerse(ctx, VerseOfTheDayKey(date))
+}
+
+type votdPayload struct {
+	Date        string   `json:"date"`
+	VerseKey    VerseKey `json:"verse_key"`
+	Citation    string   `json:"citation"`
+	Text        string   `json:"text"`
+	Translation []string `json:"translations,omitempty"`
+}
+
+func (q *QuranService) votdPayload(ctx context.Context, date time.Time) (votdPayload, error) {
+	verse, err := q.VerseOfTheDay(ctx, date)
+	if err != nil {
+		return votdPayload{}, err
+	}
+	summary, err := q.GetChapterSummary(ctx, verse.ChapterID)
+	if err != nil {
+		return votdPayload{}, err
+	}
+	p := votdPayload{
+		Date:     date.Format(time.DateOnly),
+		VerseKey: verse.VerseKey,
+		Citation: summary.Cite(verse.VerseNumber, 0).Format(CitationFormat{Style: CitationLong}),
+		Text:     verse.TextMadani,
+	}
+	for _, tr := range verse.Translations {
+		p.Translation = append(p.Translation, stripTags(tr.Text))
+	}
+	return p, nil
+}
+
+// PostVerseOfTheDay sends the verse for date as JSON to a webhook URL.
+func (q *QuranService) PostVerseOfTheDay(ctx context.Context, client Doer, url string, date time.Time) error {
+	p, err := q.votdPayload(ctx, date)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// WriteVerseOfTheDayICal writes an iCalendar feed with an all-day event for
+// each of the days starting at from.
+func (q *QuranService) WriteVerseOfTheDayICal(ctx context.Context, w io.Writer, from time.Time, days int) error {
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(icalFold(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//alilmtech//quranapi//EN")
+	line("X-WR-CALNAME:Verse of the day")
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for i := 0; i < days; i++ {
+		date := civilDate(from).AddDate(0, 0, i)
+		p, err := q.votdPayload(ctx, date)
+		if err != nil {
+			return err
+		}
+		desc := p.Text
+		for _, tr := range p.Translation {
+			desc += "\n\n" + tr
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:votd-" + date.Format("20060102") + "@quranapi")
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + icalEscape(p.Citation))
+		line("DESCRIPTION:" + icalEscape(desc))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func icalEscape(s string) string {
+	return icalEscaper.Replace(s)
+}
+
+// icalFold folds a content line at 75 octets without splitting a UTF-8
+// sequence, as RFC 5545 requires.
+func icalFold(s string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > 75 {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
+
+func runVerseOfTheDay(ctx context.Context, q *QuranService, args []string) error {
+	fs := flag.NewFlagSet("votd", flag.ContinueOnError)
+	dateFlag := fs.String("date", "", "date to show (defaults to today)")
+	ical := fs.Int("ical", 0, "write an iCalendar feed of this many days instead")
+	webhook := fs.String("webhook", "", "POST the verse as JSON to this URL")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	date := time.Now()
+	if *dateFlag != "" {
+		var err error
+		if date, err = time.Parse(time.DateOnly, *dateFlag); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case *ical > 0:
+		return q.WriteVerseOfTheDayICal(ctx, os.Stdout, date, *ical)
+	case *webhook != "":
+		return q.PostVerseOfTheDay(ctx, q.doer, *webhook, date)
+	}
+
+	p, err := q.votdPayload(ctx, date)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n%s\n", p.Citation, p.Text)
+	for _, tr := range p.Translation {
+		fmt.Printf("\n%s\n", tr)
+	}
+	return nil
+}
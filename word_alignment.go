@@ -0,0 +1,149 @@
+package quranapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const bucketWordAlignments = "word_alignments"
+
+// WordGloss is a word of a verse with its gloss in a word-by-word
+// translation.
+type WordGloss struct {
+	Position int    `json:"position"`
+	Text     string `json:"text"`
+	Gloss    string `json:"gloss"`
+	// Start and End are the byte range of Gloss in WordAlignment.Gloss,
+	// so that a UI can highlight it as the word's audio segment plays.
+	// They are equal for words without a gloss.
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// WordAlignment aligns the words of a verse, by Word.Position, with a
+// word-by-word translation.
+type WordAlignment struct {
+	VerseKey VerseKey `json:"verse_key"`
+	Language string   `json:"language"`
+	// Gloss is the glosses of the words in order, separated by spaces.
+	Gloss string      `json:"gloss"`
+	Words []WordGloss `json:"words"`
+}
+
+// Word returns the gloss of the word at position.
+func (a WordAlignment) Word(position int) (WordGloss, bool) {
+	for _, w := range a.Words {
+		if w.Position == position {
+			return w, true
+		}
+	}
+	return WordGloss{}, false
+}
+
+var languageCodeRE = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// WordAlignment returns the words of the verse named by key aligned with
+// their glosses in language, an ISO 639 code such as "en" or "ur". The
+// glosses of a chapter are fetched the first time it is aligned in a
+// language and stored apart from the chapter.
+func (q *QuranService) WordAlignment(ctx context.Context, key, language string) (WordAlignment, error) {
+	chapter, verse, err := ValidateVerseKey(key)
+	if err != nil {
+		return WordAlignment{}, err
+	}
+	alignments, err := q.ChapterWordAlignments(ctx, chapter, language)
+	if err != nil {
+		return WordAlignment{}, err
+	}
+	if verse > len(alignments) {
+		return WordAlignment{}, fmt.Errorf("%w: %s", ErrVerseNotFound, key)
+	}
+	return alignments[verse-1], nil
+}
+
+// ChapterWordAlignments returns the word alignments of every verse of
+// chapter in language, as WordAlignment.
+func (q *QuranService) ChapterWordAlignments(ctx context.Context, chapter int, language string) ([]WordAlignment, error) {
+	if err := ValidateChapter(chapter); err != nil {
+		return nil, err
+	}
+	language = strings.ToLower(strings.TrimSpace(language))
+	if !languageCodeRE.MatchString(language) {
+		return nil, fmt.Errorf("invalid language code %q", language)
+	}
+
+	key := wordAlignmentKey(language, chapter)
+	var alignments []WordAlignment
+	err := q.getValue(ctx, bucketWordAlignments, key, &alignments)
+	q.cacheEvent(ctx, CacheLayerStore, "word_alignments/"+key, err == nil)
+	if err == nil {
+		return alignments, nil
+	}
+	if !errors.Is(err, ErrKeyNotFound) {
+		return nil, err
+	}
+
+	verses, err := q.fetchWordTranslations(ctx, chapter, language)
+	if err != nil {
+		return nil, err
+	}
+	alignments = make([]WordAlignment, len(verses))
+	for i, v := range verses {
+		alignments[i] = alignWords(v, language)
+	}
+	if err := q.putValue(ctx, bucketWordAlignments, key, alignments); err != nil {
+		q.log(ctx).Error("cache word alignments", "language", language, "chapter", chapter, "err", err)
+	}
+	return alignments, nil
+}
+
+func wordAlignmentKey(language string, chapter int) string {
+	return language + "/" + strconv.Itoa(chapter)
+}
+
+// fetchWordTranslations fetches the verses of chapter with their words
+// translated into language.
+func (q *QuranService) fetchWordTranslations(ctx context.Context, chapter int, language string) ([]Verse, error) {
+	path := fmt.Sprintf("/chapters/%d/verses", chapter)
+	return paginate(ctx, q.paging, versesPerPage, chapterVerseCounts[chapter-1],
+		func(v Verse) int { return v.VerseNumber },
+		func(ctx context.Context, params []queryParam) ([]Verse, error) {
+			var resp struct {
+				Verses []Verse `json:"verses"`
+			}
+			req := q.httpClient.Get(path).QueryParam("language", language)
+			for _, p := range params {
+				req = req.QueryParam(p.name, p.value)
+			}
+			ctx, span := q.startSpan(ctx, "GET "+path)
+			err := q.fetch(ctx, path, req, &resp)
+			endSpan(span, err)
+			return resp.Verses, err
+		})
+}
+
+// alignWords aligns the words of v, leaving out its end marker, with
+// their translations.
+func alignWords(v Verse, language string) WordAlignment {
+	a := WordAlignment{VerseKey: v.VerseKey, Language: language}
+	var b strings.Builder
+	for _, w := range v.Words {
+		if w.CharType == "end" {
+			continue
+		}
+		g := WordGloss{Position: w.Position, Text: w.TextMadani, Gloss: strings.TrimSpace(stripTags(w.Translation.Text))}
+		if g.Gloss != "" && b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		g.Start = b.Len()
+		b.WriteString(g.Gloss)
+		g.End = b.Len()
+		a.Words = append(a.Words, g)
+	}
+	a.Gloss = b.String()
+	return a
+}